@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import "context"
+
+// AlbumListType is the kind of album list to get.
+type AlbumListType int
+
+const (
+	// RecentAlbums are the recently played albums.
+	RecentAlbums AlbumListType = iota
+	// FrequentAlbums are the most played albums.
+	FrequentAlbums
+	// NewestAlbums are the recently added albums.
+	NewestAlbums
+	// RandomAlbums are random albums.
+	RandomAlbums
+	// HighestRatedAlbums are the albums with the highest rating.
+	HighestRatedAlbums
+	// StarredAlbums are the starred albums.
+	StarredAlbums
+	// AlbumsByName are all albums sorted by name.
+	AlbumsByName
+	// AlbumsByArtist are all albums sorted by artist.
+	AlbumsByArtist
+	// AlbumsByYear are the albums released between FromYear and ToYear.
+	AlbumsByYear
+	// AlbumsByGenre are the albums in the Genre.
+	AlbumsByGenre
+)
+
+// AlbumListQuery selects an album list and a page of it.
+type AlbumListQuery struct {
+	// Type is the kind of list.
+	Type AlbumListType
+	// Count is the number of albums to return.
+	Count int
+	// Offset is the number of albums to skip.
+	Offset int
+	// FromYear is the first year for AlbumsByYear. If it's after ToYear,
+	// the albums are sorted in reverse order.
+	FromYear int
+	// ToYear is the last year for AlbumsByYear.
+	ToYear int
+	// Genre is the genre for AlbumsByGenre.
+	Genre string
+}
+
+// AlbumLister is implemented by providers that can list albums in other
+// orders than alphabetical.
+type AlbumLister interface {
+	// AlbumList returns the albums in the list, without their tracks.
+	AlbumList(ctx context.Context, query *AlbumListQuery) ([]*Album, error)
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// bookmarkMargin is how far into a track, and how close to its end, it
+	// has to be stopped to be bookmarked. Tracks stopped within the margin
+	// of the end are considered played.
+	bookmarkMargin = 30 * time.Second
+	// bookmarkTimeout limits the time to save or delete a bookmark.
+	bookmarkTimeout = 10 * time.Second
+	// AutoBookmarkComment is the comment of the bookmarks created when a
+	// track is stopped.
+	AutoBookmarkComment = "Saved by Jamsonic when stopped"
+)
+
+// BookmarkThreshold is the shortest track bookmarked when it's stopped
+// midway. Zero turns the automatic bookmarks off.
+var BookmarkThreshold = 20 * time.Minute
+
+// Bookmark is a saved position in a track, like in an audiobook or a long
+// mix.
+type Bookmark struct {
+	// Track is the bookmarked track.
+	Track *Track
+	// Position is where to resume the track.
+	Position time.Duration
+	// Comment describes the bookmark.
+	Comment string
+	// Changed is when the bookmark was saved, by the server's clock.
+	Changed time.Time
+}
+
+// BookmarkProvider is implemented by providers that can save bookmarks on
+// the server. A track has at most one bookmark.
+type BookmarkProvider interface {
+	// Bookmarks returns the saved bookmarks.
+	Bookmarks(ctx context.Context) ([]*Bookmark, error)
+	// CreateBookmark saves the position in the track, replacing its
+	// bookmark if it has one.
+	CreateBookmark(ctx context.Context, trackID string, position time.Duration, comment string) error
+	// DeleteBookmark removes the track's bookmark.
+	DeleteBookmark(ctx context.Context, trackID string) error
+}
+
+// BookmarkTracker bookmarks tracks longer than BookmarkThreshold that are
+// stopped midway, so they can be resumed later. The bookmark of a resumed
+// track is removed when it's played to the end.
+type BookmarkTracker struct {
+	player   *Player
+	provider BookmarkProvider
+	logger   *Logger
+	cancel   func()
+	done     chan struct{}
+
+	mu sync.Mutex
+	// current is the track being played, or nil.
+	current *Track
+	// bookmarked are the IDs of the tracks known to have a bookmark.
+	bookmarked map[string]bool
+}
+
+// NewBookmarkTracker starts bookmarking the tracks stopped by the player.
+func NewBookmarkTracker(player *Player, provider BookmarkProvider, logger *Logger) *BookmarkTracker {
+	events, cancel := player.Subscribe()
+	t := &BookmarkTracker{
+		player:     player,
+		provider:   provider,
+		logger:     logger,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		bookmarked: make(map[string]bool),
+	}
+	go t.listen(events)
+	return t
+}
+
+// Close bookmarks the track being played, if it's long enough, and stops
+// tracking.
+func (t *BookmarkTracker) Close() {
+	t.cancel()
+	<-t.done
+}
+
+// Resume plays the bookmarked track from the saved position. The track is
+// put first in the play queue and replaces the track being played.
+func (t *BookmarkTracker) Resume(b *Bookmark) {
+	t.mu.Lock()
+	t.bookmarked[b.Track.ID] = true
+	t.mu.Unlock()
+	t.player.CreatePlayQueue(append([]*Track{b.Track}, t.player.Queue()...))
+	t.player.PlayAt(b.Position)
+}
+
+// Create bookmarks the track at the position and remembers that it has a
+// bookmark.
+func (t *BookmarkTracker) Create(ctx context.Context, track *Track, position time.Duration, comment string) error {
+	if err := t.provider.CreateBookmark(ctx, track.ID, position, comment); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.bookmarked[track.ID] = true
+	t.mu.Unlock()
+	return nil
+}
+
+// Delete removes the track's bookmark.
+func (t *BookmarkTracker) Delete(ctx context.Context, trackID string) error {
+	if err := t.provider.DeleteBookmark(ctx, trackID); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	delete(t.bookmarked, trackID)
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *BookmarkTracker) listen(events <-chan *Event) {
+	defer close(t.done)
+	for e := range events {
+		if e.Type != TrackChanged {
+			continue
+		}
+		t.mu.Lock()
+		previous := t.current
+		t.current = e.CurrentTrack
+		t.mu.Unlock()
+		// The event is sent before the position is reset, so it's the
+		// position where the previous track stopped. A seek restarts the
+		// same track.
+		if previous != nil && previous != e.CurrentTrack {
+			t.stopped(previous, e.Position)
+		}
+	}
+	t.mu.Lock()
+	current := t.current
+	t.current = nil
+	t.mu.Unlock()
+	if current != nil && t.player.CurrentTrack() == current {
+		t.stopped(current, t.player.Position())
+	}
+}
+
+// stopped bookmarks the track if it's long enough and was stopped midway.
+// If it was played to the end, its bookmark is removed.
+func (t *BookmarkTracker) stopped(track *Track, position time.Duration) {
+	if track.Live || track.EpisodeID != "" || BookmarkThreshold <= 0 {
+		return
+	}
+	ms, err := strconv.ParseInt(track.DurationMillis, 10, 64)
+	length := time.Duration(ms) * time.Millisecond
+	if err != nil || length < BookmarkThreshold {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bookmarkTimeout)
+	defer cancel()
+	if position >= length-bookmarkMargin {
+		t.mu.Lock()
+		bookmarked := t.bookmarked[track.ID]
+		t.mu.Unlock()
+		if !bookmarked {
+			return
+		}
+		if err := t.Delete(ctx, track.ID); err != nil {
+			t.logger.ErrorLog("Failed to delete the bookmark of " + track.Title + ": " + err.Error())
+		}
+		return
+	}
+	if position < bookmarkMargin {
+		return
+	}
+	if err := t.Create(ctx, track, position.Truncate(time.Millisecond), AutoBookmarkComment); err != nil {
+		t.logger.ErrorLog("Failed to bookmark " + track.Title + ": " + err.Error())
+		return
+	}
+	t.logger.DebugLog("Bookmarked " + track.Title + " at " + position.Truncate(time.Second).String())
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBookmarkProvider struct {
+	mu        sync.Mutex
+	bookmarks map[string]*Bookmark
+}
+
+func (m *mockBookmarkProvider) Bookmarks(ctx context.Context) ([]*Bookmark, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var list []*Bookmark
+	for _, b := range m.bookmarks {
+		list = append(list, b)
+	}
+	return list, nil
+}
+
+func (m *mockBookmarkProvider) CreateBookmark(ctx context.Context, trackID string, position time.Duration, comment string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.bookmarks[trackID] = &Bookmark{Track: &Track{ID: trackID}, Position: position, Comment: comment}
+	return nil
+}
+
+func (m *mockBookmarkProvider) DeleteBookmark(ctx context.Context, trackID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.bookmarks, trackID)
+	return nil
+}
+
+func (m *mockBookmarkProvider) get(trackID string) *Bookmark {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.bookmarks[trackID]
+}
+
+func TestBookmarkTracker(t *testing.T) {
+	assert := assert.New(t)
+	long := &Track{ID: "1", DurationMillis: "3600000"}
+	short := &Track{ID: "2", DurationMillis: "600000"}
+
+	t.Run("stopped_midway", func(t *testing.T) {
+		provider := &mockBookmarkProvider{bookmarks: make(map[string]*Bookmark)}
+		p, _ := getOffsetPlayer()
+		bt := NewBookmarkTracker(p, provider, DefaultLogger())
+		p.CreatePlayQueue([]*Track{long})
+		p.PlayAt(20 * time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == long })
+		p.Stop()
+		waitFor(t, func() bool { return provider.get("1") != nil })
+		b := provider.get("1")
+		assert.True(b.Position >= 20*time.Minute && b.Position < 21*time.Minute, "Wrong position: %s", b.Position)
+		assert.Equal(AutoBookmarkComment, b.Comment)
+		bt.Close()
+		p.Close()
+	})
+
+	t.Run("short_track", func(t *testing.T) {
+		provider := &mockBookmarkProvider{bookmarks: make(map[string]*Bookmark)}
+		p, _ := getOffsetPlayer()
+		bt := NewBookmarkTracker(p, provider, DefaultLogger())
+		p.CreatePlayQueue([]*Track{short})
+		p.PlayAt(5 * time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == short })
+		p.Stop()
+		waitFor(t, func() bool { return p.CurrentTrack() == nil })
+		bt.Close()
+		assert.Nil(provider.get("2"), "Tracks shorter than the threshold should not be bookmarked")
+		p.Close()
+	})
+
+	t.Run("resume_and_finish", func(t *testing.T) {
+		provider := &mockBookmarkProvider{bookmarks: make(map[string]*Bookmark)}
+		p, handler := getOffsetPlayer()
+		bt := NewBookmarkTracker(p, provider, DefaultLogger())
+		b := &Bookmark{Track: long, Position: 59*time.Minute + 50*time.Second}
+		provider.bookmarks["1"] = b
+		bt.Resume(b)
+		waitFor(t, func() bool { return p.CurrentTrack() == long })
+		handler.offsetMu.Lock()
+		assert.Equal(b.Position, handler.offset, "Should resume at the bookmark")
+		handler.offsetMu.Unlock()
+		p.Stop()
+		waitFor(t, func() bool { return provider.get("1") == nil })
+		bt.Close()
+		p.Close()
+	})
+
+	t.Run("close", func(t *testing.T) {
+		provider := &mockBookmarkProvider{bookmarks: make(map[string]*Bookmark)}
+		p, _ := getOffsetPlayer()
+		bt := NewBookmarkTracker(p, provider, DefaultLogger())
+		p.CreatePlayQueue([]*Track{long})
+		p.PlayAt(10 * time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == long })
+		bt.Close()
+		assert.NotNil(provider.get("1"), "The track being played should be bookmarked on close")
+		p.Close()
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+// Capability is an optional feature of the server.
+type Capability string
+
+// The OpenSubsonic extensions known by Jamsonic.
+const (
+	// TranscodeOffsetCapability means transcoded streams can start at a
+	// time offset.
+	TranscodeOffsetCapability Capability = "transcodeOffset"
+	// SongLyricsCapability means the server has structured and synced
+	// lyrics.
+	SongLyricsCapability Capability = "songLyrics"
+	// FormPostCapability means requests can be sent as POST forms.
+	FormPostCapability Capability = "formPost"
+	// APIKeyAuthCapability means the server accepts API keys.
+	APIKeyAuthCapability Capability = "apiKeyAuthentication"
+)
+
+// CapabilityProvider is implemented by providers that know which optional
+// features the server has.
+type CapabilityProvider interface {
+	// Capabilities returns the server's optional features.
+	Capabilities() []Capability
+}
+
+// HasCapability returns true if the provider knows that the server has the
+// capability. The provider can be any provider interface.
+func HasCapability(provider interface{}, c Capability) bool {
+	p, ok := provider.(CapabilityProvider)
+	if !ok {
+		return false
+	}
+	for _, capability := range p.Capabilities() {
+		if capability == c {
+			return true
+		}
+	}
+	return false
+}
@@ -21,9 +21,13 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
+	"github.com/TcM1911/jamsonic/mpd"
+	"github.com/TcM1911/jamsonic/remote"
 	"github.com/TcM1911/jamsonic/storage"
 	"github.com/TcM1911/jamsonic/subsonic"
 	"github.com/TcM1911/jamsonic/tui"
@@ -35,18 +39,42 @@ const (
 )
 
 var (
-	vers         bool
-	debug        bool
-	lastFM       bool
-	useGPM       bool
-	experimental bool
-	legacy       bool
+	vers          bool
+	debug         bool
+	lastFM        bool
+	useGPM        bool
+	experimental  bool
+	legacy        bool
+	mpdAddr       string
+	remoteAddr    string
+	remoteToken   string
+	coverArt      string
+	coverArtMB    int64
+	streamProfile string
+	syncWorkers   int
+	bookmarkAfter time.Duration
+	transport     = subsonic.DefaultTransportConfig
 )
 
 func init() {
 	// parse flags
 	flag.BoolVar(&vers, "version", false, "print version and exit")
 	flag.BoolVar(&debug, "debug", false, "debug")
+	flag.StringVar(&mpdAddr, "mpd", "", "listen for MPD clients on the address, e.g. localhost:6600")
+	flag.StringVar(&remoteAddr, "remote", "", "serve the remote control API on the address, e.g. :8080")
+	flag.StringVar(&remoteToken, "remote-token", os.Getenv("JAMSONIC_REMOTE_TOKEN"), "token required by the remote control API")
+	flag.StringVar(&coverArt, "cover-art", tui.HalfBlocks, "how to draw the cover art: halfblock, sixel, kitty or off")
+	flag.Int64Var(&coverArtMB, "cover-art-cache", storage.CoverArtCacheSize>>20, "max size of the cover art cache in MB")
+	flag.StringVar(&streamProfile, "stream-profile", "", "name of the stream profile to use, e.g. \"tethered: mp3 128\"")
+	flag.IntVar(&syncWorkers, "sync-workers", subsonic.SyncWorkers, "number of artists or albums downloaded at the same time when syncing")
+	flag.DurationVar(&bookmarkAfter, "bookmark-threshold", jamsonic.BookmarkThreshold, "bookmark tracks at least this long when stopped midway, 0 turns it off")
+	flag.DurationVar(&transport.ConnectTimeout, "connect-timeout", transport.ConnectTimeout, "max time to connect to the server")
+	flag.DurationVar(&transport.ReadTimeout, "read-timeout", transport.ReadTimeout, "max time to wait for data from the server")
+	flag.IntVar(&transport.MaxRetries, "retries", transport.MaxRetries, "how many times failed requests that can be repeated are retried")
+	flag.StringVar(&transport.CAFile, "ca-file", "", "PEM file with extra root certificates to trust")
+	flag.StringVar(&transport.CertFile, "client-cert", "", "PEM file with the client certificate")
+	flag.StringVar(&transport.KeyFile, "client-key", "", "PEM file with the key of the client certificate")
+	flag.StringVar(&transport.Proxy, "proxy", "", "HTTP, HTTPS or SOCKS5 proxy URL, e.g. socks5://localhost:1080")
 
 	flag.Usage = func() {
 		fmt.Fprint(os.Stderr, fmt.Sprintf(BANNER, jamsonic.Version))
@@ -55,6 +83,11 @@ func init() {
 
 	flag.Parse()
 
+	tui.CoverArtProtocol = coverArt
+	storage.CoverArtCacheSize = coverArtMB << 20
+	subsonic.SyncWorkers = syncWorkers
+	jamsonic.BookmarkThreshold = bookmarkAfter
+
 	if vers {
 		fmt.Printf("%s\n", jamsonic.Version)
 		os.Exit(0)
@@ -73,6 +106,10 @@ func main() {
 		logger.ErrorLog("Can't open database: " + err.Error())
 		return
 	}
+	if err = subsonic.SetTransport(&transport); err != nil {
+		logger.ErrorLog("Can't configure the connection to the server: " + err.Error())
+		return
+	}
 	subsonicLogger := logger.SubLogger("[Subsonic client]")
 	client, err := subsonic.New(db, jamsonic.DefaultCredentialRequest, subsonicLogger)
 	if err != nil {
@@ -80,11 +117,52 @@ func main() {
 		return
 	}
 	db.LibName = []byte(client.Host())
+	if streamProfile != "" {
+		err = jamsonic.ActivateStreamProfile(db, client, streamProfile)
+	} else {
+		var profile *jamsonic.StreamProfile
+		_, profile, err = jamsonic.GetStreamProfiles(db)
+		if err == nil {
+			client.SetStreamSettings(profile.StreamSettings)
+		}
+	}
+	if err != nil {
+		logger.ErrorLog("Can't use the stream profile: " + err.Error())
+		return
+	}
+	folder, err := db.MusicFolder()
+	if err != nil {
+		logger.ErrorLog("Can't read the music folder: " + err.Error())
+		return
+	}
+	client.SetMusicFolder(folder)
 	if err != nil {
 		logger.ErrorLog("Failed to sync the library with the SubSonic server: " + err.Error())
 		return
 	}
 	ui := tui.New(db, client, logger)
+	if mpdAddr != "" {
+		mpdServer := mpd.New(ui.Player(), db, logger.SubLogger("[MPD]"))
+		defer mpdServer.Close()
+		go func() {
+			if err := mpdServer.ListenAndServe(mpdAddr); err != mpd.ErrServerClosed {
+				logger.ErrorLog("MPD server failed: " + err.Error())
+			}
+		}()
+	}
+	if remoteAddr != "" {
+		remoteServer, err := remote.New(ui.Player(), db, client, logger.SubLogger("[Remote]"), remoteToken)
+		if err != nil {
+			logger.ErrorLog("Can't start the remote API: " + err.Error())
+			return
+		}
+		defer remoteServer.Close()
+		go func() {
+			if err := remoteServer.ListenAndServe(remoteAddr); err != http.ErrServerClosed {
+				logger.ErrorLog("Remote API failed: " + err.Error())
+			}
+		}()
+	}
 	if err := ui.Run(); err != nil {
 		logger.ErrorLog(err.Error())
 	}
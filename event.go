@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import "time"
+
+// EventType identifies what changed in the Player.
+type EventType int8
+
+const (
+	// StateChanged is sent when the player changes between playing, paused and stopped.
+	StateChanged EventType = iota
+	// TrackChanged is sent when the current track changes.
+	TrackChanged
+	// QueueChanged is sent when the play queue has been modified.
+	QueueChanged
+	// VolumeChanged is sent when the output volume has been changed.
+	VolumeChanged
+	// PositionChanged is sent when the playback position jumps because of a seek.
+	PositionChanged
+	// StreamTitleChanged is sent when a live stream announces a new title.
+	StreamTitleChanged
+)
+
+// eventBufferSize is the number of events a subscriber can fall behind before
+// events are dropped.
+const eventBufferSize = 32
+
+// Event is sent to the subscribers when the state of the Player changes.
+// It holds the values from right after the change.
+type Event struct {
+	// Type is the kind of change.
+	Type EventType
+	// State is the player's state.
+	State State
+	// CurrentTrack is the track being played.
+	CurrentTrack *Track
+	// Position is how long the current track has been played.
+	Position time.Duration
+}
+
+// Subscribe returns a channel that receives the player's events and a function
+// that cancels the subscription. The Player never blocks on a subscriber, if the
+// subscriber falls behind, events are dropped.
+func (p *Player) Subscribe() (<-chan *Event, func()) {
+	c := make(chan *Event, eventBufferSize)
+	p.subMu.Lock()
+	p.subscribers[c] = struct{}{}
+	p.subMu.Unlock()
+	cancel := func() {
+		p.subMu.Lock()
+		defer p.subMu.Unlock()
+		if _, ok := p.subscribers[c]; ok {
+			delete(p.subscribers, c)
+			close(c)
+		}
+	}
+	return c, cancel
+}
+
+// notify sends an event of the given type to all subscribers.
+func (p *Player) notify(t EventType) {
+	e := &Event{
+		Type:         t,
+		State:        p.GetCurrentState(),
+		CurrentTrack: p.CurrentTrack(),
+		Position:     p.Position(),
+	}
+	p.subMu.Lock()
+	defer p.subMu.Unlock()
+	for c := range p.subscribers {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidRating is returned if a rating is not between 0 and 5.
+var ErrInvalidRating = errors.New("rating must be between 0 and 5")
+
+// ItemKind identifies what kind of item an ID belongs to.
+type ItemKind int
+
+const (
+	// ArtistItem is an artist.
+	ArtistItem ItemKind = iota
+	// AlbumItem is an album.
+	AlbumItem
+	// TrackItem is a track.
+	TrackItem
+)
+
+// Favorites holds the starred artists, albums and tracks. The artists and
+// albums may be returned without their albums and tracks.
+type Favorites struct {
+	Artists []*Artist
+	Albums  []*Album
+	Tracks  []*Track
+}
+
+// FavoritesManager is implemented by providers that support starring and
+// rating items.
+type FavoritesManager interface {
+	// Starred returns the starred items.
+	Starred(ctx context.Context) (*Favorites, error)
+	// Star stars the item.
+	Star(ctx context.Context, kind ItemKind, id string) error
+	// Unstar removes the star from the item.
+	Unstar(ctx context.Context, kind ItemKind, id string) error
+	// SetRating rates the item from 1 to 5. A rating of 0 removes the
+	// rating.
+	SetRating(ctx context.Context, id string, rating int) error
+}
+
+// FavoritesStore is implemented by stores that can cache the starred items.
+type FavoritesStore interface {
+	// Favorites returns the cached starred items.
+	Favorites() (*Favorites, error)
+	// SaveFavorites replaces the cached starred items.
+	SaveFavorites(favorites *Favorites) error
+}
+
+// RefreshFavorites fetches the starred items from the provider and saves
+// them to the store.
+func RefreshFavorites(ctx context.Context, db FavoritesStore, provider FavoritesManager) error {
+	favorites, err := provider.Starred(ctx)
+	if err != nil {
+		return err
+	}
+	return db.SaveFavorites(favorites)
+}
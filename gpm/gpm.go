@@ -19,6 +19,7 @@ package gpm
 
 /*
 import (
+	"context"
 	"io"
 
 	"github.com/TcM1911/jamsonic"
@@ -35,19 +36,19 @@ func (c *Client) GetProvider() jamsonic.MusicProvider {
 	return jamsonic.GooglePlayMusic
 }
 
-func (c *Client) FetchLibrary() ([]*jamsonic.Artist, error) {
+func (c *Client) FetchLibrary(ctx context.Context) ([]*jamsonic.Artist, error) {
 	panic("this should not be called. Use old method.")
 }
 
 // GetStream returns a ReadCloser stream of the track. The stream
 // has to be a MP3 encoded stream.
-func (c *Client) GetStream(songID string) (io.ReadCloser, error) {
+func (c *Client) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
 	r, err := c.GMusic.GetStream(songID)
 	return r.Body, err
 }
 
 // GetTrackInfo returns information abot the track from the provider.
-func (c *Client) GetTrackInfo(songID string) (*jamsonic.Track, error) {
+func (c *Client) GetTrackInfo(ctx context.Context, songID string) (*jamsonic.Track, error) {
 	t, err := c.GMusic.GetTrackInfo(songID)
 	if err != nil {
 		return nil, err
@@ -56,7 +57,7 @@ func (c *Client) GetTrackInfo(songID string) (*jamsonic.Track, error) {
 }
 
 // ListPlaylistEntries returns to entries in the playlist.
-func (c *Client) ListPlaylistEntries() ([]*jamsonic.PlaylistEntry, error) {
+func (c *Client) ListPlaylistEntries(ctx context.Context) ([]*jamsonic.PlaylistEntry, error) {
 	entries, err := c.GMusic.ListPlaylistEntries()
 	if err != nil {
 		return make([]*jamsonic.PlaylistEntry, 0), err
@@ -65,7 +66,7 @@ func (c *Client) ListPlaylistEntries() ([]*jamsonic.PlaylistEntry, error) {
 }
 
 // ListPlaylists returns all the playlists from the provider.
-func (c *Client) ListPlaylists() ([]*jamsonic.Playlist, error) {
+func (c *Client) ListPlaylists(ctx context.Context) ([]*jamsonic.Playlist, error) {
 	ps, err := c.GMusic.ListPlaylists()
 	if err != nil {
 		return make([]*jamsonic.Playlist, 0), err
@@ -74,7 +75,7 @@ func (c *Client) ListPlaylists() ([]*jamsonic.Playlist, error) {
 }
 
 // ListTracks returns all the tracks from the provider.
-func (c *Client) ListTracks() ([]*jamsonic.Track, error) {
+func (c *Client) ListTracks(ctx context.Context) ([]*jamsonic.Track, error) {
 	ts, err := c.GMusic.ListTracks()
 	if err != nil {
 		return make([]*jamsonic.Track, 0), err
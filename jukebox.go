@@ -0,0 +1,31 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+const (
+	// LocalOutput plays the tracks on this computer.
+	LocalOutput = "local"
+	// JukeboxOutput plays the tracks on the server's sound card.
+	JukeboxOutput = "jukebox"
+)
+
+// JukeboxProvider is implemented by providers that can play the tracks on
+// the server instead of streaming them.
+type JukeboxProvider interface {
+	// Jukebox returns a stream handler that plays the tracks on the server.
+	Jukebox(logger *Logger) TrackStreamHandler
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// liveBufferSize is the number of bytes buffered from a live stream.
+	// Live streams never end, so they can't be buffered in full.
+	liveBufferSize = 256 * 1024
+	// liveMaxReconnects is how many times in a row a dropped live stream is
+	// reconnected before giving up.
+	liveMaxReconnects = 5
+)
+
+// LiveReconnectWait is the time to wait before reconnecting a dropped live
+// stream. The wait grows with each failed attempt.
+var LiveReconnectWait = time.Second
+
+// LiveReadTimeout is how long to wait for data from a live stream before
+// the connection is treated as dropped and reconnected.
+var LiveReadTimeout = 30 * time.Second
+
+// errLiveStreamStalled is returned when a live stream stops sending data
+// without closing the connection.
+var errLiveStreamStalled = errors.New("no data received from the live stream")
+
+// ErrSeekLiveStream is returned when seeking in a live stream.
+var ErrSeekLiveStream = errors.New("can't seek in a live stream")
+
+// InternetRadioStation is an internet radio station.
+type InternetRadioStation struct {
+	// ID is the station's ID.
+	ID string
+	// Name is the name of the station.
+	Name string
+	// StreamURL is the URL to the audio stream.
+	StreamURL string
+	// HomePageURL is the station's home page, if any.
+	HomePageURL string
+}
+
+// Track returns a live track that plays the station.
+func (s *InternetRadioStation) Track() *Track {
+	return &Track{ID: s.ID, Title: s.Name, StreamURL: s.StreamURL, Live: true}
+}
+
+// InternetRadioProvider is implemented by providers that have internet
+// radio stations.
+type InternetRadioProvider interface {
+	// InternetRadioStations returns the stations.
+	InternetRadioStations(ctx context.Context) ([]*InternetRadioStation, error)
+}
+
+// liveStream reads a live stream into a bounded buffer. If the connection
+// drops, the stream is reconnected. Shoutcast/Icecast metadata is removed
+// from the audio and the stream title is passed to onTitle.
+type liveStream struct {
+	url     string
+	buf     *liveBuffer
+	onTitle func(string)
+	logger  *Logger
+	// ctx is canceled when the stream is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// stopped is closed when the stream is no longer read.
+	stopped chan struct{}
+}
+
+// newLiveStream starts reading the stream in the background.
+func newLiveStream(url string, onTitle func(string), logger *Logger) *liveStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &liveStream{
+		url:     url,
+		buf:     newLiveBuffer(liveBufferSize),
+		onTitle: onTitle,
+		logger:  logger,
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *liveStream) run() {
+	defer close(s.stopped)
+	defer s.buf.Close()
+	failures := 0
+	for {
+		n, err := s.copyStream()
+		if s.ctx.Err() != nil {
+			return
+		}
+		// Only count attempts that didn't get any audio, so a stream
+		// dropping now and then is always reconnected.
+		if n > 0 {
+			failures = 0
+		}
+		failures++
+		if failures > liveMaxReconnects {
+			s.logger.ErrorLog("Giving up on the live stream: " + err.Error())
+			return
+		}
+		s.logger.InfoLog("Live stream dropped, reconnecting: " + err.Error())
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(LiveReconnectWait * time.Duration(failures)):
+		}
+	}
+}
+
+// copyStream connects to the stream and copies the audio to the buffer
+// until the connection drops.
+func (s *liveStream) copyStream() (int64, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	// The request is cancelled if a read takes longer than LiveReadTimeout.
+	stalled := time.AfterFunc(LiveReadTimeout, cancel)
+	defer stalled.Stop()
+	req = req.WithContext(ctx)
+	// Ask for the stream title in the stream.
+	req.Header.Set("Icy-MetaData", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, errors.New(resp.Status)
+	}
+	metaInt, _ := strconv.Atoi(resp.Header.Get("Icy-Metaint"))
+	body := &idleReader{reader: resp.Body, timer: stalled, timeout: LiveReadTimeout}
+	n, err := io.Copy(s.buf, &icyReader{reader: body, metaInt: metaInt, left: metaInt, onTitle: s.onTitle})
+	if err == nil {
+		err = io.EOF
+	}
+	if ctx.Err() != nil && s.ctx.Err() == nil {
+		err = errLiveStreamStalled
+	}
+	return n, err
+}
+
+// idleReader restarts the timer before each read and stops it after, so
+// the timer only fires if a read takes longer than the timeout. The time
+// spent waiting for the buffer to have room isn't counted.
+type idleReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	r.timer.Reset(r.timeout)
+	n, err := r.reader.Read(p)
+	r.timer.Stop()
+	return n, err
+}
+
+// Read reads the buffered audio. It blocks until audio is available and
+// returns io.EOF when the stream is closed.
+func (s *liveStream) Read(p []byte) (int, error) {
+	return s.buf.Read(p)
+}
+
+// Close stops reading the stream and waits for the connection to close.
+func (s *liveStream) Close() error {
+	s.cancel()
+	s.buf.Close()
+	<-s.stopped
+	return nil
+}
+
+// liveBuffer is a bounded ring buffer. Writes block while the buffer is
+// full and reads block while it's empty.
+type liveBuffer struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	start  int
+	size   int
+	closed bool
+}
+
+func newLiveBuffer(size int) *liveBuffer {
+	b := &liveBuffer{data: make([]byte, size)}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// Write adds the bytes to the buffer. io.ErrClosedPipe is returned if the
+// buffer is closed.
+func (b *liveBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	written := 0
+	for written < len(p) {
+		for b.size == len(b.data) && !b.closed {
+			b.cond.Wait()
+		}
+		if b.closed {
+			return written, io.ErrClosedPipe
+		}
+		end := (b.start + b.size) % len(b.data)
+		free := len(b.data) - b.size
+		if end+free > len(b.data) {
+			free = len(b.data) - end
+		}
+		n := copy(b.data[end:end+free], p[written:])
+		b.size += n
+		written += n
+		b.cond.Broadcast()
+	}
+	return written, nil
+}
+
+// Read returns the buffered bytes. When the buffer is closed, the remaining
+// bytes are returned before io.EOF.
+func (b *liveBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.size == 0 && !b.closed {
+		b.cond.Wait()
+	}
+	if b.size == 0 {
+		return 0, io.EOF
+	}
+	n := b.size
+	if b.start+n > len(b.data) {
+		n = len(b.data) - b.start
+	}
+	n = copy(p, b.data[b.start:b.start+n])
+	b.start = (b.start + n) % len(b.data)
+	b.size -= n
+	b.cond.Broadcast()
+	return n, nil
+}
+
+// Close wakes up the blocked readers and writers.
+func (b *liveBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+	return nil
+}
+
+// icyReader removes the Shoutcast/Icecast metadata blocks from the audio.
+// A metadata block is sent after every metaInt bytes of audio.
+type icyReader struct {
+	reader  io.Reader
+	metaInt int
+	// left is the number of audio bytes until the next metadata block.
+	left    int
+	onTitle func(string)
+}
+
+func (r *icyReader) Read(p []byte) (int, error) {
+	if r.metaInt <= 0 {
+		return r.reader.Read(p)
+	}
+	if r.left == 0 {
+		if err := r.readMetadata(); err != nil {
+			return 0, err
+		}
+		r.left = r.metaInt
+	}
+	if len(p) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.reader.Read(p)
+	r.left -= n
+	return n, err
+}
+
+// readMetadata reads a metadata block. The first byte is the length of the
+// block divided by 16.
+func (r *icyReader) readMetadata() error {
+	var length [1]byte
+	if _, err := io.ReadFull(r.reader, length[:]); err != nil {
+		return err
+	}
+	if length[0] == 0 {
+		return nil
+	}
+	meta := make([]byte, int(length[0])*16)
+	if _, err := io.ReadFull(r.reader, meta); err != nil {
+		return err
+	}
+	if title, ok := streamTitle(string(meta)); ok && r.onTitle != nil {
+		r.onTitle(title)
+	}
+	return nil
+}
+
+// streamTitle returns the StreamTitle from the metadata, which looks like
+// "StreamTitle='Artist - Title';".
+func streamTitle(meta string) (string, bool) {
+	const key = "StreamTitle='"
+	start := strings.Index(meta, key)
+	if start < 0 {
+		return "", false
+	}
+	meta = meta[start+len(key):]
+	end := strings.Index(meta, "';")
+	if end < 0 {
+		end = strings.LastIndex(meta, "'")
+	}
+	if end < 0 {
+		return "", false
+	}
+	return meta[:end], true
+}
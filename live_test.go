@@ -0,0 +1,196 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// icyStream returns audio with a metadata block after every 4 bytes.
+func icyStream(title string) []byte {
+	meta := []byte("StreamTitle='" + title + "';")
+	blocks := (len(meta) + 15) / 16
+	meta = append(meta, make([]byte, blocks*16-len(meta))...)
+	var b bytes.Buffer
+	b.WriteString("abcd")
+	b.WriteByte(byte(blocks))
+	b.Write(meta)
+	b.WriteString("efgh")
+	b.WriteByte(0)
+	b.WriteString("ij")
+	return b.Bytes()
+}
+
+func TestLiveBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("wrap_around", func(t *testing.T) {
+		b := newLiveBuffer(4)
+		out := make([]byte, 4)
+		for _, s := range []string{"abc", "def", "ghi"} {
+			n, err := b.Write([]byte(s))
+			require.NoError(t, err)
+			assert.Equal(3, n)
+			var read []byte
+			for len(read) < 3 {
+				n, err = b.Read(out)
+				require.NoError(t, err)
+				read = append(read, out[:n]...)
+			}
+			assert.Equal(s, string(read))
+		}
+	})
+
+	t.Run("blocks_when_full", func(t *testing.T) {
+		b := newLiveBuffer(2)
+		done := make(chan struct{})
+		go func() {
+			b.Write([]byte("abcd"))
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatal("Write should block while the buffer is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+		buf, err := ioutil.ReadAll(io.LimitReader(b, 4))
+		require.NoError(t, err)
+		assert.Equal("abcd", string(buf))
+		<-done
+	})
+
+	t.Run("close", func(t *testing.T) {
+		b := newLiveBuffer(4)
+		b.Write([]byte("ab"))
+		b.Close()
+		buf, err := ioutil.ReadAll(b)
+		assert.NoError(err)
+		assert.Equal("ab", string(buf), "Buffered bytes should be read before EOF")
+		_, err = b.Write([]byte("c"))
+		assert.Equal(io.ErrClosedPipe, err)
+	})
+}
+
+func TestIcyReader(t *testing.T) {
+	assert := assert.New(t)
+	var title string
+	r := &icyReader{reader: bytes.NewReader(icyStream("Artist - Song")), metaInt: 4, left: 4, onTitle: func(s string) { title = s }}
+	buf, err := ioutil.ReadAll(r)
+	assert.NoError(err)
+	assert.Equal("abcdefghij", string(buf), "Metadata should be removed")
+	assert.Equal("Artist - Song", title)
+
+	_, ok := streamTitle("StreamUrl='';")
+	assert.False(ok)
+	s, ok := streamTitle("StreamTitle='It's';")
+	assert.True(ok)
+	assert.Equal("It's", s)
+}
+
+func TestLiveStream(t *testing.T) {
+	assert := assert.New(t)
+	LiveReconnectWait = time.Millisecond
+	defer func() { LiveReconnectWait = time.Second }()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal("1", r.Header.Get("Icy-MetaData"))
+		w.Header().Set("Icy-Metaint", "4")
+		// The connection is closed after the response, like a dropped stream.
+		w.Write(icyStream("Song"))
+	}))
+	defer ts.Close()
+
+	read := make(chan []byte, 1)
+	p, handler := getOffsetPlayer()
+	handler.doPlay = func(r io.Reader) error {
+		go func() {
+			buf := make([]byte, 20)
+			n, _ := io.ReadFull(r, buf)
+			read <- buf[:n]
+		}()
+		return nil
+	}
+	station := &InternetRadioStation{ID: "1", Name: "Station", StreamURL: ts.URL}
+	p.CreatePlayQueue([]*Track{station.Track()})
+	p.Play()
+
+	select {
+	case buf := <-read:
+		assert.Equal("abcdefghijabcdefghij", string(buf), "Stream should be reconnected after a drop")
+	case <-time.After(time.Second):
+		t.Fatal("No audio from the live stream")
+	}
+	assert.True(atomic.LoadInt32(&requests) >= 2)
+	assert.Equal("Song", p.StreamTitle())
+	assert.Equal(ErrSeekLiveStream, p.Seek(time.Minute))
+
+	p.Stop()
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(p.StreamTitle(), "Title should be cleared when stopped")
+	p.Close()
+}
+
+func TestLiveStreamStalled(t *testing.T) {
+	assert := assert.New(t)
+	LiveReconnectWait, LiveReadTimeout = time.Millisecond, 50*time.Millisecond
+	defer func() { LiveReconnectWait, LiveReadTimeout = time.Second, 30*time.Second }()
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte("abcdefghij"))
+		w.(http.Flusher).Flush()
+		// The station stops sending without closing the connection.
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	read := make(chan []byte, 1)
+	p, handler := getOffsetPlayer()
+	handler.doPlay = func(r io.Reader) error {
+		go func() {
+			buf := make([]byte, 20)
+			n, _ := io.ReadFull(r, buf)
+			read <- buf[:n]
+		}()
+		return nil
+	}
+	station := &InternetRadioStation{ID: "1", Name: "Station", StreamURL: ts.URL}
+	p.CreatePlayQueue([]*Track{station.Track()})
+	p.Play()
+
+	select {
+	case buf := <-read:
+		assert.Equal("abcdefghijabcdefghij", string(buf), "Stream should be reconnected when it stalls")
+	case <-time.After(time.Second):
+		t.Fatal("The stalled live stream was not reconnected")
+	}
+	assert.True(atomic.LoadInt32(&requests) >= 2)
+	p.Stop()
+	p.Close()
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrNoLyrics is returned if the track has no lyrics.
+var ErrNoLyrics = errors.New("no lyrics found")
+
+// LyricLine is a line in the lyrics.
+type LyricLine struct {
+	// Start is when the line is sung. It's zero for unsynced lyrics.
+	Start time.Duration
+	// Text is the line.
+	Text string
+}
+
+// Lyrics holds the lyrics for a track.
+type Lyrics struct {
+	// Synced is true if the lines have start times.
+	Synced bool
+	// Lines are the lines in order.
+	Lines []LyricLine
+}
+
+// LineAt returns the index of the line being sung at the position. If the
+// first line hasn't started yet or the lyrics are not synced, -1 is returned.
+func (l *Lyrics) LineAt(position time.Duration) int {
+	if !l.Synced {
+		return -1
+	}
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Start > position
+	}) - 1
+}
+
+// LyricsProvider is implemented by providers that can serve lyrics.
+type LyricsProvider interface {
+	// Lyrics returns the lyrics for the track. ErrNoLyrics is returned if
+	// the track has no lyrics.
+	Lyrics(ctx context.Context, track *Track) (*Lyrics, error)
+}
+
+// LyricsStore caches the lyrics.
+type LyricsStore interface {
+	// Lyrics returns the cached lyrics for the track. Nil is returned if no
+	// lyrics are cached.
+	Lyrics(trackID string) (*Lyrics, error)
+	// SaveLyrics caches the lyrics for the track. Empty lyrics are saved for
+	// tracks without lyrics so they are not requested again.
+	SaveLyrics(trackID string, lyrics *Lyrics) error
+}
+
+// GetLyrics returns the lyrics from the store, or from the provider if they
+// are not cached. Lyrics from the provider are added to the store.
+// ErrNoLyrics is returned if the track has no lyrics.
+func GetLyrics(ctx context.Context, db LyricsStore, provider LyricsProvider, track *Track) (*Lyrics, error) {
+	lyrics, err := db.Lyrics(track.ID)
+	if err != nil {
+		return nil, err
+	}
+	if lyrics == nil {
+		lyrics, err = provider.Lyrics(ctx, track)
+		if err == ErrNoLyrics {
+			lyrics = &Lyrics{}
+		} else if err != nil {
+			return nil, err
+		}
+		if err = db.SaveLyrics(track.ID, lyrics); err != nil {
+			return nil, err
+		}
+	}
+	if len(lyrics.Lines) == 0 {
+		return nil, ErrNoLyrics
+	}
+	return lyrics, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLyricsStore map[string]*Lyrics
+
+func (m mockLyricsStore) Lyrics(trackID string) (*Lyrics, error) {
+	return m[trackID], nil
+}
+
+func (m mockLyricsStore) SaveLyrics(trackID string, lyrics *Lyrics) error {
+	m[trackID] = lyrics
+	return nil
+}
+
+type mockLyricsProvider struct {
+	lyrics *Lyrics
+	calls  int
+}
+
+func (m *mockLyricsProvider) Lyrics(ctx context.Context, track *Track) (*Lyrics, error) {
+	m.calls++
+	if m.lyrics == nil {
+		return nil, ErrNoLyrics
+	}
+	return m.lyrics, nil
+}
+
+func TestLyrics(t *testing.T) {
+	assert := assert.New(t)
+	synced := &Lyrics{Synced: true, Lines: []LyricLine{
+		{Start: time.Second, Text: "one"},
+		{Start: 3 * time.Second, Text: "two"},
+		{Start: 5 * time.Second, Text: "three"},
+	}}
+
+	t.Run("line_at", func(t *testing.T) {
+		assert.Equal(-1, synced.LineAt(0))
+		assert.Equal(0, synced.LineAt(time.Second))
+		assert.Equal(0, synced.LineAt(2*time.Second))
+		assert.Equal(1, synced.LineAt(3*time.Second))
+		assert.Equal(2, synced.LineAt(time.Minute))
+		unsynced := &Lyrics{Lines: []LyricLine{{Text: "one"}}}
+		assert.Equal(-1, unsynced.LineAt(time.Minute))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		db := mockLyricsStore{}
+		provider := &mockLyricsProvider{lyrics: synced}
+		track := &Track{ID: "1"}
+		lyrics, err := GetLyrics(context.Background(), db, provider, track)
+		require.NoError(t, err)
+		assert.Equal(synced, lyrics)
+		_, err = GetLyrics(context.Background(), db, provider, track)
+		require.NoError(t, err)
+		assert.Equal(1, provider.calls, "Second call should use the cache")
+	})
+
+	t.Run("no_lyrics", func(t *testing.T) {
+		db := mockLyricsStore{}
+		provider := &mockLyricsProvider{}
+		track := &Track{ID: "1"}
+		_, err := GetLyrics(context.Background(), db, provider, track)
+		assert.Equal(ErrNoLyrics, err)
+		_, err = GetLyrics(context.Background(), db, provider, track)
+		assert.Equal(ErrNoLyrics, err)
+		assert.Equal(1, provider.calls, "Missing lyrics should be cached")
+	})
+}
@@ -0,0 +1,764 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package mpd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// commandFunc executes a command. The response is written to the client's
+// output buffer.
+type commandFunc func(s *Server, c *client, args []string) error
+
+// commands holds all the supported commands except idle, noidle and the
+// command list commands which are handled by the connection.
+var commands map[string]commandFunc
+
+func init() {
+	commands = map[string]commandFunc{
+		"add":                cmdAdd,
+		"addid":              cmdAddID,
+		"clear":              cmdClear,
+		"close":              cmdClose,
+		"commands":           cmdCommands,
+		"consume":            modeCommand("consume", "1"),
+		"currentsong":        cmdCurrentSong,
+		"find":               searchCommand(false, false),
+		"findadd":            searchCommand(false, true),
+		"getvol":             cmdGetVol,
+		"list":               cmdList,
+		"listplaylists":      cmdNoop,
+		"lsinfo":             cmdLsInfo,
+		"next":               cmdNext,
+		"notcommands":        cmdNoop,
+		"outputs":            cmdOutputs,
+		"pause":              cmdPause,
+		"ping":               cmdNoop,
+		"play":               cmdPlay,
+		"playid":             cmdPlayID,
+		"playlistid":         cmdPlaylistID,
+		"playlistinfo":       cmdPlaylistInfo,
+		"plchanges":          cmdPlChanges,
+		"plchangesposid":     cmdPlChangesPosID,
+		"previous":           cmdPrevious,
+		"random":             modeCommand("random", "0"),
+		"repeat":             modeCommand("repeat", "0"),
+		"replay_gain_status": cmdReplayGainStatus,
+		"search":             searchCommand(true, false),
+		"searchadd":          searchCommand(true, true),
+		"seek":               cmdSeek,
+		"seekcur":            cmdSeekCur,
+		"seekid":             cmdSeekID,
+		"setvol":             cmdSetVol,
+		"single":             modeCommand("single", "0"),
+		"stats":              cmdStats,
+		"status":             cmdStatus,
+		"stop":               cmdStop,
+		"tagtypes":           cmdTagTypes,
+		"urlhandlers":        cmdNoop,
+		"volume":             cmdVolume,
+	}
+}
+
+func argError(format string, a ...interface{}) error {
+	return newAckError(ackErrorArg, "", fmt.Sprintf(format, a...))
+}
+
+func noExistError(msg string) error {
+	return newAckError(ackErrorNoExist, "", msg)
+}
+
+func checkArgs(name string, args []string, min, max int) error {
+	if len(args) < min {
+		return argError("too few arguments for \"%s\"", name)
+	}
+	if len(args) > max {
+		return argError("too many arguments for \"%s\"", name)
+	}
+	return nil
+}
+
+// parsePos parses a song position or id argument.
+func parsePos(arg string) (int, error) {
+	pos, err := strconv.Atoi(arg)
+	if err != nil || pos < 0 {
+		return 0, argError("Integer expected: %s", arg)
+	}
+	return pos, nil
+}
+
+// playlist returns the tracks in the MPD playlist. The player removes the
+// tracks from the play queue when they are played so the current track is at
+// position 0, followed by the queue. A track's id is its position plus one.
+func (s *Server) playlist() []*jamsonic.Track {
+	tracks := make([]*jamsonic.Track, 0)
+	if ct := s.player.CurrentTrack(); ct != nil {
+		tracks = append(tracks, ct)
+	}
+	return append(tracks, s.player.Queue()...)
+}
+
+// writeSong writes the song's tags. If pos is negative, the song's position
+// and id are not written.
+func writeSong(c *client, lib *library, t *jamsonic.Track, pos int) {
+	e := lib.entryFor(t)
+	c.pair("file", e.uri())
+	for _, tag := range tagOrder {
+		for _, v := range e.tagValues(tag) {
+			c.pair(tagNames[tag], v)
+		}
+	}
+	if d := e.duration(); d > 0 {
+		c.pair("Time", strconv.Itoa(int(d.Seconds())))
+		c.pair("duration", formatSeconds(d))
+	}
+	if pos >= 0 {
+		c.pair("Pos", strconv.Itoa(pos))
+		c.pair("Id", strconv.Itoa(pos+1))
+	}
+}
+
+func cmdNoop(s *Server, c *client, args []string) error {
+	return nil
+}
+
+func cmdClose(s *Server, c *client, args []string) error {
+	c.closing = true
+	return nil
+}
+
+func cmdCommands(s *Server, c *client, args []string) error {
+	names := []string{"idle", "noidle", "command_list_begin", "command_list_ok_begin", "command_list_end"}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c.pair("command", name)
+	}
+	return nil
+}
+
+func cmdTagTypes(s *Server, c *client, args []string) error {
+	for _, tag := range tagOrder {
+		c.pair("tagtype", tagNames[tag])
+	}
+	return nil
+}
+
+func cmdOutputs(s *Server, c *client, args []string) error {
+	c.pair("outputid", "0")
+	c.pair("outputname", "Jamsonic")
+	c.pair("outputenabled", "1")
+	return nil
+}
+
+func cmdReplayGainStatus(s *Server, c *client, args []string) error {
+	c.pair("replay_gain_mode", "off")
+	return nil
+}
+
+// modeCommand returns a command for a playback mode the player doesn't
+// support changing. Setting the mode to its fixed value is accepted.
+func modeCommand(name, fixed string) commandFunc {
+	return func(s *Server, c *client, args []string) error {
+		if err := checkArgs(name, args, 1, 1); err != nil {
+			return err
+		}
+		if args[0] != fixed {
+			return newAckError(ackErrorSystem, "", fmt.Sprintf("%s %s is not supported", name, args[0]))
+		}
+		return nil
+	}
+}
+
+func cmdStatus(s *Server, c *client, args []string) error {
+	list := s.playlist()
+	state := s.player.GetCurrentState()
+	c.pair("volume", strconv.Itoa(s.player.Volume()))
+	c.pair("repeat", "0")
+	c.pair("random", "0")
+	c.pair("single", "0")
+	c.pair("consume", "1")
+	c.pair("playlist", strconv.FormatUint(uint64(atomic.LoadUint32(&s.playlistVersion)), 10))
+	c.pair("playlistlength", strconv.Itoa(len(list)))
+	switch state {
+	case jamsonic.Playing:
+		c.pair("state", "play")
+	case jamsonic.Paused:
+		c.pair("state", "pause")
+	default:
+		c.pair("state", "stop")
+		return nil
+	}
+	ct := s.player.CurrentTrack()
+	if ct == nil {
+		return nil
+	}
+	c.pair("song", "0")
+	c.pair("songid", "1")
+	elapsed := s.player.Position()
+	duration := trackDuration(ct)
+	c.pair("time", fmt.Sprintf("%d:%d", int(elapsed.Seconds()), int(duration.Seconds())))
+	c.pair("elapsed", formatSeconds(elapsed))
+	if duration > 0 {
+		c.pair("duration", formatSeconds(duration))
+	}
+	if len(list) > 1 {
+		c.pair("nextsong", "1")
+		c.pair("nextsongid", "2")
+	}
+	return nil
+}
+
+func cmdCurrentSong(s *Server, c *client, args []string) error {
+	ct := s.player.CurrentTrack()
+	if ct == nil {
+		return nil
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	writeSong(c, lib, ct, 0)
+	return nil
+}
+
+func cmdStats(s *Server, c *client, args []string) error {
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	c.pair("artists", strconv.Itoa(len(lib.artists)))
+	c.pair("albums", strconv.Itoa(lib.albumCount()))
+	c.pair("songs", strconv.Itoa(len(lib.entries)))
+	c.pair("uptime", strconv.Itoa(int(time.Since(s.started).Seconds())))
+	c.pair("db_playtime", strconv.Itoa(int(lib.playtime().Seconds())))
+	return nil
+}
+
+// playPos starts playing the song at the position in the playlist.
+func (s *Server) playPos(pos int) error {
+	list := s.playlist()
+	if pos >= len(list) {
+		return argError("Bad song index")
+	}
+	s.player.CreatePlayQueue(list[pos:])
+	if s.player.GetCurrentState() == jamsonic.Paused {
+		// Play would resume the paused track so skip to the new queue instead.
+		s.player.Next()
+		return nil
+	}
+	s.player.Play()
+	return nil
+}
+
+func cmdPlay(s *Server, c *client, args []string) error {
+	if err := checkArgs("play", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return resume(s)
+	}
+	pos, err := parsePos(args[0])
+	if err != nil {
+		return err
+	}
+	return s.playPos(pos)
+}
+
+func cmdPlayID(s *Server, c *client, args []string) error {
+	if err := checkArgs("playid", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return resume(s)
+	}
+	id, err := parsePos(args[0])
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return noExistError("No such song")
+	}
+	return s.playPos(id - 1)
+}
+
+// resume starts playing if stopped or resumes a paused track.
+func resume(s *Server) error {
+	switch s.player.GetCurrentState() {
+	case jamsonic.Paused:
+		s.player.Pause()
+	case jamsonic.Stopped:
+		if len(s.player.Queue()) == 0 {
+			return nil
+		}
+		s.player.Play()
+	}
+	return nil
+}
+
+func cmdPause(s *Server, c *client, args []string) error {
+	if err := checkArgs("pause", args, 0, 1); err != nil {
+		return err
+	}
+	state := s.player.GetCurrentState()
+	if len(args) == 0 {
+		s.player.Pause()
+		return nil
+	}
+	switch args[0] {
+	case "1":
+		if state == jamsonic.Playing {
+			s.player.Pause()
+		}
+	case "0":
+		if state == jamsonic.Paused {
+			s.player.Pause()
+		}
+	default:
+		return argError("Boolean (0/1) expected: %s", args[0])
+	}
+	return nil
+}
+
+func cmdStop(s *Server, c *client, args []string) error {
+	s.player.Stop()
+	return nil
+}
+
+func cmdNext(s *Server, c *client, args []string) error {
+	if s.player.GetCurrentState() == jamsonic.Stopped {
+		return nil
+	}
+	if len(s.player.Queue()) == 0 {
+		// The current track was the last one, consume it and stop.
+		s.player.Clear()
+		return nil
+	}
+	s.player.Next()
+	return nil
+}
+
+func cmdPrevious(s *Server, c *client, args []string) error {
+	s.player.Previous()
+	return nil
+}
+
+// seekPos seeks in the song at the position in the playlist. If it's not the
+// current song, the song is started first.
+func (s *Server) seekPos(pos int, offset time.Duration) error {
+	if pos != 0 || s.player.GetCurrentState() == jamsonic.Stopped {
+		if err := s.playPos(pos); err != nil {
+			return err
+		}
+	}
+	return s.player.Seek(offset)
+}
+
+func cmdSeek(s *Server, c *client, args []string) error {
+	if err := checkArgs("seek", args, 2, 2); err != nil {
+		return err
+	}
+	pos, err := parsePos(args[0])
+	if err != nil {
+		return err
+	}
+	offset, err := parseTime(args[1])
+	if err != nil {
+		return argError("%v", err)
+	}
+	return s.seekPos(pos, offset)
+}
+
+func cmdSeekID(s *Server, c *client, args []string) error {
+	if err := checkArgs("seekid", args, 2, 2); err != nil {
+		return err
+	}
+	id, err := parsePos(args[0])
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		return noExistError("No such song")
+	}
+	offset, err := parseTime(args[1])
+	if err != nil {
+		return argError("%v", err)
+	}
+	return s.seekPos(id-1, offset)
+}
+
+func cmdSeekCur(s *Server, c *client, args []string) error {
+	if err := checkArgs("seekcur", args, 1, 1); err != nil {
+		return err
+	}
+	offset, err := parseTime(args[0])
+	if err != nil {
+		return argError("%v", err)
+	}
+	if s.player.GetCurrentState() == jamsonic.Stopped {
+		return newAckError(ackErrorSystem, "", "Not playing")
+	}
+	// A signed value is relative to the current position.
+	if strings.HasPrefix(args[0], "+") || strings.HasPrefix(args[0], "-") {
+		offset = s.player.Position() + offset
+	}
+	return s.player.Seek(offset)
+}
+
+func cmdSetVol(s *Server, c *client, args []string) error {
+	if err := checkArgs("setvol", args, 1, 1); err != nil {
+		return err
+	}
+	vol, err := strconv.Atoi(args[0])
+	if err != nil || vol < 0 || vol > jamsonic.MaxVolume {
+		return argError("Invalid volume value: %s", args[0])
+	}
+	return s.player.SetVolume(vol)
+}
+
+func cmdVolume(s *Server, c *client, args []string) error {
+	if err := checkArgs("volume", args, 1, 1); err != nil {
+		return err
+	}
+	change, err := strconv.Atoi(args[0])
+	if err != nil {
+		return argError("Integer expected: %s", args[0])
+	}
+	return s.player.SetVolume(s.player.Volume() + change)
+}
+
+func cmdGetVol(s *Server, c *client, args []string) error {
+	c.pair("volume", strconv.Itoa(s.player.Volume()))
+	return nil
+}
+
+func cmdAdd(s *Server, c *client, args []string) error {
+	if err := checkArgs("add", args, 1, 1); err != nil {
+		return err
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	tracks, ok := lib.tracksUnder(args[0])
+	if !ok {
+		return noExistError("No such directory")
+	}
+	s.player.Enqueue(tracks...)
+	return nil
+}
+
+func cmdAddID(s *Server, c *client, args []string) error {
+	if err := checkArgs("addid", args, 1, 2); err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		return argError("Adding at a position is not supported")
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	_, _, track, ok := lib.lookup(args[0])
+	if !ok || track == nil {
+		return noExistError("No such song")
+	}
+	s.player.Enqueue(track)
+	c.pair("Id", strconv.Itoa(len(s.playlist())))
+	return nil
+}
+
+func cmdClear(s *Server, c *client, args []string) error {
+	s.player.Clear()
+	return nil
+}
+
+// writePlaylist writes the songs in the playlist between start and end. If
+// end is negative, the songs to the end of the playlist are written.
+func (s *Server) writePlaylist(c *client, start, end int) error {
+	list := s.playlist()
+	if end < 0 || end > len(list) {
+		end = len(list)
+	}
+	if start > end || (start == end && start != 0) {
+		return argError("Bad song index")
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	for i := start; i < end; i++ {
+		writeSong(c, lib, list[i], i)
+	}
+	return nil
+}
+
+func cmdPlaylistInfo(s *Server, c *client, args []string) error {
+	if err := checkArgs("playlistinfo", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return s.writePlaylist(c, 0, -1)
+	}
+	start, end, err := parseRange(args[0])
+	if err != nil {
+		return argError("%v", err)
+	}
+	return s.writePlaylist(c, start, end)
+}
+
+func cmdPlaylistID(s *Server, c *client, args []string) error {
+	if err := checkArgs("playlistid", args, 0, 1); err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return s.writePlaylist(c, 0, -1)
+	}
+	id, err := parsePos(args[0])
+	if err != nil {
+		return err
+	}
+	if id == 0 || id > len(s.playlist()) {
+		return noExistError("No such song")
+	}
+	return s.writePlaylist(c, id-1, id)
+}
+
+// cmdPlChanges returns the whole playlist since the changes between the
+// versions are not tracked.
+func cmdPlChanges(s *Server, c *client, args []string) error {
+	if err := checkArgs("plchanges", args, 1, 2); err != nil {
+		return err
+	}
+	return s.writePlaylist(c, 0, -1)
+}
+
+func cmdPlChangesPosID(s *Server, c *client, args []string) error {
+	if err := checkArgs("plchangesposid", args, 1, 2); err != nil {
+		return err
+	}
+	for i := range s.playlist() {
+		c.pair("cpos", strconv.Itoa(i))
+		c.pair("Id", strconv.Itoa(i+1))
+	}
+	return nil
+}
+
+func cmdLsInfo(s *Server, c *client, args []string) error {
+	if err := checkArgs("lsinfo", args, 0, 1); err != nil {
+		return err
+	}
+	uri := ""
+	if len(args) == 1 {
+		uri = args[0]
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+	artist, album, track, ok := lib.lookup(uri)
+	if !ok {
+		return noExistError("Not found")
+	}
+	switch {
+	case track != nil:
+		writeSong(c, lib, track, -1)
+	case album != nil:
+		for _, t := range album.Tracks {
+			writeSong(c, lib, t, -1)
+		}
+	case artist != nil:
+		for _, a := range artist.Albums {
+			c.pair("directory", uriEscaper.Replace(artist.Name)+"/"+uriEscaper.Replace(a.Name))
+		}
+	default:
+		for _, a := range lib.artists {
+			c.pair("directory", uriEscaper.Replace(a.Name))
+		}
+	}
+	return nil
+}
+
+// searchCommand returns the command for find and search. If fuzzy is true,
+// the filters match case insensitive substrings. If add is true, the found
+// songs are added to the playlist instead of returned.
+func searchCommand(fuzzy, add bool) commandFunc {
+	return func(s *Server, c *client, args []string) error {
+		if len(args) == 0 {
+			return argError("too few arguments")
+		}
+		var sortTag, window string
+		for len(args) >= 2 {
+			key := strings.ToLower(args[len(args)-2])
+			if key == "sort" {
+				sortTag = strings.ToLower(args[len(args)-1])
+			} else if key == "window" {
+				window = args[len(args)-1]
+			} else {
+				break
+			}
+			args = args[:len(args)-2]
+		}
+		filters, err := parseFilters(args, fuzzy)
+		if err != nil {
+			return argError("%v", err)
+		}
+		lib, err := loadLibrary(s.store)
+		if err != nil {
+			return err
+		}
+		found := lib.find(filters)
+		if sortTag != "" {
+			sortEntries(found, sortTag)
+		}
+		if window != "" {
+			start, end, err := parseRange(window)
+			if err != nil {
+				return argError("%v", err)
+			}
+			if end < 0 || end > len(found) {
+				end = len(found)
+			}
+			if start > end {
+				start = end
+			}
+			found = found[start:end]
+		}
+		if add {
+			tracks := make([]*jamsonic.Track, len(found))
+			for i, e := range found {
+				tracks[i] = e.track
+			}
+			s.player.Enqueue(tracks...)
+			return nil
+		}
+		for _, e := range found {
+			writeSong(c, lib, e.track, -1)
+		}
+		return nil
+	}
+}
+
+// sortEntries sorts the entries by the first value of the tag. If the tag is
+// prefixed with a "-", the order is descending.
+func sortEntries(entries []*entry, tag string) {
+	desc := strings.HasPrefix(tag, "-")
+	tag = strings.TrimPrefix(tag, "-")
+	value := func(e *entry) string {
+		values := e.tagValues(tag)
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if desc {
+			return value(entries[i]) > value(entries[j])
+		}
+		return value(entries[i]) < value(entries[j])
+	})
+}
+
+func cmdList(s *Server, c *client, args []string) error {
+	if len(args) == 0 {
+		return argError("too few arguments for \"list\"")
+	}
+	tag := strings.ToLower(args[0])
+	name, ok := tagNames[tag]
+	if tag == "file" {
+		name, ok = "file", true
+	}
+	if !ok {
+		return argError("Unknown tag type: %s", args[0])
+	}
+	args = args[1:]
+	groups := make([]string, 0)
+	for len(args) >= 2 && strings.ToLower(args[len(args)-2]) == "group" {
+		groups = append([]string{strings.ToLower(args[len(args)-1])}, groups...)
+		args = args[:len(args)-2]
+	}
+	for _, g := range groups {
+		if _, ok := tagNames[g]; !ok {
+			return argError("Unknown tag type: %s", g)
+		}
+	}
+	// The old syntax allows the artist as the only filter when listing albums.
+	if tag == "album" && len(args) == 1 && !strings.HasPrefix(args[0], "(") {
+		args = []string{"artist", args[0]}
+	}
+	filters, err := parseFilters(args, false)
+	if err != nil {
+		return argError("%v", err)
+	}
+	lib, err := loadLibrary(s.store)
+	if err != nil {
+		return err
+	}
+
+	// Each row holds the group values followed by the tag value.
+	rows := make([][]string, 0)
+	seen := make(map[string]struct{})
+	for _, e := range lib.find(filters) {
+		groupValues := make([]string, len(groups))
+		for i, g := range groups {
+			if values := e.tagValues(g); len(values) > 0 {
+				groupValues[i] = values[0]
+			}
+		}
+		for _, v := range e.tagValues(tag) {
+			row := append(append([]string{}, groupValues...), v)
+			key := strings.Join(row, "\x00")
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		for k := range rows[i] {
+			if rows[i][k] != rows[j][k] {
+				return rows[i][k] < rows[j][k]
+			}
+		}
+		return false
+	})
+
+	var prev []string
+	for _, row := range rows {
+		changed := prev == nil
+		for i, g := range groups {
+			changed = changed || prev[i] != row[i]
+			if changed {
+				c.pair(tagNames[g], row[i])
+			}
+		}
+		c.pair(name, row[len(row)-1])
+		prev = row
+	}
+	return nil
+}
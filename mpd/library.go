@@ -0,0 +1,287 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package mpd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// tagNames maps the lower case tag names to the names used in responses.
+var tagNames = map[string]string{
+	"artist":      "Artist",
+	"albumartist": "AlbumArtist",
+	"album":       "Album",
+	"title":       "Title",
+	"track":       "Track",
+	"disc":        "Disc",
+	"date":        "Date",
+}
+
+// tagOrder is the order the tags are listed by tagtypes and in song responses.
+var tagOrder = []string{"artist", "albumartist", "album", "title", "track", "disc", "date"}
+
+// uriEscaper escapes the characters used as separators in the URIs.
+var uriEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
+
+// uriUnescaper reverses uriEscaper.
+var uriUnescaper = strings.NewReplacer("%2F", "/", "%25", "%")
+
+// entry is a track in the library together with its artist and album.
+type entry struct {
+	artist *jamsonic.Artist
+	album  *jamsonic.Album
+	track  *jamsonic.Track
+}
+
+// uri returns the path used by the clients to identify the track. The path is
+// "artist/album/trackID".
+func (e *entry) uri() string {
+	return strings.Join([]string{
+		uriEscaper.Replace(e.artist.Name),
+		uriEscaper.Replace(e.album.Name),
+		uriEscaper.Replace(e.track.ID),
+	}, "/")
+}
+
+// tagValues returns the values for the tag. The special tag "any" matches
+// all tags and "file" matches the URI.
+func (e *entry) tagValues(tag string) []string {
+	switch tag {
+	case "artist":
+		return []string{e.artist.Name}
+	case "albumartist":
+		if e.track.AlbumArtist != "" {
+			return []string{e.track.AlbumArtist}
+		}
+		return []string{e.album.Artist}
+	case "album":
+		return []string{e.album.Name}
+	case "title":
+		return []string{e.track.Title}
+	case "track":
+		if e.track.TrackNumber == 0 {
+			return []string{}
+		}
+		return []string{strconv.FormatUint(uint64(e.track.TrackNumber), 10)}
+	case "disc":
+		if e.track.DiscNumber == 0 {
+			return []string{}
+		}
+		return []string{strconv.FormatUint(uint64(e.track.DiscNumber), 10)}
+	case "date":
+		year := e.track.Year
+		if year == 0 {
+			year = e.album.Year
+		}
+		if year == 0 {
+			return []string{}
+		}
+		return []string{strconv.FormatUint(uint64(year), 10)}
+	case "file":
+		return []string{e.uri()}
+	case "any":
+		values := []string{e.uri()}
+		for _, t := range tagOrder {
+			values = append(values, e.tagValues(t)...)
+		}
+		return values
+	}
+	return []string{}
+}
+
+// duration returns the length of the track.
+func (e *entry) duration() time.Duration {
+	return trackDuration(e.track)
+}
+
+// trackDuration returns the length of the track.
+func trackDuration(t *jamsonic.Track) time.Duration {
+	ms, err := strconv.ParseInt(t.DurationMillis, 10, 64)
+	if err != nil {
+		return time.Duration(0)
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// matches returns true if the entry matches all the filters.
+func (e *entry) matches(filters []*filter) bool {
+	for _, f := range filters {
+		if !f.match(e.tagValues(f.tag)) {
+			return false
+		}
+	}
+	return true
+}
+
+// library is an index of the tracks in the music store.
+type library struct {
+	artists []*jamsonic.Artist
+	entries []*entry
+	byID    map[string]*entry
+}
+
+// loadLibrary reads the artists from the store and indexes the tracks.
+func loadLibrary(store jamsonic.MusicStore) (*library, error) {
+	artists, err := store.Artists()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i].Name) < strings.ToLower(artists[j].Name)
+	})
+	lib := &library{
+		artists: artists,
+		entries: make([]*entry, 0),
+		byID:    make(map[string]*entry),
+	}
+	for _, artist := range artists {
+		for _, album := range artist.Albums {
+			for _, track := range album.Tracks {
+				e := &entry{artist: artist, album: album, track: track}
+				lib.entries = append(lib.entries, e)
+				lib.byID[track.ID] = e
+			}
+		}
+	}
+	return lib, nil
+}
+
+// entryFor returns the library entry for the track. If the track is not in the
+// library, an entry is created from the track's own fields.
+func (l *library) entryFor(t *jamsonic.Track) *entry {
+	if e, ok := l.byID[t.ID]; ok {
+		return e
+	}
+	return &entry{
+		artist: &jamsonic.Artist{Name: t.Artist},
+		album:  &jamsonic.Album{Name: t.Album, Artist: t.AlbumArtist, Year: t.Year},
+		track:  t,
+	}
+}
+
+// splitURI splits the URI into its unescaped path elements.
+func splitURI(uri string) []string {
+	uri = strings.Trim(uri, "/")
+	if uri == "" {
+		return []string{}
+	}
+	parts := strings.Split(uri, "/")
+	for i, p := range parts {
+		parts[i] = uriUnescaper.Replace(p)
+	}
+	return parts
+}
+
+// lookup returns the artist, album and track the URI points to. Elements not
+// included in the URI are nil. If the URI doesn't exist in the library,
+// ok is false.
+func (l *library) lookup(uri string) (artist *jamsonic.Artist, album *jamsonic.Album, track *jamsonic.Track, ok bool) {
+	parts := splitURI(uri)
+	if len(parts) > 3 {
+		return nil, nil, nil, false
+	}
+	if len(parts) == 0 {
+		return nil, nil, nil, true
+	}
+	for _, a := range l.artists {
+		if a.Name == parts[0] {
+			artist = a
+			break
+		}
+	}
+	if artist == nil {
+		return nil, nil, nil, false
+	}
+	if len(parts) == 1 {
+		return artist, nil, nil, true
+	}
+	for _, a := range artist.Albums {
+		if a.Name == parts[1] {
+			album = a
+			break
+		}
+	}
+	if album == nil {
+		return nil, nil, nil, false
+	}
+	if len(parts) == 2 {
+		return artist, album, nil, true
+	}
+	for _, t := range album.Tracks {
+		if t.ID == parts[2] {
+			return artist, album, t, true
+		}
+	}
+	return nil, nil, nil, false
+}
+
+// tracksUnder returns all the tracks under the URI.
+func (l *library) tracksUnder(uri string) ([]*jamsonic.Track, bool) {
+	artist, album, track, ok := l.lookup(uri)
+	if !ok {
+		return nil, false
+	}
+	tracks := make([]*jamsonic.Track, 0)
+	switch {
+	case track != nil:
+		tracks = append(tracks, track)
+	case album != nil:
+		tracks = append(tracks, album.Tracks...)
+	default:
+		for _, e := range l.entries {
+			if artist == nil || e.artist == artist {
+				tracks = append(tracks, e.track)
+			}
+		}
+	}
+	return tracks, true
+}
+
+// find returns the entries that match all the filters.
+func (l *library) find(filters []*filter) []*entry {
+	found := make([]*entry, 0)
+	for _, e := range l.entries {
+		if e.matches(filters) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// albumCount returns the number of albums in the library.
+func (l *library) albumCount() int {
+	n := 0
+	for _, a := range l.artists {
+		n = n + len(a.Albums)
+	}
+	return n
+}
+
+// playtime returns the total length of all the tracks in the library.
+func (l *library) playtime() time.Duration {
+	var d time.Duration
+	for _, e := range l.entries {
+		d = d + e.duration()
+	}
+	return d
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package mpd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MPD error codes used in ACK responses.
+const (
+	ackErrorNotList = 1
+	ackErrorArg     = 2
+	ackErrorUnknown = 5
+	ackErrorNoExist = 50
+	ackErrorSystem  = 52
+)
+
+var (
+	errUnterminatedQuote = errors.New("missing closing '\"'")
+	errBadFilter         = errors.New("malformed filter expression")
+)
+
+// ackError is an error that is sent back to the client as an ACK response.
+type ackError struct {
+	code    int
+	command string
+	message string
+}
+
+func (e *ackError) Error() string {
+	return e.message
+}
+
+// format returns the error as an ACK line. The index is the position of the
+// command in a command list.
+func (e *ackError) format(index int) string {
+	return fmt.Sprintf("ACK [%d@%d] {%s} %s\n", e.code, index, e.command, e.message)
+}
+
+func newAckError(code int, command, message string) *ackError {
+	return &ackError{code: code, command: command, message: message}
+}
+
+// parseArgs splits a request line into the command and its arguments.
+// Arguments can be quoted with double quotes and characters inside quotes
+// can be escaped with a backslash.
+func parseArgs(line string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg, quoted, escaped := false, false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			if quoted {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			} else {
+				inArg = true
+			}
+			quoted = !quoted
+		case !quoted && (r == ' ' || r == '\t'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, errUnterminatedQuote
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// filter matches a tag against a value.
+type filter struct {
+	tag      string
+	value    string
+	negate   bool
+	contains bool
+	fold     bool
+}
+
+// match returns true if any of the values matches the filter.
+func (f *filter) match(values []string) bool {
+	matched := false
+	for _, v := range values {
+		if f.matchValue(v) {
+			matched = true
+			break
+		}
+	}
+	return matched != f.negate
+}
+
+func (f *filter) matchValue(v string) bool {
+	needle := f.value
+	if f.fold {
+		v, needle = strings.ToLower(v), strings.ToLower(needle)
+	}
+	if f.contains {
+		return strings.Contains(v, needle)
+	}
+	return v == needle
+}
+
+// parseFilters parses the filter arguments of find, search and list. Both the
+// old "TAG VALUE" pairs and the new "(TAG == 'VALUE')" expressions are supported.
+// If fuzzy is true, the filters match case insensitive substrings.
+func parseFilters(args []string, fuzzy bool) ([]*filter, error) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "(") {
+		filters, err := parseExpression(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if fuzzy {
+			for _, f := range filters {
+				f.fold = true
+				f.contains = true
+			}
+		}
+		return filters, nil
+	}
+	if len(args)%2 != 0 {
+		return nil, errBadFilter
+	}
+	filters := make([]*filter, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		filters = append(filters, &filter{
+			tag:      strings.ToLower(args[i]),
+			value:    args[i+1],
+			contains: fuzzy,
+			fold:     fuzzy,
+		})
+	}
+	return filters, nil
+}
+
+// parseExpression parses a filter expression. Only expressions joined by AND
+// are supported.
+func parseExpression(expr string) ([]*filter, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return nil, errBadFilter
+	}
+	inner := strings.TrimSpace(expr[1 : len(expr)-1])
+	// A group of expressions.
+	if strings.HasPrefix(inner, "(") {
+		parts, err := splitAnd(inner)
+		if err != nil {
+			return nil, err
+		}
+		filters := make([]*filter, 0, len(parts))
+		for _, part := range parts {
+			f, err := parseExpression(part)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f...)
+		}
+		return filters, nil
+	}
+	fields := strings.SplitN(inner, " ", 3)
+	if len(fields) != 3 {
+		return nil, errBadFilter
+	}
+	f := &filter{tag: strings.ToLower(fields[0])}
+	switch fields[1] {
+	case "==":
+	case "!=":
+		f.negate = true
+	case "contains":
+		f.contains = true
+	default:
+		return nil, errBadFilter
+	}
+	value, err := unquote(strings.TrimSpace(fields[2]))
+	if err != nil {
+		return nil, err
+	}
+	f.value = value
+	return []*filter{f}, nil
+}
+
+// splitAnd splits expressions joined by AND at the outer most level.
+func splitAnd(expr string) ([]string, error) {
+	parts := make([]string, 0)
+	depth, start := 0, 0
+	var quote rune
+	escaped := false
+	// Text between the expressions, should only be the AND operator.
+	var between strings.Builder
+	for i, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case depth > 0 && (r == '\'' || r == '"'):
+			quote = r
+		case r == '(':
+			if depth == 0 {
+				expected := "AND"
+				if len(parts) == 0 {
+					expected = ""
+				}
+				if strings.TrimSpace(between.String()) != expected {
+					return nil, errBadFilter
+				}
+				between.Reset()
+				start = i
+			}
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return nil, errBadFilter
+			}
+			if depth == 0 {
+				parts = append(parts, expr[start:i+1])
+			}
+		case depth == 0:
+			between.WriteRune(r)
+		}
+	}
+	if depth != 0 || quote != 0 || strings.TrimSpace(between.String()) != "" {
+		return nil, errBadFilter
+	}
+	return parts, nil
+}
+
+// unquote removes the quotes around a value in a filter expression.
+func unquote(v string) (string, error) {
+	if len(v) < 2 || (v[0] != '\'' && v[0] != '"') || v[len(v)-1] != v[0] {
+		return "", errBadFilter
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range v[1 : len(v)-1] {
+		if !escaped && r == '\\' {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String(), nil
+}
+
+// parseRange parses a "START:END" or "POS" argument. An open end is returned
+// as -1.
+func parseRange(arg string) (int, int, error) {
+	if !strings.Contains(arg, ":") {
+		pos, err := strconv.Atoi(arg)
+		if err != nil || pos < 0 {
+			return 0, 0, fmt.Errorf("Integer expected: %s", arg)
+		}
+		return pos, pos + 1, nil
+	}
+	parts := strings.SplitN(arg, ":", 2)
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("Integer expected: %s", parts[0])
+	}
+	if parts[1] == "" {
+		return start, -1, nil
+	}
+	end, err := strconv.Atoi(parts[1])
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("Integer expected: %s", parts[1])
+	}
+	return start, end, nil
+}
+
+// parseTime parses a time argument in seconds. Fractions are allowed.
+func parseTime(arg string) (time.Duration, error) {
+	secs, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Float expected: %s", arg)
+	}
+	return time.Duration(secs * float64(time.Second)), nil
+}
+
+// formatSeconds returns the duration in seconds with millisecond precision.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package mpd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("plain", func(t *testing.T) {
+		args, err := parseArgs("seek 0  12.5")
+		assert.NoError(err)
+		assert.Equal([]string{"seek", "0", "12.5"}, args)
+	})
+
+	t.Run("quoted", func(t *testing.T) {
+		args, err := parseArgs(`find artist "The \"Band\"" album ""`)
+		assert.NoError(err)
+		assert.Equal([]string{"find", "artist", `The "Band"`, "album", ""}, args)
+	})
+
+	t.Run("unterminated", func(t *testing.T) {
+		_, err := parseArgs(`find artist "The Band`)
+		assert.Equal(errUnterminatedQuote, err)
+	})
+}
+
+func TestParseFilters(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("pairs", func(t *testing.T) {
+		filters, err := parseFilters([]string{"Artist", "A", "album", "B"}, false)
+		assert.NoError(err)
+		assert.Equal([]*filter{
+			&filter{tag: "artist", value: "A"},
+			&filter{tag: "album", value: "B"},
+		}, filters)
+	})
+
+	t.Run("odd_pairs", func(t *testing.T) {
+		_, err := parseFilters([]string{"artist"}, false)
+		assert.Equal(errBadFilter, err)
+	})
+
+	t.Run("expression", func(t *testing.T) {
+		filters, err := parseFilters([]string{`((artist == 'A\'s') AND (album != "B"))`}, false)
+		assert.NoError(err)
+		assert.Equal([]*filter{
+			&filter{tag: "artist", value: "A's"},
+			&filter{tag: "album", value: "B", negate: true},
+		}, filters)
+	})
+
+	t.Run("fuzzy_expression", func(t *testing.T) {
+		filters, err := parseFilters([]string{`(title contains 'x')`}, true)
+		assert.NoError(err)
+		assert.Equal([]*filter{&filter{tag: "title", value: "x", contains: true, fold: true}}, filters)
+	})
+
+	t.Run("bad_expressions", func(t *testing.T) {
+		for _, expr := range []string{
+			"(artist == A)",
+			"(artist ~= 'A')",
+			"((artist == 'A') OR (album == 'B'))",
+			"((artist == 'A')",
+		} {
+			_, err := parseFilters([]string{expr}, false)
+			assert.Equal(errBadFilter, err, expr)
+		}
+	})
+
+	t.Run("match", func(t *testing.T) {
+		assert.True((&filter{value: "abc"}).match([]string{"x", "abc"}))
+		assert.False((&filter{value: "ABC"}).match([]string{"abc"}))
+		assert.True((&filter{value: "B", contains: true, fold: true}).match([]string{"abc"}))
+		assert.True((&filter{value: "abc", negate: true}).match([]string{}))
+	})
+}
+
+func TestParseRange(t *testing.T) {
+	assert := assert.New(t)
+	start, end, err := parseRange("3")
+	assert.NoError(err)
+	assert.Equal([]int{3, 4}, []int{start, end})
+
+	start, end, err = parseRange("1:")
+	assert.NoError(err)
+	assert.Equal([]int{1, -1}, []int{start, end})
+
+	_, _, err = parseRange("4:2")
+	assert.Error(err)
+}
+
+func TestParseTime(t *testing.T) {
+	assert := assert.New(t)
+	d, err := parseTime("1.5")
+	assert.NoError(err)
+	assert.Equal(1500*time.Millisecond, d)
+	assert.Equal("1.500", formatSeconds(d))
+
+	_, err = parseTime("abc")
+	assert.Error(err)
+}
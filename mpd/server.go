@@ -0,0 +1,372 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package mpd implements a subset of the Music Player Daemon protocol. It allows
+// MPD clients to control the Jamsonic player and browse the cached library.
+package mpd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// greeting is sent to the client when it connects. The version is the
+// protocol version the implemented subset is based on.
+const greeting = "OK MPD 0.21.0\n"
+
+// ErrServerClosed is returned by Serve after the server has been closed.
+var ErrServerClosed = errors.New("mpd: server closed")
+
+// subsystems are the subsystems a client can wait for with idle.
+var subsystems = []string{
+	"database", "update", "stored_playlist", "playlist", "player",
+	"mixer", "output", "options", "sticker", "subscription", "message",
+}
+
+// Server serves MPD clients. The clients control the player and browse the
+// library in the music store.
+type Server struct {
+	player  *jamsonic.Player
+	store   jamsonic.MusicStore
+	logger  *jamsonic.Logger
+	started time.Time
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+
+	// playlistVersion is increased every time the playlist changes.
+	playlistVersion uint32
+	cancelEvents    func()
+}
+
+// New returns a new Server for the player. The library is read from the store.
+func New(player *jamsonic.Player, store jamsonic.MusicStore, logger *jamsonic.Logger) *Server {
+	s := &Server{
+		player:          player,
+		store:           store,
+		logger:          logger,
+		started:         time.Now(),
+		conns:           make(map[net.Conn]struct{}),
+		playlistVersion: 1,
+	}
+	events, cancel := player.Subscribe()
+	s.cancelEvents = cancel
+	go s.countVersions(events)
+	return s
+}
+
+// countVersions increases the playlist version for each change of the playlist.
+func (s *Server) countVersions(events <-chan *jamsonic.Event) {
+	for e := range events {
+		if e.Type == jamsonic.QueueChanged || e.Type == jamsonic.TrackChanged {
+			atomic.AddUint32(&s.playlistVersion, 1)
+		}
+	}
+}
+
+// ListenAndServe listens on the TCP address and serves the clients that connect.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on the listener and serves them. Serve always
+// returns a non-nil error. After Close, ErrServerClosed is returned.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+	s.logger.InfoLog("Listening for MPD clients on " + l.Addr().String())
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the listener and closes all client connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cancelEvents()
+	for c := range s.conns {
+		c.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// client holds the state of a client connection.
+type client struct {
+	w *bufio.Writer
+	// out buffers the response of the command being executed. It's only
+	// written to the connection if the command succeeds.
+	out *bytes.Buffer
+	// changed holds the subsystems that have changed since they were last
+	// reported by idle.
+	changed map[string]struct{}
+	// idle holds the subsystems the client is waiting for. It's nil if the
+	// client is not idle.
+	idle map[string]struct{}
+	// Command list state.
+	inList bool
+	listOK bool
+	list   []string
+	// closing is set when the connection should be closed.
+	closing bool
+}
+
+// pair writes a key value pair to the response.
+func (c *client) pair(key, value string) {
+	fmt.Fprintf(c.out, "%s: %s\n", key, value)
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		conn.Close()
+		return
+	}
+	s.conns[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+	s.logger.DebugLog("Client connected from " + conn.RemoteAddr().String())
+
+	events, cancel := s.player.Subscribe()
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	lines := make(chan string)
+	go readLines(conn, lines, done)
+
+	c := &client{
+		w:       bufio.NewWriter(conn),
+		out:     new(bytes.Buffer),
+		changed: make(map[string]struct{}),
+	}
+	c.w.WriteString(greeting)
+	for !c.closing {
+		if err := c.w.Flush(); err != nil {
+			return
+		}
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			c.changed[subsystem(e.Type)] = struct{}{}
+			if c.idle != nil {
+				c.sendIdle(false)
+			}
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			s.handleLine(c, line)
+		}
+	}
+	c.w.Flush()
+}
+
+// readLines sends the lines read from r to the lines channel until r is
+// closed or done is closed.
+func readLines(r io.Reader, lines chan<- string, done <-chan struct{}) {
+	defer close(lines)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		select {
+		case lines <- strings.TrimRight(scanner.Text(), "\r"):
+		case <-done:
+			return
+		}
+	}
+}
+
+// subsystem returns the idle subsystem for the event.
+func subsystem(t jamsonic.EventType) string {
+	switch t {
+	case jamsonic.QueueChanged:
+		return "playlist"
+	case jamsonic.VolumeChanged:
+		return "mixer"
+	default:
+		return "player"
+	}
+}
+
+// handleLine handles a request line from the client.
+func (s *Server) handleLine(c *client, line string) {
+	if c.idle != nil {
+		// The only command allowed while idle is noidle.
+		if line == "noidle" {
+			c.sendIdle(true)
+		} else {
+			c.closing = true
+		}
+		return
+	}
+	if c.inList {
+		if line == "command_list_end" {
+			s.executeList(c)
+			return
+		}
+		c.list = append(c.list, line)
+		return
+	}
+	switch line {
+	case "command_list_begin", "command_list_ok_begin":
+		c.inList = true
+		c.listOK = line == "command_list_ok_begin"
+		c.list = make([]string, 0)
+		return
+	case "noidle":
+		// Noidle when not idle is ignored.
+		return
+	}
+	if strings.HasPrefix(line, "idle") {
+		args, err := parseArgs(line)
+		if err == nil && args[0] == "idle" {
+			c.startIdle(args[1:])
+			return
+		}
+	}
+	if ack := s.execute(c, line); ack != nil {
+		c.w.WriteString(ack.format(0))
+		return
+	}
+	c.w.WriteString("OK\n")
+}
+
+// executeList executes the commands in the command list.
+func (s *Server) executeList(c *client) {
+	list := c.list
+	c.inList = false
+	c.list = nil
+	for i, line := range list {
+		if ack := s.execute(c, line); ack != nil {
+			c.w.WriteString(ack.format(i))
+			return
+		}
+		if c.listOK {
+			c.w.WriteString("list_OK\n")
+		}
+	}
+	c.w.WriteString("OK\n")
+}
+
+// execute runs the command and writes its response. An ackError is returned
+// if the command failed.
+func (s *Server) execute(c *client, line string) *ackError {
+	c.out.Reset()
+	args, err := parseArgs(line)
+	if err != nil {
+		return newAckError(ackErrorArg, "", err.Error())
+	}
+	if len(args) == 0 {
+		return newAckError(ackErrorUnknown, "", "No command given")
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return newAckError(ackErrorUnknown, "", fmt.Sprintf("unknown command \"%s\"", args[0]))
+	}
+	if err := cmd(s, c, args[1:]); err != nil {
+		ack, ok := err.(*ackError)
+		if !ok {
+			ack = newAckError(ackErrorSystem, "", err.Error())
+		}
+		ack.command = args[0]
+		return ack
+	}
+	c.w.Write(c.out.Bytes())
+	return nil
+}
+
+// startIdle makes the client wait for changes in the subsystems. If no
+// subsystems are given, the client waits for all.
+func (c *client) startIdle(args []string) {
+	c.idle = make(map[string]struct{})
+	if len(args) == 0 {
+		args = subsystems
+	}
+	for _, sub := range args {
+		c.idle[strings.ToLower(sub)] = struct{}{}
+	}
+	c.sendIdle(false)
+}
+
+// sendIdle reports the changed subsystems the client is waiting for and ends
+// the idle. If force is false, nothing is sent if none of the subsystems
+// has changed.
+func (c *client) sendIdle(force bool) {
+	changed := make([]string, 0)
+	for sub := range c.idle {
+		if _, ok := c.changed[sub]; ok {
+			changed = append(changed, sub)
+			delete(c.changed, sub)
+		}
+	}
+	if len(changed) == 0 && !force {
+		return
+	}
+	sort.Strings(changed)
+	for _, sub := range changed {
+		fmt.Fprintf(c.w, "changed: %s\n", sub)
+	}
+	c.w.WriteString("OK\n")
+	c.idle = nil
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package mpd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	// Set to 0 since it's not needed for testing.
+	jamsonic.BufferingWait = time.Duration(0)
+}
+
+var testArtists = []*jamsonic.Artist{
+	&jamsonic.Artist{
+		Name: "AC/DC",
+		ID:   "ar1",
+		Albums: []*jamsonic.Album{
+			&jamsonic.Album{Name: "Back in Black", Artist: "AC/DC", Year: 1980, ID: "al1",
+				Tracks: []*jamsonic.Track{
+					&jamsonic.Track{ID: "t1", Title: "Hells Bells", TrackNumber: 1, DurationMillis: "312000"},
+					&jamsonic.Track{ID: "t2", Title: "Shoot to Thrill", TrackNumber: 2, DurationMillis: "317000"},
+				},
+			},
+		},
+	},
+	&jamsonic.Artist{
+		Name: "Beatles",
+		ID:   "ar2",
+		Albums: []*jamsonic.Album{
+			&jamsonic.Album{Name: "Help!", Artist: "Beatles", Year: 1965, ID: "al2",
+				Tracks: []*jamsonic.Track{
+					&jamsonic.Track{ID: "t3", Title: "Help!", TrackNumber: 1, DurationMillis: "138000"},
+				},
+			},
+		},
+	},
+}
+
+func TestServer(t *testing.T) {
+	assert := assert.New(t)
+	player, handler := getPlayer()
+	defer player.Close()
+	s := New(player, &mockStore{artists: testArtists}, jamsonic.NewLogger(ioutil.Discard))
+	c := connect(t, s)
+	defer c.conn.Close()
+
+	t.Run("unknown_command", func(t *testing.T) {
+		assert.Equal(`ACK [5@0] {} unknown command "foo"`+"\n", c.send(t, "foo"))
+	})
+
+	t.Run("lsinfo_root", func(t *testing.T) {
+		assert.Equal("directory: AC%2FDC\ndirectory: Beatles\nOK\n", c.send(t, "lsinfo"))
+	})
+
+	t.Run("lsinfo_artist", func(t *testing.T) {
+		assert.Equal("directory: AC%2FDC/Back in Black\nOK\n", c.send(t, `lsinfo "AC%2FDC"`))
+	})
+
+	t.Run("lsinfo_missing", func(t *testing.T) {
+		assert.Equal("ACK [50@0] {lsinfo} Not found\n", c.send(t, `lsinfo "Missing"`))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		assert.Equal("Album: Back in Black\nAlbum: Help!\nOK\n", c.send(t, "list album"))
+		assert.Equal("Album: Help!\nOK\n", c.send(t, "list album Beatles"))
+		assert.Equal("Date: 1965\nAlbum: Help!\nDate: 1980\nAlbum: Back in Black\nOK\n",
+			c.send(t, "list album group date"))
+	})
+
+	t.Run("find", func(t *testing.T) {
+		expected := "file: Beatles/Help!/t3\nArtist: Beatles\nAlbumArtist: Beatles\nAlbum: Help!\n" +
+			"Title: Help!\nTrack: 1\nDate: 1965\nTime: 138\nduration: 138.000\nOK\n"
+		assert.Equal(expected, c.send(t, `find "(Artist == 'Beatles')"`))
+		assert.Equal("OK\n", c.send(t, "find artist beatles"))
+	})
+
+	t.Run("search", func(t *testing.T) {
+		resp := c.send(t, "search title bell")
+		assert.Contains(resp, "file: AC%2FDC/Back in Black/t1\n")
+		assert.Equal(1, strings.Count(resp, "file: "))
+	})
+
+	t.Run("add_and_playlistinfo", func(t *testing.T) {
+		assert.Equal("OK\n", c.send(t, `add "AC%2FDC"`))
+		assert.Equal("Id: 3\nOK\n", c.send(t, `addid "Beatles/Help!/t3"`))
+		resp := c.send(t, "playlistinfo 2")
+		assert.Contains(resp, "Title: Help!\n")
+		assert.Contains(resp, "Pos: 2\nId: 3\n")
+		assert.Equal("ACK [2@0] {playlistinfo} Bad song index\n", c.send(t, "playlistinfo 5"))
+	})
+
+	t.Run("play", func(t *testing.T) {
+		assert.Equal("OK\n", c.send(t, "play 1"))
+		waitForState(player, jamsonic.Playing)
+		assert.Equal("t2", player.CurrentTrack().ID)
+		resp := c.send(t, "status")
+		assert.Contains(resp, "state: play\n")
+		assert.Contains(resp, "playlistlength: 2\n")
+		assert.Contains(resp, "songid: 1\n")
+		assert.Contains(c.send(t, "currentsong"), "Title: Shoot to Thrill\n")
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		assert.Equal("OK\n", c.send(t, "seekcur 30"))
+		for i := 0; i < 100 && handler.getOffset() == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(30*time.Second, handler.getOffset())
+	})
+
+	t.Run("volume", func(t *testing.T) {
+		assert.Equal("OK\n", c.send(t, "setvol 40"))
+		assert.Equal("volume: 40\nOK\n", c.send(t, "getvol"))
+		assert.Equal("ACK [2@0] {setvol} Invalid volume value: 200\n", c.send(t, "setvol 200"))
+	})
+
+	t.Run("command_list", func(t *testing.T) {
+		c.write(t, "command_list_ok_begin")
+		c.write(t, "ping")
+		c.write(t, "getvol")
+		assert.Equal("list_OK\nvolume: 40\nlist_OK\nOK\n", c.send(t, "command_list_end"))
+
+		c.write(t, "command_list_begin")
+		c.write(t, "ping")
+		c.write(t, "foo")
+		assert.Equal(`ACK [5@1] {} unknown command "foo"`+"\n", c.send(t, "command_list_end"))
+	})
+
+	t.Run("idle", func(t *testing.T) {
+		// The volume change from the earlier command is reported right away.
+		assert.Equal("changed: mixer\nOK\n", c.send(t, "idle mixer"))
+
+		c.write(t, "idle mixer")
+		player.SetVolume(50)
+		assert.Equal("changed: mixer\nOK\n", c.read(t))
+
+		c.write(t, "idle stored_playlist")
+		assert.Equal("OK\n", c.send(t, "noidle"))
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		assert.Equal("OK\n", c.send(t, "clear"))
+		waitForState(player, jamsonic.Stopped)
+		assert.Contains(c.send(t, "status"), "playlistlength: 0\nstate: stop\n")
+	})
+
+	t.Run("close", func(t *testing.T) {
+		assert.NoError(s.Close())
+		_, err := c.r.ReadString('\n')
+		assert.Equal(io.EOF, err)
+	})
+}
+
+// waitForState waits up to a second for the player to change to the state.
+func waitForState(p *jamsonic.Player, s jamsonic.State) {
+	for i := 0; i < 100 && p.GetCurrentState() != s; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type testClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func connect(t *testing.T, s *Server) *testClient {
+	clientConn, serverConn := net.Pipe()
+	go s.serveConn(serverConn)
+	c := &testClient{conn: clientConn, r: bufio.NewReader(clientConn)}
+	line, err := c.r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, greeting, line)
+	return c
+}
+
+func (c *testClient) write(t *testing.T, line string) {
+	_, err := c.conn.Write([]byte(line + "\n"))
+	require.NoError(t, err)
+}
+
+// read reads a response up to and including the OK or ACK line.
+func (c *testClient) read(t *testing.T) string {
+	var resp strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		require.NoError(t, err)
+		resp.WriteString(line)
+		if line == "OK\n" || strings.HasPrefix(line, "ACK ") {
+			return resp.String()
+		}
+	}
+}
+
+func (c *testClient) send(t *testing.T, line string) string {
+	c.write(t, line)
+	return c.read(t)
+}
+
+func getPlayer() (*jamsonic.Player, *mockHandler) {
+	handler := &mockHandler{errChan: make(chan error)}
+	provider := &mockProvider{}
+	player := jamsonic.NewPlayer(jamsonic.NewLogger(ioutil.Discard), provider, handler, nil, 0)
+	go func() {
+		for range player.Error {
+		}
+	}()
+	return player, handler
+}
+
+type mockHandler struct {
+	errChan  chan error
+	offset   time.Duration
+	offsetMu sync.Mutex
+}
+
+func (m *mockHandler) Finished() <-chan struct{} { return nil }
+func (m *mockHandler) Play(io.Reader) error      { return nil }
+func (m *mockHandler) Stop()                     {}
+func (m *mockHandler) Pause()                    {}
+func (m *mockHandler) Continue()                 {}
+func (m *mockHandler) Errors() <-chan error      { return m.errChan }
+func (m *mockHandler) SetVolume(percent int)     {}
+
+func (m *mockHandler) PlayFrom(r io.Reader, offset time.Duration) error {
+	m.offsetMu.Lock()
+	defer m.offsetMu.Unlock()
+	m.offset = offset
+	return nil
+}
+
+func (m *mockHandler) getOffset() time.Duration {
+	m.offsetMu.Lock()
+	defer m.offsetMu.Unlock()
+	return m.offset
+}
+
+type mockProvider struct{}
+
+func (m *mockProvider) ListTracks(ctx context.Context) ([]*jamsonic.Track, error)    { return nil, nil }
+func (m *mockProvider) FetchLibrary(ctx context.Context) ([]*jamsonic.Artist, error) { return nil, nil }
+func (m *mockProvider) GetTrackInfo(context.Context, string) (*jamsonic.Track, error) {
+	return nil, nil
+}
+func (m *mockProvider) ListPlaylists(ctx context.Context) ([]*jamsonic.Playlist, error) {
+	return nil, nil
+}
+func (m *mockProvider) GetProvider() jamsonic.MusicProvider { return jamsonic.SubSonic }
+func (m *mockProvider) ListPlaylistEntries(ctx context.Context) ([]*jamsonic.PlaylistEntry, error) {
+	return nil, nil
+}
+
+func (m *mockProvider) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(songID)), nil
+}
+
+type mockStore struct {
+	artists []*jamsonic.Artist
+}
+
+func (m *mockStore) AddTracks([]*jamsonic.Track) error { return nil }
+func (m *mockStore) AddPlaylists(context.Context, jamsonic.Provider, []*jamsonic.Playlist, []*jamsonic.PlaylistEntry) error {
+	return nil
+}
+func (m *mockStore) Artists() ([]*jamsonic.Artist, error) { return m.artists, nil }
+func (m *mockStore) SaveArtists(a []*jamsonic.Artist) error {
+	m.artists = a
+	return nil
+}
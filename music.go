@@ -18,18 +18,214 @@
 
 package jamsonic
 
-func RefreshLibrary(db MusicStore, provider Provider) error {
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// LibraryChanges lists the names of the artists and albums added, changed
+// and removed by a sync.
+type LibraryChanges struct {
+	AddedArtists   []string
+	ChangedArtists []string
+	RemovedArtists []string
+	AddedAlbums    []string
+	ChangedAlbums  []string
+	RemovedAlbums  []string
+}
+
+// Empty returns true if nothing was changed.
+func (c *LibraryChanges) Empty() bool {
+	return len(c.AddedArtists)+len(c.ChangedArtists)+len(c.RemovedArtists)+
+		len(c.AddedAlbums)+len(c.ChangedAlbums)+len(c.RemovedAlbums) == 0
+}
+
+func (c *LibraryChanges) String() string {
+	return fmt.Sprintf("%d artists added, %d changed and %d removed; %d albums added, %d changed and %d removed",
+		len(c.AddedArtists), len(c.ChangedArtists), len(c.RemovedArtists),
+		len(c.AddedAlbums), len(c.ChangedAlbums), len(c.RemovedAlbums))
+}
+
+// LibraryUpdate is the changes to the library fetched from a provider.
+type LibraryUpdate struct {
+	// Artists are the new and changed artists, with all their albums.
+	Artists []*Artist
+	// Removed are the IDs of the removed artists.
+	Removed []string
+	// Modified is the server's time of the last change to the library. It's
+	// passed to the next call to FetchLibraryChanges.
+	Modified time.Time
+}
+
+// IncrementalLibraryProvider is implemented by providers that can fetch only
+// the changes to the library.
+type IncrementalLibraryProvider interface {
+	// FetchLibraryChanges returns the changes to the cached artists since
+	// the time of the last update. Unchanged albums are not downloaded
+	// again. A zero time checks the whole library.
+	FetchLibraryChanges(ctx context.Context, cached []*Artist, since time.Time) (*LibraryUpdate, error)
+}
+
+// SyncProgress is the progress of a library sync.
+type SyncProgress struct {
+	// ArtistsDone is the number of artists downloaded out of ArtistsTotal.
+	// Both are zero if the sync is done by album.
+	ArtistsDone  int
+	ArtistsTotal int
+	// AlbumsDone is the number of albums downloaded out of AlbumsTotal.
+	AlbumsDone  int
+	AlbumsTotal int
+	// Current is the name of the artist or album being downloaded.
+	Current string
+	// Failures are the artists and albums that failed to download so far.
+	Failures []*SyncFailure
+}
+
+// SyncFailure is an artist or album that failed to download.
+type SyncFailure struct {
+	// Name of the artist or album.
+	Name string
+	// Err is why it failed.
+	Err error
+}
+
+func (f *SyncFailure) String() string {
+	return f.Name + ": " + f.Err.Error()
+}
+
+// SyncProgressReporter is implemented by providers that report the progress
+// of FetchLibrary and FetchLibraryChanges.
+type SyncProgressReporter interface {
+	// SetSyncProgress sets the function called each time the sync makes
+	// progress. A nil function turns off the reports.
+	SetSyncProgress(fn func(SyncProgress))
+}
+
+// SyncLibrary updates the library, playlists and favorites in the store. If
+// both the provider and the store support it, only the changed artists are
+// fetched and written. Otherwise the whole library is replaced. The changes
+// to the library are returned.
+func SyncLibrary(ctx context.Context, db MusicStore, provider Provider) (*LibraryChanges, error) {
+	if provider.GetProvider() == GooglePlayMusic {
+		return &LibraryChanges{}, RefreshLibrary(ctx, db, provider)
+	}
+	// A missing library is synced from scratch.
+	cached, _ := db.Artists()
+	ip, incremental := provider.(IncrementalLibraryProvider)
+	ls, ok := db.(LibrarySyncStore)
+	var changes *LibraryChanges
+	if incremental && ok {
+		since, err := ls.LibrarySyncTime()
+		if err != nil {
+			return nil, err
+		}
+		update, err := ip.FetchLibraryChanges(ctx, cached, since)
+		if err != nil {
+			return nil, err
+		}
+		changes = diffLibrary(cached, update.Artists, update.Removed)
+		if err = ls.UpdateArtists(update.Artists, update.Removed); err != nil {
+			return nil, err
+		}
+		if err = ls.SaveLibrarySyncTime(update.Modified); err != nil {
+			return nil, err
+		}
+	} else {
+		artists, err := provider.FetchLibrary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		keep := make(map[string]bool, len(artists))
+		for _, a := range artists {
+			keep[a.ID] = true
+		}
+		var removed []string
+		for _, a := range cached {
+			if !keep[a.ID] {
+				removed = append(removed, a.ID)
+			}
+		}
+		changes = diffLibrary(cached, artists, removed)
+		if err = db.SaveArtists(artists); err != nil {
+			return nil, err
+		}
+	}
+	return changes, refreshLists(ctx, db, provider)
+}
+
+// diffLibrary returns the changes made by saving the updated artists and
+// removing the artists with the IDs.
+func diffLibrary(cached, updated []*Artist, removed []string) *LibraryChanges {
+	old := make(map[string]*Artist, len(cached))
+	for _, a := range cached {
+		old[a.ID] = a
+	}
+	changes := &LibraryChanges{}
+	for _, a := range updated {
+		prev, ok := old[a.ID]
+		if !ok {
+			changes.AddedArtists = append(changes.AddedArtists, a.Name)
+			for _, album := range a.Albums {
+				changes.AddedAlbums = append(changes.AddedAlbums, album.Name)
+			}
+			continue
+		}
+		changed := prev.Name != a.Name || prev.Starred != a.Starred ||
+			prev.Rating != a.Rating || prev.CoverArt != a.CoverArt
+		oldAlbums := make(map[string]*Album, len(prev.Albums))
+		for _, album := range prev.Albums {
+			oldAlbums[album.ID] = album
+		}
+		for _, album := range a.Albums {
+			prevAlbum, ok := oldAlbums[album.ID]
+			switch {
+			case !ok:
+				changes.AddedAlbums = append(changes.AddedAlbums, album.Name)
+				changed = true
+			case !reflect.DeepEqual(prevAlbum, album):
+				changes.ChangedAlbums = append(changes.ChangedAlbums, album.Name)
+				changed = true
+			}
+			delete(oldAlbums, album.ID)
+		}
+		// Keep the order of the cached albums.
+		for _, album := range prev.Albums {
+			if _, ok := oldAlbums[album.ID]; ok {
+				changes.RemovedAlbums = append(changes.RemovedAlbums, album.Name)
+				changed = true
+			}
+		}
+		if changed {
+			changes.ChangedArtists = append(changes.ChangedArtists, a.Name)
+		}
+	}
+	for _, id := range removed {
+		prev, ok := old[id]
+		if !ok {
+			continue
+		}
+		changes.RemovedArtists = append(changes.RemovedArtists, prev.Name)
+		for _, album := range prev.Albums {
+			changes.RemovedAlbums = append(changes.RemovedAlbums, album.Name)
+		}
+	}
+	return changes
+}
+
+func RefreshLibrary(ctx context.Context, db MusicStore, provider Provider) error {
 	var err error
 	if provider.GetProvider() == GooglePlayMusic {
-		tracks, err := provider.ListTracks()
+		tracks, err := provider.ListTracks(ctx)
 		if err != nil {
 			return err
 		}
-		playlists, err := provider.ListPlaylists()
+		playlists, err := provider.ListPlaylists(ctx)
 		if err != nil {
 			return err
 		}
-		entries, err := provider.ListPlaylistEntries()
+		entries, err := provider.ListPlaylistEntries(ctx)
 		if err != nil {
 			return err
 		}
@@ -37,16 +233,35 @@ func RefreshLibrary(db MusicStore, provider Provider) error {
 		if err != nil {
 			return err
 		}
-		err = db.AddPlaylists(provider, playlists, entries)
+		err = db.AddPlaylists(ctx, provider, playlists, entries)
 		if err != nil {
 			return err
 		}
 	} else {
-		albums, err := provider.FetchLibrary()
+		albums, err := provider.FetchLibrary(ctx)
 		if err != nil {
 			return err
 		}
-		err = db.SaveArtists(albums)
+		if err = db.SaveArtists(albums); err != nil {
+			return err
+		}
+		return refreshLists(ctx, db, provider)
 	}
 	return err
 }
+
+// refreshLists refreshes the playlists and favorites if the store can cache
+// them.
+func refreshLists(ctx context.Context, db MusicStore, provider Provider) error {
+	if ps, ok := db.(PlaylistStore); ok {
+		if err := RefreshPlaylists(ctx, ps, provider); err != nil {
+			return err
+		}
+	}
+	if fs, ok := db.(FavoritesStore); ok {
+		if fm, ok := provider.(FavoritesManager); ok {
+			return RefreshFavorites(ctx, fs, fm)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLibraryStore struct {
+	artists []*Artist
+	synced  time.Time
+	saved   bool
+}
+
+func (m *mockLibraryStore) AddTracks([]*Track) error { return nil }
+func (m *mockLibraryStore) AddPlaylists(context.Context, Provider, []*Playlist, []*PlaylistEntry) error {
+	return nil
+}
+func (m *mockLibraryStore) Artists() ([]*Artist, error) { return m.artists, nil }
+
+func (m *mockLibraryStore) SaveArtists(artists []*Artist) error {
+	m.artists, m.saved = artists, true
+	return nil
+}
+
+func (m *mockLibraryStore) UpdateArtists(artists []*Artist, removed []string) error {
+	updated := make(map[string]*Artist)
+	for _, a := range artists {
+		updated[a.ID] = a
+	}
+	for _, id := range removed {
+		updated[id] = nil
+	}
+	var result []*Artist
+	for _, a := range m.artists {
+		if u, ok := updated[a.ID]; ok {
+			delete(updated, a.ID)
+			a = u
+		}
+		if a != nil {
+			result = append(result, a)
+		}
+	}
+	for _, a := range artists {
+		if updated[a.ID] != nil {
+			result = append(result, a)
+		}
+	}
+	m.artists = result
+	return nil
+}
+
+func (m *mockLibraryStore) LibrarySyncTime() (time.Time, error) { return m.synced, nil }
+
+func (m *mockLibraryStore) SaveLibrarySyncTime(t time.Time) error {
+	m.synced = t
+	return nil
+}
+
+type mockIncrementalProvider struct {
+	mockProvider
+	since  time.Time
+	update *LibraryUpdate
+}
+
+func (m *mockIncrementalProvider) FetchLibraryChanges(ctx context.Context, cached []*Artist, since time.Time) (*LibraryUpdate, error) {
+	m.since = since
+	return m.update, nil
+}
+
+func TestSyncLibrary(t *testing.T) {
+	assert := assert.New(t)
+	subsonic := func() MusicProvider { return SubSonic }
+	album := func(id string, tracks int) *Album {
+		a := &Album{ID: id, Name: id}
+		for i := 0; i < tracks; i++ {
+			a.Tracks = append(a.Tracks, &Track{Album: id})
+		}
+		return a
+	}
+	cached := func() []*Artist {
+		return []*Artist{
+			{ID: "1", Name: "A1", Albums: []*Album{album("AA1", 2), album("AA2", 1)}},
+			{ID: "2", Name: "A2", Albums: []*Album{album("AB1", 1)}},
+		}
+	}
+
+	t.Run("incremental", func(t *testing.T) {
+		db := &mockLibraryStore{artists: cached(), synced: time.Unix(100, 0)}
+		provider := &mockIncrementalProvider{
+			mockProvider: mockProvider{doGetProvider: subsonic},
+			update: &LibraryUpdate{
+				Artists: []*Artist{
+					{ID: "1", Name: "A1", Albums: []*Album{album("AA1", 3), album("AA3", 1)}},
+					{ID: "3", Name: "A3", Albums: []*Album{album("AC1", 1)}},
+				},
+				Removed:  []string{"2"},
+				Modified: time.Unix(200, 0),
+			},
+		}
+		changes, err := SyncLibrary(context.Background(), db, provider)
+		require.NoError(t, err)
+		assert.Equal(time.Unix(100, 0), provider.since, "Should pass the time of the last sync")
+		assert.Equal(time.Unix(200, 0), db.synced, "Should save the server's time")
+		assert.False(db.saved, "Should not rewrite the library")
+		assert.Len(db.artists, 2)
+		assert.Equal([]string{"A3"}, changes.AddedArtists)
+		assert.Equal([]string{"A1"}, changes.ChangedArtists)
+		assert.Equal([]string{"A2"}, changes.RemovedArtists)
+		assert.Equal([]string{"AA3", "AC1"}, changes.AddedAlbums)
+		assert.Equal([]string{"AA1"}, changes.ChangedAlbums)
+		assert.Equal([]string{"AA2", "AB1"}, changes.RemovedAlbums)
+		assert.Equal("1 artists added, 1 changed and 1 removed; 2 albums added, 1 changed and 2 removed", changes.String())
+	})
+	t.Run("no_changes", func(t *testing.T) {
+		db := &mockLibraryStore{artists: cached()}
+		provider := &mockIncrementalProvider{
+			mockProvider: mockProvider{doGetProvider: subsonic},
+			update:       &LibraryUpdate{},
+		}
+		changes, err := SyncLibrary(context.Background(), db, provider)
+		require.NoError(t, err)
+		assert.True(changes.Empty())
+		assert.Len(db.artists, 2)
+	})
+	t.Run("full", func(t *testing.T) {
+		db := &mockLibraryStore{artists: cached()}
+		provider := &mockProvider{
+			doGetProvider: subsonic,
+			doFetchLibrary: func() ([]*Artist, error) {
+				return []*Artist{{ID: "1", Name: "A1", Albums: []*Album{album("AA1", 2), album("AA2", 1)}}}, nil
+			},
+		}
+		changes, err := SyncLibrary(context.Background(), db, provider)
+		require.NoError(t, err)
+		assert.True(db.saved, "Should replace the library")
+		assert.Empty(changes.ChangedArtists, "Should not report unchanged artists")
+		assert.Equal([]string{"A2"}, changes.RemovedArtists)
+		assert.Equal([]string{"AB1"}, changes.RemovedAlbums)
+	})
+}
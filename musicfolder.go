@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"time"
+)
+
+// MusicFolder is a top folder of the server's library, like Music or
+// Audiobooks.
+type MusicFolder struct {
+	// ID of the folder.
+	ID string
+	// Name of the folder.
+	Name string
+}
+
+// MusicFolderProvider is implemented by providers that can limit the library
+// to one of the server's music folders.
+type MusicFolderProvider interface {
+	// MusicFolders returns the server's music folders.
+	MusicFolders(ctx context.Context) ([]*MusicFolder, error)
+	// SetMusicFolder limits the library, album lists, searches and random
+	// tracks to the folder. An empty ID is all folders.
+	SetMusicFolder(id string)
+}
+
+// MusicFolderStore is implemented by stores that can save the selected
+// music folder of the library.
+type MusicFolderStore interface {
+	// MusicFolder returns the ID of the selected folder, or an empty string
+	// if all folders are used.
+	MusicFolder() (string, error)
+	// SaveMusicFolder saves the ID of the selected folder.
+	SaveMusicFolder(id string) error
+}
+
+// SelectMusicFolder saves the folder and limits the provider to it. If the
+// store keeps the time of the last sync, it's reset so the next sync checks
+// the whole library.
+func SelectMusicFolder(db MusicFolderStore, provider MusicFolderProvider, id string) error {
+	if err := db.SaveMusicFolder(id); err != nil {
+		return err
+	}
+	provider.SetMusicFolder(id)
+	if ls, ok := db.(LibrarySyncStore); ok {
+		return ls.SaveLibrarySyncTime(time.Time{})
+	}
+	return nil
+}
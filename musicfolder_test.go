@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockMusicFolderStore struct {
+	mockLibraryStore
+	folder string
+}
+
+func (m *mockMusicFolderStore) MusicFolder() (string, error) { return m.folder, nil }
+
+func (m *mockMusicFolderStore) SaveMusicFolder(id string) error {
+	m.folder = id
+	return nil
+}
+
+type mockMusicFolderProvider struct {
+	folder string
+}
+
+func (m *mockMusicFolderProvider) MusicFolders(ctx context.Context) ([]*MusicFolder, error) {
+	return nil, nil
+}
+
+func (m *mockMusicFolderProvider) SetMusicFolder(id string) { m.folder = id }
+
+func TestSelectMusicFolder(t *testing.T) {
+	assert := assert.New(t)
+	db := &mockMusicFolderStore{mockLibraryStore: mockLibraryStore{synced: time.Unix(100, 0)}}
+	provider := &mockMusicFolderProvider{}
+	assert.NoError(SelectMusicFolder(db, provider, "2"))
+	assert.Equal("2", db.folder)
+	assert.Equal("2", provider.folder)
+	assert.True(db.synced.IsZero(), "Should sync the whole library next time")
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
 )
@@ -36,6 +38,8 @@ type StreamHandler struct {
 	errChan      chan error
 	pauseChan    chan struct{}
 	continueChan chan struct{}
+	// volume is the output volume in percent. It's accessed atomically.
+	volume int32
 }
 
 // New returns a new stream handler.
@@ -48,6 +52,7 @@ func New(logger *jamsonic.Logger) *StreamHandler {
 		errChan:      make(chan error),
 		pauseChan:    make(chan struct{}),
 		continueChan: make(chan struct{}),
+		volume:       jamsonic.MaxVolume,
 	}
 }
 
@@ -67,11 +72,22 @@ func (p *StreamHandler) Errors() <-chan error {
 
 // Play starts processing the stream.
 func (p *StreamHandler) Play(iostream io.Reader) error {
+	return p.PlayFrom(iostream, time.Duration(0))
+}
+
+// PlayFrom starts processing the stream from the offset. The decoded audio
+// before the offset is discarded.
+func (p *StreamHandler) PlayFrom(iostream io.Reader, offset time.Duration) error {
 	s, err := newDecoder(&stream{reader: iostream})
 	if err != nil {
 		return err
 	}
 	p.logger.DebugLog(fmt.Sprintf("Sample Rate: %d", s.SampleRate()))
+	var r io.Reader = s
+	if offset > 0 {
+		p.logger.DebugLog("Skipping to " + offset.String())
+		r = &skipReader{reader: s, skip: pcmOffset(offset, s.SampleRate())}
+	}
 	// Nothing playing
 	if p.writer == nil {
 		p.logger.DebugLog("Nothing playing, starting the main loop.")
@@ -82,15 +98,20 @@ func (p *StreamHandler) Play(iostream io.Reader) error {
 		p.writerMu.Lock()
 		p.writer = writer
 		p.writerMu.Unlock()
-		go mainLoop(p, s)
+		go mainLoop(p, r)
 	} else {
 		p.logger.DebugLog("Switching track.")
 		// Already playing a track, telling to switch stream.
-		p.newTrackChan <- &switchStream{stream: s, sampleRate: s.SampleRate()}
+		p.newTrackChan <- &switchStream{stream: r, sampleRate: s.SampleRate()}
 	}
 	return nil
 }
 
+// SetVolume sets the output volume in percent.
+func (p *StreamHandler) SetVolume(percent int) {
+	atomic.StoreInt32(&p.volume, int32(percent))
+}
+
 func mainLoop(p *StreamHandler, stream io.Reader) {
 	defer p.closeOutput()
 	p.reader = stream
@@ -137,6 +158,9 @@ func mainLoop(p *StreamHandler, stream io.Reader) {
 			} else if err != nil && err != io.ErrUnexpectedEOF {
 				p.errChan <- err
 			}
+			if v := atomic.LoadInt32(&p.volume); v < jamsonic.MaxVolume {
+				scaleVolume(buf, int(v))
+			}
 			_, err = p.writer.Write(buf)
 			if err != nil {
 				p.errChan <- err
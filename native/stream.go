@@ -19,6 +19,7 @@ package native
 
 import (
 	"io"
+	"io/ioutil"
 	"sync"
 	"time"
 
@@ -79,6 +80,32 @@ func (s *stream) retryWithBackoff(b []byte, wait time.Duration, attempt int) (in
 	return n, err
 }
 
+// bytesPerSample is the size of a decoded sample. The decoder always
+// outputs 16 bit samples for two channels.
+const bytesPerSample = 4
+
+// pcmOffset returns the number of decoded bytes that corresponds to the offset.
+func pcmOffset(offset time.Duration, sampleRate int) int64 {
+	return int64(offset.Seconds()*float64(sampleRate)) * bytesPerSample
+}
+
+// skipReader discards the first skip bytes from the reader.
+type skipReader struct {
+	reader io.Reader
+	skip   int64
+}
+
+func (s *skipReader) Read(b []byte) (int, error) {
+	if s.skip > 0 {
+		n, err := io.CopyN(ioutil.Discard, s.reader, s.skip)
+		s.skip = s.skip - n
+		if err != nil {
+			return 0, err
+		}
+	}
+	return s.reader.Read(b)
+}
+
 type mp3Stream interface {
 	io.Reader
 	SampleRate() int
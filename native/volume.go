@@ -0,0 +1,34 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/binary"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// scaleVolume scales the 16 bit little endian samples in the buffer to
+// the volume given in percent.
+func scaleVolume(buf []byte, percent int) {
+	for i := 0; i+1 < len(buf); i += 2 {
+		sample := int32(int16(binary.LittleEndian.Uint16(buf[i:])))
+		sample = sample * int32(percent) / jamsonic.MaxVolume
+		binary.LittleEndian.PutUint16(buf[i:], uint16(int16(sample)))
+	}
+}
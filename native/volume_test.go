@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleVolume(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("half_volume", func(t *testing.T) {
+		// 1000 and -1000 as little endian int16.
+		buf := []byte{0xe8, 0x03, 0x18, 0xfc}
+		scaleVolume(buf, 50)
+		assert.Equal([]byte{0xf4, 0x01, 0x0c, 0xfe}, buf, "Samples not scaled to 500 and -500")
+	})
+
+	t.Run("mute", func(t *testing.T) {
+		buf := []byte{0xe8, 0x03, 0x18, 0xfc}
+		scaleVolume(buf, 0)
+		assert.Equal([]byte{0x0, 0x0, 0x0, 0x0}, buf, "Samples should be silent")
+	})
+}
+
+func TestSkipReader(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("skip_offset", func(t *testing.T) {
+		r := &skipReader{reader: strings.NewReader("skipped content"), skip: 8}
+		content, err := ioutil.ReadAll(r)
+		assert.NoError(err)
+		assert.Equal("content", string(content), "Wrong content after skip")
+	})
+
+	t.Run("skip_past_end", func(t *testing.T) {
+		r := &skipReader{reader: bytes.NewReader([]byte{0x1, 0x2}), skip: 8}
+		content, err := ioutil.ReadAll(r)
+		assert.NoError(err)
+		assert.Empty(content, "Should not return any content")
+	})
+
+	t.Run("pcm_offset", func(t *testing.T) {
+		assert.Equal(int64(2*44100*bytesPerSample), pcmOffset(2*time.Second, 44100), "Wrong offset")
+	})
+}
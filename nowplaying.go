@@ -0,0 +1,41 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"time"
+)
+
+// NowPlaying is a track another user on the server is playing.
+type NowPlaying struct {
+	// Track is the track being played.
+	Track *Track
+	// Username is the user playing the track.
+	Username string
+	// PlayerName is the name of the user's player, if it has one.
+	PlayerName string
+	// Started is how long ago the track was started, in whole minutes.
+	Started time.Duration
+}
+
+// NowPlayingProvider is implemented by providers that can tell what the
+// other users are playing.
+type NowPlayingProvider interface {
+	// NowPlaying returns the tracks the other users are playing.
+	NowPlaying(ctx context.Context) ([]*NowPlaying, error)
+}
@@ -19,6 +19,7 @@ package jamsonic
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"log"
@@ -42,8 +43,17 @@ var (
 	// ErrNoNextTrack is returned when the playing queue does not have a track to play
 	// but is asked to play one.
 	ErrNoNextTrack = errors.New("no track in playing queue")
+	// ErrSeekNotSupported is returned when neither the stream handler nor the
+	// provider can start a stream from an offset.
+	ErrSeekNotSupported = errors.New("stream handler does not support seeking")
+	// ErrVolumeNotSupported is returned when the stream handler can't change the
+	// output volume.
+	ErrVolumeNotSupported = errors.New("stream handler does not support volume control")
 )
 
+// MaxVolume is the highest volume level, in percent, the player accepts.
+const MaxVolume = 100
+
 // NewPlayer returns a new Player. The Provider should be a music provider.
 // The callback is a function that is called every interval by the Player as long as the state is
 // not stopped. If interval is set to 0, the callback will be called every 1000 ms.
@@ -64,9 +74,15 @@ func NewPlayer(l *Logger, p Provider, h StreamHandler, callback func(*CallbackDa
 		nextChan:         make(chan struct{}),
 		prevChan:         make(chan struct{}),
 		stopChan:         make(chan struct{}),
+		seekChan:         make(chan time.Duration),
+		playAtChan:       make(chan time.Duration),
+		clearChan:        make(chan struct{}),
+		queue:            &playqueue{array: make([]*Track, 0)},
 		played:           &playqueue{array: make([]*Track, 0)},
 		buffer:           newBufReadWriter(),
 		logger:           l,
+		volume:           MaxVolume,
+		subscribers:      make(map[chan *Event]struct{}),
 	}
 	// Since know the buffer doesn't have any current writes to it,
 	// set buffered to true so we don't allocate a new buffer.
@@ -91,6 +107,7 @@ type Player struct {
 	// Error returns errors from the handler and the provider.
 	Error            chan error
 	handler          StreamHandler
+	handlerMu        sync.RWMutex
 	provider         Provider
 	callback         func(*CallbackData)
 	callbackInterval int
@@ -107,10 +124,33 @@ type Player struct {
 	nextChan         chan struct{}
 	prevChan         chan struct{}
 	closeChan        chan struct{}
+	seekChan         chan time.Duration
+	playAtChan       chan time.Duration
+	clearChan        chan struct{}
 	// bufMu protects the buffer pointer from being manipulated by multiple go routines.
 	bufMu  sync.Mutex
 	buffer *bufReadWriter
 	logger *Logger
+	// Playback position of the current track. The position is calculated from when the
+	// track was started minus the time it has been paused.
+	posMu       sync.RWMutex
+	trackStart  time.Time
+	pausedAt    time.Time
+	pausedTotal time.Duration
+	// Output volume in percent.
+	volume   int
+	volumeMu sync.RWMutex
+	// Subscribers that receive player events.
+	subscribers map[chan *Event]struct{}
+	subMu       sync.Mutex
+	// live is the live stream being played, if any.
+	live   *liveStream
+	liveMu sync.Mutex
+	// streamTitle is the title announced by the live stream.
+	streamTitle string
+	// streamCancel cancels the download of the current track's stream.
+	streamCancel context.CancelFunc
+	streamMu     sync.Mutex
 }
 
 // Play starts or resumes playing the track first in the play queue.
@@ -130,19 +170,114 @@ func (p *Player) Pause() {
 
 // Next skips to the next track in the play queue.
 func (p *Player) Next() {
+	// The current track keeps playing if there's no next track.
+	if p.NextTrack() != nil {
+		p.cancelStream()
+	}
 	p.nextChan <- struct{}{}
 }
 
 // Previous will go back to previous played track.
 func (p *Player) Previous() {
+	if p.played.nextSong() != nil {
+		p.cancelStream()
+	}
 	p.prevChan <- struct{}{}
 }
 
 // Stop should be called to stop playing the track.
 func (p *Player) Stop() {
+	p.cancelStream()
 	p.stopChan <- struct{}{}
 }
 
+// Clear stops the player and removes all tracks from the play queue.
+func (p *Player) Clear() {
+	p.cancelStream()
+	p.clearChan <- struct{}{}
+}
+
+// Seek moves the playback position of the current track to the given offset.
+// ErrSeekNotSupported is returned if neither the stream handler nor the
+// provider can play a stream from an offset.
+func (p *Player) Seek(offset time.Duration) error {
+	if !p.canSkip() {
+		return ErrSeekNotSupported
+	}
+	if ct := p.CurrentTrack(); ct != nil && ct.Live {
+		return ErrSeekLiveStream
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	p.cancelStream()
+	p.seekChan <- offset
+	return nil
+}
+
+// PlayAt starts playing the track first in the play queue from the offset,
+// for example to resume it. If a track is playing, it's stopped. If the
+// track can't be played from an offset, it's played from the start.
+func (p *Player) PlayAt(offset time.Duration) {
+	if offset < 0 {
+		offset = 0
+	}
+	p.cancelStream()
+	p.playAtChan <- offset
+}
+
+// canSkip returns true if tracks can be played from an offset, either by
+// the handler or by asking the provider for a stream starting at it.
+func (p *Player) canSkip() bool {
+	switch p.streamHandler().(type) {
+	case OffsetStreamHandler, TrackStreamHandler:
+		return true
+	}
+	_, ok := p.provider.(StreamConfigurer)
+	return ok
+}
+
+// Position returns how long the current track has been played.
+func (p *Player) Position() time.Duration {
+	if p.GetCurrentState() == Stopped {
+		return time.Duration(0)
+	}
+	p.posMu.RLock()
+	defer p.posMu.RUnlock()
+	if !p.pausedAt.IsZero() {
+		return p.pausedAt.Sub(p.trackStart) - p.pausedTotal
+	}
+	return time.Since(p.trackStart) - p.pausedTotal
+}
+
+// SetVolume changes the output volume. The volume is given in percent and
+// is capped between 0 and MaxVolume. ErrVolumeNotSupported is returned if the
+// stream handler can't change the volume.
+func (p *Player) SetVolume(percent int) error {
+	h, ok := p.streamHandler().(VolumeStreamHandler)
+	if !ok {
+		return ErrVolumeNotSupported
+	}
+	if percent < 0 {
+		percent = 0
+	} else if percent > MaxVolume {
+		percent = MaxVolume
+	}
+	h.SetVolume(percent)
+	p.volumeMu.Lock()
+	p.volume = percent
+	p.volumeMu.Unlock()
+	p.notify(VolumeChanged)
+	return nil
+}
+
+// Volume returns the output volume in percent.
+func (p *Player) Volume() int {
+	p.volumeMu.RLock()
+	defer p.volumeMu.RUnlock()
+	return p.volume
+}
+
 // GetCurrentState returns the player's current internal state.
 func (p *Player) GetCurrentState() State {
 	p.stateMu.RLock()
@@ -152,14 +287,51 @@ func (p *Player) GetCurrentState() State {
 
 // CreatePlayQueue creates a new list with queued tracks.
 func (p *Player) CreatePlayQueue(tracks []*Track) {
+	p.queueMu.Lock()
 	p.queue = &playqueue{array: tracks}
+	p.queueMu.Unlock()
+	p.notify(QueueChanged)
+}
+
+// Enqueue adds the tracks to the end of the play queue.
+func (p *Player) Enqueue(tracks ...*Track) {
+	p.queueMu.Lock()
+	p.queue.appendSongs(tracks)
+	p.queueMu.Unlock()
+	p.notify(QueueChanged)
+}
+
+// Queue returns a copy of the tracks waiting in the play queue. The current
+// track is not included.
+func (p *Player) Queue() []*Track {
+	p.queueMu.RLock()
+	defer p.queueMu.RUnlock()
+	return p.queue.songs()
 }
 
 // NextTrack returns the next track in the play queue.
 func (p *Player) NextTrack() *Track {
+	p.queueMu.RLock()
+	defer p.queueMu.RUnlock()
 	return p.queue.nextSong()
 }
 
+// StreamTitle returns the title announced by the live stream being played,
+// usually the artist and title of the song. It's empty if the stream hasn't
+// announced a title.
+func (p *Player) StreamTitle() string {
+	p.liveMu.Lock()
+	defer p.liveMu.Unlock()
+	return p.streamTitle
+}
+
+func (p *Player) setStreamTitle(title string) {
+	p.liveMu.Lock()
+	p.streamTitle = title
+	p.liveMu.Unlock()
+	p.notify(StreamTitleChanged)
+}
+
 // CurrentTrack returns the current playing or paused track.
 func (p *Player) CurrentTrack() *Track {
 	p.currentTrackMu.RLock()
@@ -169,6 +341,7 @@ func (p *Player) CurrentTrack() *Track {
 
 // Close closes the player.
 func (p *Player) Close() {
+	p.cancelStream()
 	p.closeChan <- struct{}{}
 }
 
@@ -181,10 +354,65 @@ func (p *Player) UpdateProvider(c Provider) {
 	go p.playerLoop()
 }
 
+// streamHandler returns the handler playing the streams.
+func (p *Player) streamHandler() StreamHandler {
+	p.handlerMu.RLock()
+	defer p.handlerMu.RUnlock()
+	return p.handler
+}
+
+// UpdateHandler sets a new stream handler for the player, for example to
+// play on another output. The current track is stopped and, if it was
+// playing, continued with the new handler from the same position.
+func (p *Player) UpdateHandler(h StreamHandler) {
+	playing := p.GetCurrentState() == Playing
+	position := p.Position()
+	p.Stop()
+	p.Close()
+	p.handlerMu.Lock()
+	p.handler = h
+	p.handlerMu.Unlock()
+	if vh, ok := h.(VolumeStreamHandler); ok {
+		vh.SetVolume(p.Volume())
+	}
+	go p.playerLoop()
+	if playing {
+		p.PlayAt(position)
+	}
+}
+
 func (p *Player) updateCurrentTrack(t *Track) {
 	p.currentTrackMu.Lock()
-	defer p.currentTrackMu.Unlock()
 	p.currentTrack = t
+	p.currentTrackMu.Unlock()
+	p.notify(TrackChanged)
+}
+
+// resetPosition marks the current track as started at the given offset.
+func (p *Player) resetPosition(offset time.Duration) {
+	p.posMu.Lock()
+	defer p.posMu.Unlock()
+	p.trackStart = time.Now().Add(-offset)
+	p.pausedAt = time.Time{}
+	p.pausedTotal = time.Duration(0)
+}
+
+// pausePosition freezes the playback position.
+func (p *Player) pausePosition() {
+	p.posMu.Lock()
+	defer p.posMu.Unlock()
+	p.pausedAt = time.Now()
+}
+
+// resumePosition continues counting the playback position after a pause.
+func (p *Player) resumePosition() {
+	p.posMu.Lock()
+	defer p.posMu.Unlock()
+	if p.pausedAt.IsZero() {
+		return
+	}
+	p.pausedTotal = p.pausedTotal + time.Since(p.pausedAt)
+	p.pausedAt = time.Time{}
 }
 
 func handleErrors(errs <-chan error, close <-chan struct{}) {
@@ -201,44 +429,38 @@ func handleErrors(errs <-chan error, close <-chan struct{}) {
 
 func (p *Player) playerLoop() {
 	stopErrHandle := make(chan struct{})
-	go handleErrors(p.handler.Errors(), stopErrHandle)
-	finished := p.handler.Finished()
+	go handleErrors(p.streamHandler().Errors(), stopErrHandle)
+	finished := p.streamHandler().Finished()
 	ticker := time.NewTicker(time.Millisecond * time.Duration(p.callbackInterval))
-	// Timers
-	pausedDuration := time.Duration(0)
-	songDuration := time.Duration(0)
-	var pauseTimer time.Time
-	var songStart time.Time
 controllerLoop:
 	for {
 		select {
 		case <-p.playChan:
 			status := p.changeState(Playing)
 			if status == Paused {
-				p.handler.Continue()
-				pausedDuration = pausedDuration + time.Since(pauseTimer)
+				p.streamHandler().Continue()
+				p.resumePosition()
 				continue
 			}
 			if status == Playing {
-				p.handler.Stop()
+				p.streamHandler().Stop()
 			}
-			p.playNextInQueue(p.queue.popSong)
-			songStart = time.Now()
-			pausedDuration = time.Duration(0)
+			p.playNextInQueue(p.popQueued)
+			p.resetPosition(0)
 		case <-p.pauseChan:
-			p.handler.Pause()
+			p.streamHandler().Pause()
+			p.pausePosition()
 			p.changeState(Paused)
-			pauseTimer = time.Now()
 		case <-p.stopChan:
 			if p.GetCurrentState() == Stopped {
 				continue
 			}
 			ct := p.CurrentTrack()
 			if ct != nil {
-				p.queue.pushSong(ct)
+				p.pushQueued(ct)
 			}
 			p.stopPlaying()
-			pausedDuration = time.Duration(0)
+			p.resetPosition(0)
 		case <-p.nextChan:
 			state := p.GetCurrentState()
 			if state == Stopped {
@@ -250,16 +472,15 @@ controllerLoop:
 			ct := p.CurrentTrack()
 			if ct != nil {
 				p.played.pushSong(ct)
-				p.handler.Stop()
+				p.streamHandler().Stop()
 			}
-			err := p.playNextInQueue(p.queue.popSong)
+			err := p.playNextInQueue(p.popQueued)
 			if err == ErrNoNextTrack {
 				// If no next track, keep playing the current.
 				p.Error <- err
 				continue
 			}
-			songStart = time.Now()
-			pausedDuration = time.Duration(0)
+			p.resetPosition(0)
 		case <-p.prevChan:
 			state := p.GetCurrentState()
 			if state == Stopped {
@@ -268,11 +489,48 @@ controllerLoop:
 			if p.played.nextSong() == nil {
 				continue
 			}
-			p.queue.pushSong(p.CurrentTrack())
-			p.handler.Stop()
+			p.pushQueued(p.CurrentTrack())
+			p.streamHandler().Stop()
 			p.playNextInQueue(p.played.popSong)
-			songStart = time.Now()
-			pausedDuration = time.Duration(0)
+			p.resetPosition(0)
+		case offset := <-p.seekChan:
+			if p.GetCurrentState() == Stopped {
+				continue
+			}
+			ct := p.CurrentTrack()
+			p.streamHandler().Stop()
+			p.changeState(Playing)
+			p.playTrack(ct, offset)
+			p.resetPosition(offset)
+			p.notify(PositionChanged)
+		case offset := <-p.playAtChan:
+			p.queueMu.Lock()
+			ct := p.queue.popSong()
+			p.queueMu.Unlock()
+			if ct == nil {
+				continue
+			}
+			if p.changeState(Playing) != Stopped {
+				if current := p.CurrentTrack(); current != nil {
+					p.played.pushSong(current)
+				}
+				p.streamHandler().Stop()
+			}
+			if ct.Live || !p.canSkip() {
+				offset = 0
+			}
+			p.notify(QueueChanged)
+			p.playTrack(ct, offset)
+			p.resetPosition(offset)
+		case <-p.clearChan:
+			if p.GetCurrentState() != Stopped {
+				p.stopPlaying()
+				p.resetPosition(0)
+			}
+			p.queueMu.Lock()
+			p.queue = &playqueue{array: make([]*Track, 0)}
+			p.queueMu.Unlock()
+			p.notify(QueueChanged)
 		case <-finished:
 			ct := p.CurrentTrack()
 			if ct != nil {
@@ -282,9 +540,8 @@ controllerLoop:
 				p.stopPlaying()
 				continue
 			}
-			p.playNextInQueue(p.queue.popSong)
-			songStart = time.Now()
-			pausedDuration = time.Duration(0)
+			p.playNextInQueue(p.popQueued)
+			p.resetPosition(0)
 		case <-p.closeChan:
 			break controllerLoop
 		case <-ticker.C:
@@ -292,12 +549,9 @@ controllerLoop:
 				continue
 			}
 			if p.callback != nil {
-				if p.GetCurrentState() == Playing {
-					songDuration = time.Since(songStart) - pausedDuration
-				}
 				data := &CallbackData{
 					CurrentTrack: p.CurrentTrack(),
-					Duration:     songDuration,
+					Duration:     p.Position(),
 				}
 				p.callback(data)
 			}
@@ -306,6 +560,19 @@ controllerLoop:
 	stopErrHandle <- struct{}{}
 }
 
+// popQueued removes the first track from the play queue. queueMu must be
+// held, as it is by playNextInQueue.
+func (p *Player) popQueued() *Track {
+	return p.queue.popSong()
+}
+
+// pushQueued puts the track first in the play queue.
+func (p *Player) pushQueued(t *Track) {
+	p.queueMu.Lock()
+	p.queue.pushSong(t)
+	p.queueMu.Unlock()
+}
+
 func (p *Player) playNextInQueue(getTrack func() *Track) error {
 	p.queueMu.Lock()
 	ct := getTrack()
@@ -313,11 +580,32 @@ func (p *Player) playNextInQueue(getTrack func() *Track) error {
 	if ct == nil {
 		return ErrNoNextTrack
 	}
+	p.notify(QueueChanged)
+	return p.playTrack(ct, time.Duration(0))
+}
+
+// playTrack streams the track to the handler. If offset is larger than 0, the
+// handler is asked to start playing from the offset.
+func (p *Player) playTrack(ct *Track, offset time.Duration) error {
+	p.closeLiveStream()
 	p.updateCurrentTrack(ct)
+	if ct.Live {
+		return p.playLiveStream(ct)
+	}
+	if h, ok := p.streamHandler().(TrackStreamHandler); ok {
+		if err := h.PlayTrack(ct, offset); err != nil {
+			handleStreamError(p, err)
+		}
+		return nil
+	}
 
-	stream, err := p.provider.GetStream(ct.ID)
+	ctx := p.streamContext()
+	stream, skipped, err := p.openStream(ctx, ct, offset)
 	if err != nil {
-		handleStreamError(p, err)
+		// A cancelled request was replaced by the command that cancelled it.
+		if ctx.Err() == nil {
+			handleStreamError(p, err)
+		}
 		return nil
 	}
 	// Ensure we have control of this pointer.
@@ -343,6 +631,11 @@ func (p *Player) playNextInQueue(getTrack func() *Track) error {
 		go p.logger.DebugLog("Reading track into memory buffer.")
 		_, cpErr := io.Copy(buf, stream)
 		go p.logger.DebugLog("Track saved to memory buffer.")
+		if cpErr != nil && ctx.Err() != nil {
+			// The track was stopped or skipped while downloading.
+			stream.Close()
+			return
+		}
 		if cpErr != nil {
 			p.Error <- cpErr
 			return
@@ -356,15 +649,90 @@ func (p *Player) playNextInQueue(getTrack func() *Track) error {
 		}
 	}()
 	time.Sleep(BufferingWait)
-	err = p.handler.Play(buf)
+	if h, ok := p.streamHandler().(OffsetStreamHandler); ok && offset > 0 && !skipped {
+		err = h.PlayFrom(buf, offset)
+	} else {
+		err = p.streamHandler().Play(buf)
+	}
 	if err != nil {
 		handleStreamError(p, err)
 	}
 	return nil
 }
 
+// openStream returns the stream of the track. The memory buffer can only be
+// played from the start, so if the handler can't skip to the offset in it,
+// the provider is asked for a stream starting at the offset instead and
+// skipped is true.
+func (p *Player) openStream(ctx context.Context, ct *Track, offset time.Duration) (stream io.ReadCloser, skipped bool, err error) {
+	_, handlerSkips := p.streamHandler().(OffsetStreamHandler)
+	if sc, ok := p.provider.(StreamConfigurer); ok && offset > 0 && !handlerSkips {
+		stream, err = sc.GetStreamAt(ctx, ct.ID, offset)
+		return stream, true, err
+	}
+	stream, err = p.provider.GetStream(ctx, ct.ID)
+	return stream, false, err
+}
+
+// streamContext returns the context for downloading a new stream. The
+// download of the previous stream is cancelled.
+func (p *Player) streamContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.streamMu.Lock()
+	if p.streamCancel != nil {
+		p.streamCancel()
+	}
+	p.streamCancel = cancel
+	p.streamMu.Unlock()
+	return ctx
+}
+
+// cancelStream cancels the download of the current stream, if any. It's
+// called before the player loop is told to stop or skip the track, since
+// the loop may be waiting for the stream.
+func (p *Player) cancelStream() {
+	p.streamMu.Lock()
+	defer p.streamMu.Unlock()
+	if p.streamCancel != nil {
+		p.streamCancel()
+		p.streamCancel = nil
+	}
+}
+
+// playLiveStream plays a stream that never ends. Instead of reading the
+// whole stream into memory, a bounded buffer is used.
+func (p *Player) playLiveStream(ct *Track) error {
+	s := newLiveStream(ct.StreamURL, p.setStreamTitle, p.logger)
+	p.liveMu.Lock()
+	p.live = s
+	p.liveMu.Unlock()
+	time.Sleep(BufferingWait)
+	if err := p.streamHandler().Play(s); err != nil {
+		handleStreamError(p, err)
+	}
+	return nil
+}
+
+// closeLiveStream stops reading the live stream, if one is playing.
+func (p *Player) closeLiveStream() {
+	p.liveMu.Lock()
+	s := p.live
+	p.live = nil
+	hadTitle := p.streamTitle != ""
+	p.streamTitle = ""
+	p.liveMu.Unlock()
+	if s != nil {
+		s.Close()
+	}
+	if hadTitle {
+		p.notify(StreamTitleChanged)
+	}
+}
+
 func (p *Player) stopPlaying() {
-	p.handler.Stop()
+	p.cancelStream()
+	p.streamHandler().Stop()
+	p.closeLiveStream()
 	p.updateCurrentTrack(nil)
 	p.changeState(Stopped)
 }
@@ -372,9 +740,12 @@ func (p *Player) stopPlaying() {
 // changeState changes the state to the new but also returns the previous state.
 func (p *Player) changeState(s State) State {
 	p.stateMu.Lock()
-	defer p.stateMu.Unlock()
 	status := p.state
 	p.state = s
+	p.stateMu.Unlock()
+	if status != s {
+		p.notify(StateChanged)
+	}
 	return status
 }
 
@@ -383,9 +754,7 @@ func handleStreamError(p *Player, err error) {
 		p.Error <- err
 	}()
 	p.changeState(Stopped)
-	p.queueMu.Lock()
-	p.queue.pushSong(p.CurrentTrack())
-	p.queueMu.Unlock()
+	p.pushQueued(p.CurrentTrack())
 }
 
 type playqueue struct {
@@ -418,6 +787,25 @@ func (q *playqueue) popSong() *Track {
 	return track
 }
 
+// appendSongs adds the tracks to the end of the play queue.
+func (q *playqueue) appendSongs(tracks []*Track) {
+	q.arrayMu.Lock()
+	defer q.arrayMu.Unlock()
+	// Copy to a new array so the slice given to CreatePlayQueue is not modified.
+	tmp := make([]*Track, len(q.array), len(q.array)+len(tracks))
+	copy(tmp, q.array)
+	q.array = append(tmp, tracks...)
+}
+
+// songs returns a copy of the tracks in the play queue.
+func (q *playqueue) songs() []*Track {
+	q.arrayMu.RLock()
+	defer q.arrayMu.RUnlock()
+	tmp := make([]*Track, len(q.array))
+	copy(tmp, q.array)
+	return tmp
+}
+
 func (q *playqueue) pushSong(t *Track) {
 	q.arrayMu.Lock()
 	defer q.arrayMu.Unlock()
@@ -452,6 +840,30 @@ type StreamHandler interface {
 	Errors() <-chan error
 }
 
+// OffsetStreamHandler is a StreamHandler that can start playing a stream from
+// an offset. The Player uses this to seek in the current track.
+type OffsetStreamHandler interface {
+	StreamHandler
+	// PlayFrom works like Play but the audio before the offset is skipped.
+	PlayFrom(r io.Reader, offset time.Duration) error
+}
+
+// TrackStreamHandler is a StreamHandler that plays the tracks itself, for
+// example on the server's sound card, instead of being given the streams.
+// The Player doesn't download the tracks for it.
+type TrackStreamHandler interface {
+	StreamHandler
+	// PlayTrack starts playing the track from the offset.
+	PlayTrack(track *Track, offset time.Duration) error
+}
+
+// VolumeStreamHandler is a StreamHandler that can change the output volume.
+type VolumeStreamHandler interface {
+	StreamHandler
+	// SetVolume sets the output volume in percent.
+	SetVolume(percent int)
+}
+
 // bufReadWriter is a buffer that is "thread safe". Tracks are read in and stored in memory buffer.
 // The stream handler reads from this buffer as it plays the track.
 type bufReadWriter struct {
@@ -466,17 +878,33 @@ type bufReadWriter struct {
 	// This is used as an indicator to signal that the buffer can be reused for a new stream.
 	// If this is false, the buffer can't be reused as it still might have writes happening to it.
 	buffered bool
+	// written is closed and replaced every time data is written to the buffer.
+	written chan struct{}
 }
 
 // newBufReadWriter creates a new buffer.
 func newBufReadWriter() *bufReadWriter {
 	buf := new(bytes.Buffer)
-	return &bufReadWriter{buf: buf}
+	return &bufReadWriter{buf: buf, written: make(chan struct{})}
 }
 
-// Read returns at most len(a) from the memory buffer.
+// Read returns at most len(a) from the memory buffer. If the buffer is empty
+// but the track is still being downloaded, Read waits up to BufferingWait for
+// more data before returning.
 func (b *bufReadWriter) Read(a []byte) (int, error) {
+	b.bufferedMu.Lock()
+	done := b.buffered
+	b.bufferedMu.Unlock()
 	b.mu.Lock()
+	if !done && b.buf.Len() == 0 {
+		written := b.written
+		b.mu.Unlock()
+		select {
+		case <-written:
+		case <-time.After(BufferingWait):
+		}
+		b.mu.Lock()
+	}
 	defer b.mu.Unlock()
 	return b.buf.Read(a)
 }
@@ -485,7 +913,10 @@ func (b *bufReadWriter) Read(a []byte) (int, error) {
 func (b *bufReadWriter) Write(a []byte) (int, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	return b.buf.Write(a)
+	n, err := b.buf.Write(a)
+	close(b.written)
+	b.written = make(chan struct{})
+	return n, err
 }
 
 // Reset empties the buffer so it can be reused for new content.
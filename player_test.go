@@ -18,6 +18,7 @@
 package jamsonic
 
 import (
+	"context"
 	"errors"
 	"io"
 	"io/ioutil"
@@ -700,6 +701,305 @@ func TestErrorHandle(t *testing.T) {
 	errChan <- errors.New("test error")
 }
 
+func TestEnqueue(t *testing.T) {
+	assert := assert.New(t)
+	p, _, _, _ := getPlayer()
+	assert.Empty(p.Queue(), "Queue should be empty for a new player")
+
+	p.CreatePlayQueue(tracks[:2])
+	p.Enqueue(tracks[2:]...)
+	assert.Equal(tracks, p.Queue(), "Tracks not added to the end of the queue")
+
+	q := p.Queue()
+	q[0] = nil
+	assert.Equal(tracks[0], p.NextTrack(), "Queue should return a copy")
+	p.Close()
+}
+
+func TestClear(t *testing.T) {
+	assert := assert.New(t)
+	p, _, _, _ := getPlayer()
+	p.CreatePlayQueue(tracks)
+	p.Play()
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(Playing, p.GetCurrentState(), "Should be playing")
+
+	p.Clear()
+	// The player is already stopped, Stop is only used to wait for Clear to be handled.
+	p.Stop()
+	assert.Equal(Stopped, p.GetCurrentState(), "Should be stopped")
+	assert.Nil(p.CurrentTrack(), "Current track should be cleared")
+	assert.Empty(p.Queue(), "Queue should be empty")
+	p.Close()
+}
+
+func TestSeek(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("not_supported", func(t *testing.T) {
+		p, _, _, _ := getPlayer()
+		assert.Equal(ErrSeekNotSupported, p.Seek(time.Second), "Wrong error returned")
+		p.Close()
+	})
+
+	t.Run("seek_current_track", func(t *testing.T) {
+		p, handler := getOffsetPlayer()
+		events, cancel := p.Subscribe()
+		defer cancel()
+		p.CreatePlayQueue(tracks)
+		p.Play()
+		time.Sleep(time.Millisecond * 100)
+
+		err := p.Seek(time.Minute)
+		assert.NoError(err, "Seek should not fail")
+		time.Sleep(time.Millisecond * 100)
+		handler.offsetMu.Lock()
+		assert.Equal(time.Minute, handler.offset, "Wrong offset passed to the handler")
+		handler.offsetMu.Unlock()
+		assert.Equal(tracks[0], p.CurrentTrack(), "Should keep playing the same track")
+		assert.True(p.Position() >= time.Minute, "Position should be moved to the offset")
+
+		seeked := false
+		for len(events) > 0 {
+			if e := <-events; e.Type == PositionChanged {
+				seeked = true
+			}
+		}
+		assert.True(seeked, "No position changed event sent")
+		p.Close()
+	})
+
+	t.Run("play_at", func(t *testing.T) {
+		p, handler := getOffsetPlayer()
+		p.CreatePlayQueue(tracks)
+		p.PlayAt(time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == tracks[0] })
+		handler.offsetMu.Lock()
+		assert.Equal(time.Minute, handler.offset, "Wrong offset passed to the handler")
+		handler.offsetMu.Unlock()
+		assert.Equal(Playing, p.GetCurrentState())
+		assert.True(p.Position() >= time.Minute, "Position should start at the offset")
+		assert.Len(p.Queue(), len(tracks)-1, "Track should be taken from the queue")
+		p.Close()
+	})
+
+	t.Run("provider_offset", func(t *testing.T) {
+		provider := &mockOffsetProvider{mockProvider: mockProvider{
+			doGetStream: func(id string) (io.ReadCloser, error) {
+				return &recorder{streamID: id}, nil
+			},
+		}}
+		handler := &mockStreaHandler{
+			doFinished: func() <-chan struct{} { return make(chan struct{}) },
+			doPlay:     func(io.Reader) error { return nil },
+			doStop:     func() {},
+			errChan:    make(chan error),
+		}
+		p := NewPlayer(DefaultLogger(), provider, handler, nil, 0)
+		p.CreatePlayQueue(tracks)
+		p.PlayAt(time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == tracks[0] })
+		provider.offsetMu.Lock()
+		assert.Equal(time.Minute, provider.offset, "Stream should start at the offset")
+		provider.offsetMu.Unlock()
+		assert.True(p.Position() >= time.Minute, "Position should start at the offset")
+		assert.NoError(p.Seek(2*time.Minute), "Provider can seek")
+		p.Close()
+	})
+}
+
+func TestTrackStreamHandler(t *testing.T) {
+	assert := assert.New(t)
+	newHandler := func() *mockTrackHandler {
+		return &mockTrackHandler{mockStreaHandler: mockStreaHandler{
+			doFinished: func() <-chan struct{} { return make(chan struct{}) },
+			doStop:     func() {},
+			errChan:    make(chan error),
+		}}
+	}
+
+	t.Run("play_track", func(t *testing.T) {
+		handler := newHandler()
+		provider := &mockProvider{
+			doGetStream: func(id string) (io.ReadCloser, error) {
+				return &recorder{streamID: id}, nil
+			},
+		}
+		p := NewPlayer(DefaultLogger(), provider, handler, nil, 0)
+		p.CreatePlayQueue(tracks)
+		p.PlayAt(time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == tracks[0] })
+		track, offset := handler.played()
+		assert.Equal(tracks[0], track)
+		assert.Equal(time.Minute, offset)
+		provider.streamIDMu.RLock()
+		assert.Equal("", provider.streamID, "The track should not be downloaded")
+		provider.streamIDMu.RUnlock()
+		assert.NoError(p.Seek(2*time.Minute), "Handler can seek")
+		p.Close()
+	})
+
+	t.Run("update_handler", func(t *testing.T) {
+		handler := newHandler()
+		p, local := getOffsetPlayer()
+		p.CreatePlayQueue(tracks)
+		p.PlayAt(time.Minute)
+		waitFor(t, func() bool { return p.CurrentTrack() == tracks[0] })
+		p.UpdateHandler(handler)
+		waitFor(t, func() bool {
+			track, _ := handler.played()
+			return track == tracks[0] && p.GetCurrentState() == Playing
+		})
+		_, offset := handler.played()
+		assert.True(offset >= time.Minute, "Should continue from the position")
+		calledMu.RLock()
+		assert.Equal(1, local.calledStopped, "Old handler should be stopped")
+		calledMu.RUnlock()
+		assert.Len(p.Queue(), len(tracks)-1)
+		p.Close()
+	})
+
+	t.Run("update_while_used", func(t *testing.T) {
+		p, _ := getOffsetPlayer()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 10; i++ {
+				p.SetVolume(50)
+				p.Seek(time.Minute)
+			}
+		}()
+		p.UpdateHandler(newHandler())
+		<-done
+		p.Close()
+	})
+}
+
+func TestStreamCancel(t *testing.T) {
+	assert := assert.New(t)
+	// waitReturn fails the test if f doesn't return in time.
+	waitReturn := func(t *testing.T, f func()) {
+		done := make(chan struct{})
+		go func() {
+			f()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("The slow stream was not cancelled")
+		}
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		p, provider := getSlowStreamPlayer()
+		p.CreatePlayQueue(tracks[:2])
+		p.Play()
+		<-provider.started
+		waitReturn(t, p.Stop)
+		assert.Equal(Stopped, p.GetCurrentState())
+		assert.Equal(tracks[:2], p.Queue(), "The stopped track should be first in the queue")
+		p.Close()
+	})
+	t.Run("next", func(t *testing.T) {
+		p, provider := getSlowStreamPlayer()
+		p.CreatePlayQueue(tracks[:2])
+		p.Play()
+		<-provider.started
+		waitReturn(t, p.Next)
+		waitFor(t, func() bool {
+			ct := p.CurrentTrack()
+			return ct != nil && ct.ID == "2"
+		})
+		assert.Equal(Playing, p.GetCurrentState(), "Should play the next track")
+		p.Close()
+	})
+	t.Run("close", func(t *testing.T) {
+		p, provider := getSlowStreamPlayer()
+		p.CreatePlayQueue(tracks[:2])
+		p.Play()
+		<-provider.started
+		waitReturn(t, p.Close)
+	})
+}
+
+func TestVolume(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("not_supported", func(t *testing.T) {
+		p, _, _, _ := getPlayer()
+		assert.Equal(ErrVolumeNotSupported, p.SetVolume(50), "Wrong error returned")
+		assert.Equal(MaxVolume, p.Volume(), "Volume should not change")
+		p.Close()
+	})
+
+	t.Run("set_volume", func(t *testing.T) {
+		p, handler := getOffsetPlayer()
+		assert.NoError(p.SetVolume(50))
+		assert.Equal(50, p.Volume(), "Wrong volume returned")
+		assert.Equal(50, handler.volume, "Volume not passed to the handler")
+
+		assert.NoError(p.SetVolume(150))
+		assert.Equal(MaxVolume, p.Volume(), "Volume should be capped")
+		p.Close()
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	assert := assert.New(t)
+	p, _, _, _ := getPlayer()
+	events, cancel := p.Subscribe()
+
+	p.CreatePlayQueue(tracks)
+	e := <-events
+	assert.Equal(QueueChanged, e.Type, "Wrong event type")
+	assert.Equal(Stopped, e.State, "Wrong state in the event")
+
+	cancel()
+	_, ok := <-events
+	assert.False(ok, "Channel should be closed when canceled")
+	p.Close()
+}
+
+func getOffsetPlayer() (*Player, *mockOffsetHandler) {
+	handler := &mockOffsetHandler{
+		mockStreaHandler: mockStreaHandler{
+			doFinished: func() <-chan struct{} { return make(chan struct{}) },
+			doPlay:     func(io.Reader) error { return nil },
+			doStop:     func() {},
+			doPause:    func() {},
+			doContinue: func() {},
+			errChan:    make(chan error),
+		},
+	}
+	provider := &mockProvider{
+		doGetStream: func(id string) (io.ReadCloser, error) {
+			return &recorder{streamID: id}, nil
+		},
+	}
+	return NewPlayer(DefaultLogger(), provider, handler, nil, 0), handler
+}
+
+func getSlowStreamPlayer() (*Player, *slowStreamProvider) {
+	handler := &mockStreaHandler{
+		doFinished: func() <-chan struct{} { return make(chan struct{}) },
+		doPlay:     func(io.Reader) error { return nil },
+		doStop:     func() {},
+		doPause:    func() {},
+		doContinue: func() {},
+		errChan:    make(chan error),
+	}
+	provider := &slowStreamProvider{
+		mockProvider: mockProvider{
+			doGetStream: func(id string) (io.ReadCloser, error) {
+				return &recorder{streamID: id}, nil
+			},
+		},
+		started: make(chan struct{}),
+	}
+	return NewPlayer(DefaultLogger(), provider, handler, nil, 0), provider
+}
+
 func getPlayer() (*Player, chan struct{}, *mockProvider, *mockStreaHandler) {
 	finishedChan := make(chan struct{})
 
@@ -824,6 +1124,77 @@ func (m *mockStreaHandler) Errors() <-chan error {
 	return m.errChan
 }
 
+type mockOffsetHandler struct {
+	mockStreaHandler
+	offset   time.Duration
+	offsetMu sync.Mutex
+	volume   int
+}
+
+func (m *mockOffsetHandler) PlayFrom(r io.Reader, offset time.Duration) error {
+	m.offsetMu.Lock()
+	defer m.offsetMu.Unlock()
+	m.offset = offset
+	return nil
+}
+
+func (m *mockOffsetHandler) SetVolume(percent int) {
+	m.volume = percent
+}
+
+// mockTrackHandler plays the tracks without streams.
+type mockTrackHandler struct {
+	mockStreaHandler
+	mu     sync.Mutex
+	track  *Track
+	offset time.Duration
+}
+
+func (m *mockTrackHandler) PlayTrack(track *Track, offset time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.track, m.offset = track, offset
+	return nil
+}
+
+func (m *mockTrackHandler) played() (*Track, time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.track, m.offset
+}
+
+// mockOffsetProvider can start the streams at an offset.
+type mockOffsetProvider struct {
+	mockProvider
+	offset   time.Duration
+	offsetMu sync.Mutex
+}
+
+func (m *mockOffsetProvider) SetStreamSettings(settings StreamSettings) {}
+
+func (m *mockOffsetProvider) GetStreamAt(ctx context.Context, songID string, offset time.Duration) (io.ReadCloser, error) {
+	m.offsetMu.Lock()
+	m.offset = offset
+	m.offsetMu.Unlock()
+	return m.GetStream(ctx, songID)
+}
+
+// slowStreamProvider doesn't return the stream for track 1 until the
+// request is cancelled.
+type slowStreamProvider struct {
+	mockProvider
+	started chan struct{}
+}
+
+func (m *slowStreamProvider) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
+	if songID != "1" {
+		return m.mockProvider.GetStream(ctx, songID)
+	}
+	close(m.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
 type mockProvider struct {
 	streamID              string
 	streamIDMu            sync.RWMutex
@@ -836,31 +1207,31 @@ type mockProvider struct {
 	doGetProvider         func() MusicProvider
 }
 
-func (m *mockProvider) ListTracks() ([]*Track, error) {
+func (m *mockProvider) ListTracks(ctx context.Context) ([]*Track, error) {
 	return m.doListTracks()
 }
 
-func (m *mockProvider) FetchLibrary() ([]*Artist, error) {
+func (m *mockProvider) FetchLibrary(ctx context.Context) ([]*Artist, error) {
 	return m.doFetchLibrary()
 }
 
-func (m *mockProvider) GetTrackInfo(trackID string) (*Track, error) {
+func (m *mockProvider) GetTrackInfo(ctx context.Context, trackID string) (*Track, error) {
 	return m.doGetTrackInfo(trackID)
 }
 
-func (m *mockProvider) GetStream(songID string) (io.ReadCloser, error) {
+func (m *mockProvider) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
 	m.streamIDMu.Lock()
 	defer m.streamIDMu.Unlock()
 	m.streamID = songID
 	return m.doGetStream(songID)
 }
 
-func (m *mockProvider) ListPlaylists() ([]*Playlist, error) {
+func (m *mockProvider) ListPlaylists(ctx context.Context) ([]*Playlist, error) {
 	return m.doListPlaylists()
 }
 
-func (m *mockProvider) ListPlaylistEntries() ([]*PlaylistEntry, error) {
-	return m.ListPlaylistEntries()
+func (m *mockProvider) ListPlaylistEntries(ctx context.Context) ([]*PlaylistEntry, error) {
+	return m.ListPlaylistEntries(ctx)
 }
 
 func (m *mockProvider) GetProvider() MusicProvider {
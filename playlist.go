@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import "context"
+
+// PlaylistManager is implemented by providers that can fetch and edit
+// playlists on the server.
+type PlaylistManager interface {
+	// Playlist returns the playlist with its tracks.
+	Playlist(ctx context.Context, id string) (*Playlist, error)
+	// CreatePlaylist creates a new playlist with the tracks.
+	CreatePlaylist(ctx context.Context, name string, trackIDs []string) (*Playlist, error)
+	// RenamePlaylist changes the name of the playlist.
+	RenamePlaylist(ctx context.Context, id, name string) error
+	// AppendToPlaylist adds the tracks to the end of the playlist.
+	AppendToPlaylist(ctx context.Context, id string, trackIDs []string) error
+	// RemoveFromPlaylist removes the tracks at the indexes from the playlist.
+	RemoveFromPlaylist(ctx context.Context, id string, indexes []int) error
+	// ReorderPlaylist replaces the tracks in the playlist with the tracks
+	// in the given order.
+	ReorderPlaylist(ctx context.Context, id string, trackIDs []string) error
+	// DeletePlaylist deletes the playlist.
+	DeletePlaylist(ctx context.Context, id string) error
+}
+
+// RefreshPlaylists fetches all the playlists from the provider and saves
+// them to the store. If the provider is a PlaylistManager, the tracks of
+// each playlist are fetched as well.
+func RefreshPlaylists(ctx context.Context, db PlaylistStore, provider Provider) error {
+	playlists, err := provider.ListPlaylists(ctx)
+	if err != nil {
+		return err
+	}
+	if m, ok := provider.(PlaylistManager); ok {
+		for i, p := range playlists {
+			pl, err := m.Playlist(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+			playlists[i] = pl
+		}
+	}
+	return db.SavePlaylists(playlists)
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// podcastSaveInterval is how often the position of the episode being
+	// played is saved.
+	podcastSaveInterval = 5 * time.Second
+	// podcastPlayedMargin is how close to the end an episode has to be
+	// played to be marked as played.
+	podcastPlayedMargin = 30 * time.Second
+)
+
+// PodcastChannel is a podcast the server is subscribed to.
+type PodcastChannel struct {
+	// ID is the channel's ID.
+	ID string
+	// URL is the URL of the podcast feed.
+	URL string
+	// Title is the name of the podcast.
+	Title string
+	// Description describes the podcast.
+	Description string
+	// CoverArt is the ID of the podcast's cover art, if any.
+	CoverArt string
+	// Status is the server's status of the channel, like "completed" or
+	// "error".
+	Status string
+	// Episodes are the channel's episodes, newest first. It's nil if only
+	// the channel information has been fetched.
+	Episodes []*PodcastEpisode
+}
+
+// PodcastEpisode is an episode of a podcast.
+type PodcastEpisode struct {
+	// ID is the episode's ID.
+	ID string
+	// StreamID is the ID used to stream the episode. It's empty until the
+	// server has downloaded the episode.
+	StreamID string
+	// ChannelID is the ID of the episode's channel.
+	ChannelID string
+	// Title is the episode's title.
+	Title string
+	// Description describes the episode.
+	Description string
+	// Published is when the episode was published.
+	Published time.Time
+	// Status is the server's download status, like "new", "downloading"
+	// or "completed".
+	Status string
+	// Duration is the length of the episode, if known.
+	Duration time.Duration
+	// CoverArt is the ID of the episode's cover art, if any.
+	CoverArt string
+}
+
+// Downloaded returns true if the server has downloaded the episode so it
+// can be played.
+func (e *PodcastEpisode) Downloaded() bool {
+	return e.Status == "completed" && e.StreamID != ""
+}
+
+// Track returns a track that plays the episode.
+func (e *PodcastEpisode) Track() *Track {
+	return &Track{
+		ID:             e.StreamID,
+		Title:          e.Title,
+		DurationMillis: strconv.FormatInt(int64(e.Duration/time.Millisecond), 10),
+		CoverArt:       e.CoverArt,
+		EpisodeID:      e.ID,
+	}
+}
+
+// PodcastProvider is implemented by providers that handle podcasts.
+type PodcastProvider interface {
+	// Podcasts returns the channels, with their episodes if includeEpisodes
+	// is true.
+	Podcasts(ctx context.Context, includeEpisodes bool) ([]*PodcastChannel, error)
+	// NewestPodcasts returns up to count of the newest episodes.
+	NewestPodcasts(ctx context.Context, count int) ([]*PodcastEpisode, error)
+	// DownloadPodcastEpisode asks the server to download the episode.
+	DownloadPodcastEpisode(ctx context.Context, id string) error
+	// CreatePodcastChannel subscribes to the podcast feed at the URL.
+	CreatePodcastChannel(ctx context.Context, url string) error
+	// DeletePodcastChannel unsubscribes from the podcast.
+	DeletePodcastChannel(ctx context.Context, id string) error
+	// RefreshPodcasts asks the server to check the feeds for new episodes.
+	RefreshPodcasts(ctx context.Context) error
+}
+
+// EpisodeProgress is how far an episode has been listened to.
+type EpisodeProgress struct {
+	// Position is where to resume the episode.
+	Position time.Duration
+	// Played is true if the episode has been played to the end.
+	Played bool
+}
+
+// PodcastStore stores the progress of the podcast episodes.
+type PodcastStore interface {
+	// PodcastProgress returns the progress of the episodes by episode ID.
+	PodcastProgress() (map[string]*EpisodeProgress, error)
+	// SavePodcastProgress stores the progress of the episode.
+	SavePodcastProgress(episodeID string, progress *EpisodeProgress) error
+}
+
+// PodcastTracker saves the progress of the episodes played and resumes
+// episodes from where they were left.
+type PodcastTracker struct {
+	player *Player
+	db     PodcastStore
+	logger *Logger
+	cancel func()
+	done   chan struct{}
+
+	mu sync.Mutex
+	// current is the episode being played, or nil.
+	current *Track
+	// position is the last known position in the current episode.
+	position time.Duration
+	// saved is the position last saved.
+	saved time.Duration
+	// seeking is set until the player has moved to the resume position.
+	seeking bool
+}
+
+// NewPodcastTracker starts tracking the episodes played by the player.
+func NewPodcastTracker(player *Player, db PodcastStore, logger *Logger) *PodcastTracker {
+	events, cancel := player.Subscribe()
+	t := &PodcastTracker{
+		player: player,
+		db:     db,
+		logger: logger,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go t.listen(events)
+	return t
+}
+
+// Close saves the progress of the current episode and stops tracking.
+func (t *PodcastTracker) Close() {
+	t.cancel()
+	<-t.done
+}
+
+func (t *PodcastTracker) listen(events <-chan *Event) {
+	defer close(t.done)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.mu.Lock()
+				if t.current != nil && !t.seeking && t.player.CurrentTrack() == t.current {
+					t.position = t.player.Position()
+				}
+				t.mu.Unlock()
+				t.finish()
+				return
+			}
+			switch e.Type {
+			case TrackChanged:
+				t.mu.Lock()
+				same := e.CurrentTrack != nil && e.CurrentTrack == t.current
+				t.mu.Unlock()
+				// A seek restarts the same track.
+				if same {
+					continue
+				}
+				t.finish()
+				t.start(e.CurrentTrack)
+			case PositionChanged:
+				t.mu.Lock()
+				t.seeking = false
+				t.mu.Unlock()
+			}
+		case <-ticker.C:
+			t.update()
+		}
+	}
+}
+
+// start begins tracking the track if it's an episode. If the episode has
+// been partly played, it's resumed from the saved position.
+func (t *PodcastTracker) start(track *Track) {
+	if track == nil || track.EpisodeID == "" {
+		return
+	}
+	var resume time.Duration
+	progress, err := t.db.PodcastProgress()
+	if err != nil {
+		t.logger.ErrorLog("Failed to read the podcast progress: " + err.Error())
+	} else if p, ok := progress[track.EpisodeID]; ok && !p.Played {
+		resume = p.Position
+	}
+	t.mu.Lock()
+	t.current = track
+	t.position = resume
+	t.saved = resume
+	t.seeking = resume > 0
+	t.mu.Unlock()
+	if resume > 0 {
+		t.logger.DebugLog("Resuming the episode at " + resume.String())
+		if err := t.player.Seek(resume); err != nil {
+			t.logger.ErrorLog("Can't resume the episode: " + err.Error())
+			t.mu.Lock()
+			t.seeking = false
+			t.mu.Unlock()
+		}
+	}
+}
+
+// update reads the position of the current episode and saves it every
+// podcastSaveInterval.
+func (t *PodcastTracker) update() {
+	t.mu.Lock()
+	if t.current == nil || t.seeking || t.player.CurrentTrack() != t.current || t.player.GetCurrentState() == Stopped {
+		t.mu.Unlock()
+		return
+	}
+	t.position = t.player.Position()
+	if t.position-t.saved < podcastSaveInterval && t.position >= t.saved {
+		t.mu.Unlock()
+		return
+	}
+	t.saved = t.position
+	id, progress := t.current.EpisodeID, &EpisodeProgress{Position: t.position}
+	t.mu.Unlock()
+	t.save(id, progress)
+}
+
+// finish saves the progress of the episode that stopped playing. If it
+// was played close to the end, it's marked as played.
+func (t *PodcastTracker) finish() {
+	t.mu.Lock()
+	track, position := t.current, t.position
+	t.current = nil
+	t.mu.Unlock()
+	if track == nil {
+		return
+	}
+	progress := &EpisodeProgress{Position: position}
+	if d, err := strconv.Atoi(track.DurationMillis); err == nil && d > 0 &&
+		position >= time.Duration(d)*time.Millisecond-podcastPlayedMargin {
+		progress = &EpisodeProgress{Played: true}
+	}
+	t.save(track.EpisodeID, progress)
+}
+
+func (t *PodcastTracker) save(episodeID string, progress *EpisodeProgress) {
+	if err := t.db.SavePodcastProgress(episodeID, progress); err != nil {
+		t.logger.ErrorLog("Failed to save the podcast progress: " + err.Error())
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPodcastStore struct {
+	mu       sync.Mutex
+	progress map[string]*EpisodeProgress
+}
+
+func (m *mockPodcastStore) PodcastProgress() (map[string]*EpisodeProgress, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	progress := make(map[string]*EpisodeProgress, len(m.progress))
+	for k, v := range m.progress {
+		progress[k] = v
+	}
+	return progress, nil
+}
+
+func (m *mockPodcastStore) SavePodcastProgress(episodeID string, progress *EpisodeProgress) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.progress[episodeID] = progress
+	return nil
+}
+
+func (m *mockPodcastStore) get(episodeID string) *EpisodeProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.progress[episodeID]
+}
+
+func TestPodcastTracker(t *testing.T) {
+	assert := assert.New(t)
+	episode := &PodcastEpisode{ID: "E1", StreamID: "1", Status: "completed", Duration: time.Hour}
+	assert.True(episode.Downloaded())
+	assert.False((&PodcastEpisode{ID: "E2", Status: "new"}).Downloaded())
+	assert.Equal("3600000", episode.Track().DurationMillis)
+
+	t.Run("resume", func(t *testing.T) {
+		db := &mockPodcastStore{progress: map[string]*EpisodeProgress{"E1": {Position: 10 * time.Minute}}}
+		p, handler := getOffsetPlayer()
+		tracker := NewPodcastTracker(p, db, DefaultLogger())
+		p.CreatePlayQueue([]*Track{episode.Track(), tracks[1]})
+		p.Play()
+		waitFor(t, func() bool {
+			handler.offsetMu.Lock()
+			defer handler.offsetMu.Unlock()
+			return handler.offset == 10*time.Minute
+		})
+
+		// Skipping saves the position.
+		p.Next()
+		waitFor(t, func() bool {
+			progress := db.get("E1")
+			return progress.Position >= 10*time.Minute && !progress.Played
+		})
+		tracker.Close()
+		p.Close()
+	})
+
+	t.Run("seek_once", func(t *testing.T) {
+		db := &mockPodcastStore{progress: map[string]*EpisodeProgress{"E1": {Position: 10 * time.Minute}}}
+		p, handler := getOffsetPlayer()
+		events, cancel := p.Subscribe()
+		tracker := NewPodcastTracker(p, db, DefaultLogger())
+		p.CreatePlayQueue([]*Track{episode.Track(), tracks[1]})
+		p.Play()
+		seeks := 0
+		timeout := time.After(500 * time.Millisecond)
+	count:
+		for {
+			select {
+			case e := <-events:
+				if e.Type == PositionChanged {
+					seeks++
+				}
+			case <-timeout:
+				break count
+			}
+		}
+		cancel()
+		assert.Equal(1, seeks, "Should only seek to the resume position once")
+
+		// A seek by the user is not undone.
+		assert.NoError(p.Seek(20 * time.Minute))
+		time.Sleep(100 * time.Millisecond)
+		handler.offsetMu.Lock()
+		assert.Equal(20*time.Minute, handler.offset)
+		handler.offsetMu.Unlock()
+		tracker.Close()
+		p.Close()
+	})
+
+	t.Run("played", func(t *testing.T) {
+		db := &mockPodcastStore{progress: map[string]*EpisodeProgress{"E1": {Position: 59*time.Minute + 50*time.Second}}}
+		p, _ := getOffsetPlayer()
+		tracker := NewPodcastTracker(p, db, DefaultLogger())
+		p.CreatePlayQueue([]*Track{episode.Track(), tracks[1]})
+		p.Play()
+		waitFor(t, func() bool { return p.Position() >= 59*time.Minute })
+		time.Sleep(1100 * time.Millisecond)
+		p.Next()
+		waitFor(t, func() bool { return db.get("E1").Played })
+		assert.Zero(db.get("E1").Position, "Played episodes should start from the beginning")
+		tracker.Close()
+		p.Close()
+	})
+
+	t.Run("save_on_close", func(t *testing.T) {
+		db := &mockPodcastStore{progress: map[string]*EpisodeProgress{"E1": {Position: 10 * time.Minute}}}
+		p, _ := getOffsetPlayer()
+		tracker := NewPodcastTracker(p, db, DefaultLogger())
+		p.CreatePlayQueue([]*Track{episode.Track()})
+		p.Play()
+		waitFor(t, func() bool { return p.Position() >= 10*time.Minute })
+		assert.NoError(p.Seek(20 * time.Minute))
+		waitFor(t, func() bool { return p.Position() >= 20*time.Minute })
+		tracker.Close()
+		assert.True(db.get("E1").Position >= 20*time.Minute, "Should save the position when closed")
+		p.Close()
+	})
+
+	t.Run("not_episode", func(t *testing.T) {
+		db := &mockPodcastStore{progress: map[string]*EpisodeProgress{}}
+		p, _ := getOffsetPlayer()
+		tracker := NewPodcastTracker(p, db, DefaultLogger())
+		p.CreatePlayQueue([]*Track{tracks[0], tracks[1]})
+		p.Play()
+		time.Sleep(100 * time.Millisecond)
+		p.Next()
+		time.Sleep(100 * time.Millisecond)
+		progress, _ := db.PodcastProgress()
+		assert.Empty(progress)
+		tracker.Close()
+		p.Close()
+	})
+}
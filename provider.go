@@ -17,7 +17,11 @@
 
 package jamsonic
 
-import "io"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 // MusicProvider is the provider identifier.
 type MusicProvider int
@@ -32,19 +36,19 @@ const (
 // Provider is a music provider.
 type Provider interface {
 	// ListTracks returns all the tracks from the provider. [DEPRECATED]
-	ListTracks() ([]*Track, error)
+	ListTracks(ctx context.Context) ([]*Track, error)
 	// FetchLibrary gets the library from the server. This implementation
 	// should be used instead of the old implementations.
-	FetchLibrary() ([]*Artist, error)
+	FetchLibrary(ctx context.Context) ([]*Artist, error)
 	// GetTrackInfo returns information abot the track from the provider.
-	GetTrackInfo(trackID string) (*Track, error)
+	GetTrackInfo(ctx context.Context, trackID string) (*Track, error)
 	// GetStream returns a ReadCloser stream of the track. The stream
 	// has to be a MP3 encoded stream. [DEPRECATED]
-	GetStream(songID string) (io.ReadCloser, error)
+	GetStream(ctx context.Context, songID string) (io.ReadCloser, error)
 	// ListPlaylists returns all the playlists from the provider.
-	ListPlaylists() ([]*Playlist, error)
+	ListPlaylists(ctx context.Context) ([]*Playlist, error)
 	// ListPlaylistEntries returns to entries in the playlist. [DEPRECATED]
-	ListPlaylistEntries() ([]*PlaylistEntry, error)
+	ListPlaylistEntries(ctx context.Context) ([]*PlaylistEntry, error)
 	// GetProvider returns the MusicProvider type.
 	GetProvider() MusicProvider
 }
@@ -53,6 +57,8 @@ type Provider interface {
 type Track struct {
 	// Artist is the name of the artist.
 	Artist string
+	// ArtistID is the ID of the artist, if known.
+	ArtistID string
 	// Album is the album.
 	Album string
 	// AlbumArtist is the album artist.
@@ -73,6 +79,43 @@ type Track struct {
 	Title string
 	// Year is the year the track was released.
 	Year uint32
+	// Genre is the track's genre, if known.
+	Genre string
+	// BitRate is the bit rate of the file on the server in kbps, or 0 if
+	// not known.
+	BitRate int
+	// Suffix is the extension of the file on the server, like "flac".
+	Suffix string
+	// ContentType is the MIME type of the file on the server.
+	ContentType string
+	// Path is the file's path on the server, if the server tells it.
+	Path string
+	// Created is when the track was added to the server, if known.
+	Created time.Time
+	// Starred is true if the user has starred the track.
+	Starred bool
+	// StarredAt is when the user starred the track, if known.
+	StarredAt time.Time
+	// Rating is the user's rating from 1 to 5, or 0 if not rated.
+	Rating int
+	// CoverArt is the ID of the track's cover art, if any.
+	CoverArt string
+	// Live is true for streams that never end, like internet radio.
+	Live bool
+	// StreamURL is the URL of a live stream.
+	StreamURL string
+	// EpisodeID is the ID of the podcast episode, if the track is one.
+	EpisodeID string
+	// Artists are all the track's artists, if the server lists them.
+	Artists []string
+	// AlbumArtists are all the album's artists, if the server lists them.
+	AlbumArtists []string
+	// Moods are the track's moods, if any.
+	Moods []string
+	// BPM is the track's beats per minute, or 0 if not known.
+	BPM int
+	// ExplicitStatus is "explicit", "clean" or empty if not known.
+	ExplicitStatus string
 }
 
 // PlaylistEntry represents an entry in a playlist.
@@ -89,6 +132,17 @@ type Playlist struct {
 	ID string
 	// Name is the name of the playlist.
 	Name string
+	// Comment is the playlist's description.
+	Comment string
+	// Owner is the user that owns the playlist.
+	Owner string
+	// Public is true if other users can see the playlist.
+	Public bool
+	// SongCount is the number of tracks in the playlist.
+	SongCount int
+	// Tracks is an array of the tracks in the playlist, in order. It's nil
+	// if only the playlist information has been fetched.
+	Tracks []*Track
 }
 
 // Album holds all data for an album.
@@ -103,6 +157,21 @@ type Album struct {
 	ID string
 	// Tracks is an array of all the tracks.
 	Tracks []*Track
+	// Starred is true if the user has starred the album.
+	Starred bool
+	// Rating is the user's rating from 1 to 5, or 0 if not rated.
+	Rating int
+	// CoverArt is the ID of the album's cover art, if any.
+	CoverArt string
+	// Artists are all the album's artists, if the server lists them.
+	Artists []string
+	// Moods are the album's moods, if any.
+	Moods []string
+	// ExplicitStatus is "explicit", "clean" or empty if not known.
+	ExplicitStatus string
+	// Changed is when the album was added or last changed on the server,
+	// or the zero time if the server doesn't say.
+	Changed time.Time
 }
 
 // Artist holds all the data for an artist.
@@ -113,4 +182,10 @@ type Artist struct {
 	ID string
 	// Albums is an array of all the artist's albums.
 	Albums []*Album
+	// Starred is true if the user has starred the artist.
+	Starred bool
+	// Rating is the user's rating from 1 to 5, or 0 if not rated.
+	Rating int
+	// CoverArt is the ID of the artist's image, if any.
+	CoverArt string
 }
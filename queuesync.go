@@ -0,0 +1,314 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// queueSaveInterval is how often the queue is saved while playing.
+	queueSaveInterval = 30 * time.Second
+	// queueSaveTimeout limits the time to save the queue.
+	queueSaveTimeout = 10 * time.Second
+)
+
+// SavedPlayQueue is a play queue saved on the server, so it can be resumed
+// on another device.
+type SavedPlayQueue struct {
+	// Tracks are the queued tracks, including the current track.
+	Tracks []*Track
+	// Current is the ID of the current track, or empty if there's none.
+	Current string
+	// Position is the position in the current track.
+	Position time.Duration
+	// Changed is when the queue was saved, by the server's clock.
+	Changed time.Time
+	// ChangedBy is the name of the client that saved the queue.
+	ChangedBy string
+}
+
+// PlayQueueProvider is implemented by providers that can save the play queue
+// on the server.
+type PlayQueueProvider interface {
+	// SavePlayQueue saves the tracks with the IDs, the current track and
+	// the position in it.
+	SavePlayQueue(ctx context.Context, trackIDs []string, current string, position time.Duration) error
+	// PlayQueue returns the saved play queue, or nil if none is saved.
+	PlayQueue(ctx context.Context) (*SavedPlayQueue, error)
+}
+
+// queueSnapshot is the part of the play queue saved on the server.
+type queueSnapshot struct {
+	ids      []string
+	current  string
+	position time.Duration
+}
+
+func (q *queueSnapshot) equal(o *queueSnapshot) bool {
+	if q == nil || o == nil || q.current != o.current || q.position != o.position || len(q.ids) != len(o.ids) {
+		return false
+	}
+	for i := range q.ids {
+		if q.ids[i] != o.ids[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// QueueSync saves the player's queue on the server when the player is paused
+// or stopped, every queueSaveInterval while playing and when it's closed.
+// A queue saved by another device after the last change made in the player
+// is not overwritten. The server's timestamps are only compared with each
+// other, so the clocks of the devices don't matter.
+type QueueSync struct {
+	player   *Player
+	provider PlayQueueProvider
+	logger   *Logger
+	cancel   func()
+	done     chan struct{}
+
+	mu sync.Mutex
+	// serverChanged is the server's timestamp of the queue last seen saved
+	// on the server, by this or another device.
+	serverChanged time.Time
+	// changed is set when the player's queue, track or state changes after
+	// another device's queue was seen.
+	changed bool
+	// saved is the queue last saved or resumed.
+	saved *queueSnapshot
+	// conflict is set while the server has a newer queue.
+	conflict bool
+	// resumeID and resumeAt are the track and position to seek to once the
+	// resumed queue starts playing.
+	resumeID string
+	resumeAt time.Duration
+	// current is the track being played. stoppedID and stoppedAt are the
+	// track and position where the player was stopped. Stop puts the track
+	// back first in the queue and resets the position, so they're saved
+	// instead of starting the queue from the beginning.
+	current   *Track
+	stoppedID string
+	stoppedAt time.Duration
+}
+
+// NewQueueSync starts saving the player's queue with the provider.
+func NewQueueSync(player *Player, provider PlayQueueProvider, logger *Logger) *QueueSync {
+	events, cancel := player.Subscribe()
+	s := &QueueSync{
+		player:   player,
+		provider: provider,
+		logger:   logger,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go s.listen(events)
+	return s
+}
+
+// Close saves the queue a last time and stops saving it.
+func (s *QueueSync) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// Resume replaces the player's queue with the queue saved on the server and
+// plays it from the saved position. The saved queue is returned, or nil if
+// there is none.
+func (s *QueueSync) Resume(ctx context.Context) (*SavedPlayQueue, error) {
+	saved, err := s.provider.PlayQueue(ctx)
+	if err != nil || saved == nil || len(saved.Tracks) == 0 {
+		return nil, err
+	}
+	start, position := 0, time.Duration(0)
+	for i, t := range saved.Tracks {
+		if t.ID == saved.Current {
+			start, position = i, saved.Position
+			break
+		}
+	}
+	s.mu.Lock()
+	s.saved = snapshotOf(saved.Tracks, saved.Current, saved.Position)
+	s.serverChanged = saved.Changed
+	s.conflict = false
+	s.resumeID, s.resumeAt = saved.Tracks[start].ID, position
+	s.mu.Unlock()
+	s.player.Stop()
+	s.player.CreatePlayQueue(saved.Tracks[start:])
+	s.player.Play()
+	return saved, nil
+}
+
+func (s *QueueSync) listen(events <-chan *Event) {
+	defer close(s.done)
+	s.loadServerChanged()
+	ticker := time.NewTicker(queueSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				s.save()
+				return
+			}
+			switch e.Type {
+			case TrackChanged:
+				s.touch()
+				s.trackChanged(e)
+				s.seekResumed(e.CurrentTrack)
+			case StateChanged:
+				s.touch()
+				if e.State != Playing {
+					s.save()
+				}
+			case QueueChanged, PositionChanged:
+				s.touch()
+			}
+		case <-ticker.C:
+			if s.player.GetCurrentState() == Playing {
+				s.save()
+			}
+		}
+	}
+}
+
+// touch marks the queue as changed in the player.
+func (s *QueueSync) touch() {
+	s.mu.Lock()
+	s.changed = true
+	s.mu.Unlock()
+}
+
+// loadServerChanged reads when the queue on the server was saved, so a
+// queue saved by another device after this can be told apart.
+func (s *QueueSync) loadServerChanged() {
+	ctx, cancel := context.WithTimeout(context.Background(), queueSaveTimeout)
+	defer cancel()
+	server, err := s.provider.PlayQueue(ctx)
+	if err != nil {
+		s.logger.ErrorLog("Failed to get the saved play queue: " + err.Error())
+		return
+	}
+	if server != nil {
+		s.mu.Lock()
+		s.serverChanged = server.Changed
+		s.mu.Unlock()
+	}
+}
+
+// trackChanged remembers where the track was stopped. The event is sent
+// before the position is reset, so it's the position of the stopped track.
+func (s *QueueSync) trackChanged(e *Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.CurrentTrack == nil && s.current != nil && !s.current.Live {
+		s.stoppedID, s.stoppedAt = s.current.ID, e.Position
+	} else if e.CurrentTrack != nil {
+		s.stoppedID, s.stoppedAt = "", 0
+	}
+	s.current = e.CurrentTrack
+}
+
+// seekResumed moves to the saved position when the resumed track starts.
+func (s *QueueSync) seekResumed(track *Track) {
+	s.mu.Lock()
+	id, at := s.resumeID, s.resumeAt
+	s.resumeID = ""
+	s.mu.Unlock()
+	if track == nil || id == "" || track.ID != id || at <= 0 {
+		return
+	}
+	if err := s.player.Seek(at); err != nil {
+		s.logger.ErrorLog("Can't resume the track: " + err.Error())
+	}
+}
+
+// save saves the queue if it has changed since it was last saved and the
+// server doesn't have a newer queue.
+func (s *QueueSync) save() {
+	current := s.player.CurrentTrack()
+	var position time.Duration
+	var currentID string
+	tracks := s.player.Queue()
+	if current != nil && !current.Live {
+		tracks = append([]*Track{current}, tracks...)
+		currentID, position = current.ID, s.player.Position()
+	} else if current == nil && len(tracks) > 0 {
+		s.mu.Lock()
+		if tracks[0].ID == s.stoppedID {
+			currentID, position = s.stoppedID, s.stoppedAt
+		}
+		s.mu.Unlock()
+	}
+	snapshot := snapshotOf(tracks, currentID, position)
+	s.mu.Lock()
+	saved := s.saved
+	s.mu.Unlock()
+	if len(snapshot.ids) == 0 || snapshot.equal(saved) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queueSaveTimeout)
+	defer cancel()
+	server, err := s.provider.PlayQueue(ctx)
+	if err != nil {
+		s.logger.ErrorLog("Failed to get the saved play queue: " + err.Error())
+		return
+	}
+	s.mu.Lock()
+	if server != nil && !server.Changed.Equal(s.serverChanged) {
+		s.serverChanged = server.Changed
+		// The queue saved here last has a new timestamp the first time
+		// it's seen.
+		if !saved.equal(snapshotOf(server.Tracks, server.Current, server.Position)) {
+			if !s.conflict {
+				s.logger.InfoLog("The play queue was saved by " + server.ChangedBy + " after it was changed here, so it's not overwritten.")
+			}
+			s.conflict = true
+			s.changed = false
+		}
+	}
+	if s.conflict && !s.changed {
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	if err := s.provider.SavePlayQueue(ctx, snapshot.ids, snapshot.current, snapshot.position); err != nil {
+		s.logger.ErrorLog("Failed to save the play queue: " + err.Error())
+		return
+	}
+	s.mu.Lock()
+	s.saved = snapshot
+	s.conflict = false
+	s.mu.Unlock()
+}
+
+// snapshotOf returns the part of the queue saved on the server. Live streams
+// can't be saved, so they're left out. The server keeps the position in
+// milliseconds.
+func snapshotOf(tracks []*Track, current string, position time.Duration) *queueSnapshot {
+	q := &queueSnapshot{current: current, position: position.Truncate(time.Millisecond)}
+	for _, t := range tracks {
+		if !t.Live {
+			q.ids = append(q.ids, t.ID)
+		}
+	}
+	return q
+}
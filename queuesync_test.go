@@ -0,0 +1,218 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPlayQueueProvider struct {
+	mu    sync.Mutex
+	queue *SavedPlayQueue
+	saves int
+	loads int
+	// clock is the server's time, which may differ from this computer's.
+	clock time.Time
+}
+
+func (m *mockPlayQueueProvider) SavePlayQueue(ctx context.Context, trackIDs []string, current string, position time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = m.clock.Add(time.Second)
+	q := &SavedPlayQueue{Current: current, Position: position, Changed: m.clock, ChangedBy: "Jamsonic"}
+	for _, id := range trackIDs {
+		q.Tracks = append(q.Tracks, &Track{ID: id})
+	}
+	m.queue = q
+	m.saves++
+	return nil
+}
+
+func (m *mockPlayQueueProvider) PlayQueue(ctx context.Context) (*SavedPlayQueue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.loads++
+	return m.queue, nil
+}
+
+// saveFrom saves the queue as another device.
+func (m *mockPlayQueueProvider) saveFrom(client string, ids ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = m.clock.Add(time.Second)
+	q := &SavedPlayQueue{Current: ids[0], Changed: m.clock, ChangedBy: client}
+	for _, id := range ids {
+		q.Tracks = append(q.Tracks, &Track{ID: id})
+	}
+	m.queue = q
+}
+
+// loaded waits for the queue sync to read the queue on the server.
+func (m *mockPlayQueueProvider) loaded(t *testing.T) {
+	waitFor(t, func() bool {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.loads > 0
+	})
+}
+
+func (m *mockPlayQueueProvider) get() (*SavedPlayQueue, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.queue, m.saves
+}
+
+func TestQueueSync(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("save_on_pause", func(t *testing.T) {
+		provider := &mockPlayQueueProvider{}
+		p, _ := getOffsetPlayer()
+		s := NewQueueSync(p, provider, DefaultLogger())
+		p.CreatePlayQueue(tracks[:3])
+		p.Play()
+		waitFor(t, func() bool { return p.GetCurrentState() == Playing })
+		p.Pause()
+		waitFor(t, func() bool {
+			_, saves := provider.get()
+			return saves > 0
+		})
+		q, _ := provider.get()
+		assert.Equal("1", q.Current)
+		assert.Equal([]*Track{{ID: "1"}, {ID: "2"}, {ID: "3"}}, q.Tracks)
+		s.Close()
+		_, saves := provider.get()
+		assert.Equal(1, saves, "Should not save the same queue again")
+		p.Close()
+	})
+	t.Run("save_on_stop", func(t *testing.T) {
+		provider := &mockPlayQueueProvider{}
+		p, _ := getOffsetPlayer()
+		s := NewQueueSync(p, provider, DefaultLogger())
+		p.CreatePlayQueue(tracks[:3])
+		p.Play()
+		waitFor(t, func() bool { return p.GetCurrentState() == Playing })
+		require.NoError(t, p.Seek(30*time.Second))
+		waitFor(t, func() bool { return p.Position() >= 30*time.Second })
+		p.Stop()
+		waitFor(t, func() bool {
+			_, saves := provider.get()
+			return saves > 0
+		})
+		s.Close()
+		q, _ := provider.get()
+		assert.Equal("1", q.Current, "Should keep the stopped track as current")
+		assert.True(q.Position >= 30*time.Second, "Should keep the position")
+		assert.Equal([]*Track{{ID: "1"}, {ID: "2"}, {ID: "3"}}, q.Tracks)
+		p.Close()
+	})
+	t.Run("newer_on_server", func(t *testing.T) {
+		// The server's clock is far behind this computer's.
+		provider := &mockPlayQueueProvider{clock: time.Now().Add(-24 * time.Hour)}
+		provider.saveFrom("DSub", "3")
+		p, _ := getOffsetPlayer()
+		s := NewQueueSync(p, provider, DefaultLogger())
+		provider.loaded(t)
+		p.CreatePlayQueue(tracks[:3])
+		p.Play()
+		waitFor(t, func() bool { return p.GetCurrentState() == Playing })
+		provider.saveFrom("DSub", "4")
+		p.Pause()
+		time.Sleep(100 * time.Millisecond)
+		q, saves := provider.get()
+		assert.Zero(saves, "Should not overwrite a newer queue")
+		assert.Equal("4", q.Current)
+
+		// A change made here after the queue was seen is saved.
+		p.Play()
+		waitFor(t, func() bool { return p.GetCurrentState() == Playing })
+		p.Pause()
+		waitFor(t, func() bool {
+			_, saves := provider.get()
+			return saves > 0
+		})
+		s.Close()
+		q, _ = provider.get()
+		assert.Equal("1", q.Current, "Should save the queue changed here")
+		p.Close()
+	})
+	t.Run("server_clock_ahead", func(t *testing.T) {
+		provider := &mockPlayQueueProvider{clock: time.Now().Add(24 * time.Hour)}
+		provider.saveFrom("DSub", "4")
+		p, _ := getOffsetPlayer()
+		s := NewQueueSync(p, provider, DefaultLogger())
+		provider.loaded(t)
+		p.CreatePlayQueue(tracks[:3])
+		p.Play()
+		waitFor(t, func() bool { return p.GetCurrentState() == Playing })
+		p.Pause()
+		waitFor(t, func() bool {
+			_, saves := provider.get()
+			return saves == 1
+		})
+		p.Next()
+		waitFor(t, func() bool {
+			ct := p.CurrentTrack()
+			return p.GetCurrentState() == Playing && ct != nil && ct.ID == "2"
+		})
+		p.Pause()
+		waitFor(t, func() bool {
+			_, saves := provider.get()
+			return saves == 2
+		})
+		q, _ := provider.get()
+		assert.Equal("2", q.Current, "Should overwrite the queue seen when started and its own queue")
+		s.Close()
+		p.Close()
+	})
+	t.Run("resume", func(t *testing.T) {
+		provider := &mockPlayQueueProvider{queue: &SavedPlayQueue{
+			Tracks:   []*Track{{ID: "1"}, {ID: "2"}, {ID: "3"}},
+			Current:  "2",
+			Position: 30 * time.Second,
+		}}
+		p, handler := getOffsetPlayer()
+		s := NewQueueSync(p, provider, DefaultLogger())
+		saved, err := s.Resume(context.Background())
+		require.NoError(t, err)
+		assert.Equal("2", saved.Current)
+		waitFor(t, func() bool {
+			handler.offsetMu.Lock()
+			defer handler.offsetMu.Unlock()
+			return handler.offset == 30*time.Second
+		})
+		assert.Equal("2", p.CurrentTrack().ID)
+		assert.Equal([]*Track{{ID: "3"}}, p.Queue())
+		s.Close()
+		p.Close()
+	})
+	t.Run("nothing_saved", func(t *testing.T) {
+		p, _ := getOffsetPlayer()
+		s := NewQueueSync(p, &mockPlayQueueProvider{}, DefaultLogger())
+		saved, err := s.Resume(context.Background())
+		assert.NoError(err)
+		assert.Nil(saved)
+		s.Close()
+		p.Close()
+	})
+}
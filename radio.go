@@ -0,0 +1,246 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	// radioLowWater is the number of tracks left in the play queue when
+	// the radio fetches more tracks. Fetching before the queue is empty
+	// avoids gaps in the playback.
+	radioLowWater = 3
+	// radioBatchSize is the number of tracks requested at the time.
+	radioBatchSize = 10
+)
+
+// RadioFilter limits the tracks added by the radio. Zero values match all
+// tracks.
+type RadioFilter struct {
+	// Genre is the genre of the tracks.
+	Genre string
+	// FromYear is the earliest release year.
+	FromYear int
+	// ToYear is the latest release year.
+	ToYear int
+}
+
+// matches returns true if the track passes the filter. Tracks without a
+// year or genre are not filtered on them.
+func (f *RadioFilter) matches(t *Track) bool {
+	if f.Genre != "" && t.Genre != "" && !strings.EqualFold(f.Genre, t.Genre) {
+		return false
+	}
+	if t.Year == 0 {
+		return true
+	}
+	if f.FromYear != 0 && int(t.Year) < f.FromYear {
+		return false
+	}
+	return f.ToYear == 0 || int(t.Year) <= f.ToYear
+}
+
+// RadioProvider is implemented by providers that can suggest tracks for
+// the radio.
+type RadioProvider interface {
+	// SimilarTracks returns up to count tracks similar to the artist's.
+	SimilarTracks(ctx context.Context, artistID string, count int) ([]*Track, error)
+	// RandomTracks returns up to count random tracks matching the filter.
+	RandomTracks(ctx context.Context, filter *RadioFilter, count int) ([]*Track, error)
+}
+
+// Radio keeps the player's queue filled while it's on. Tracks similar to
+// the last track in the queue are added, with random tracks as a fallback.
+// Tracks that have been played or queued since the Radio was created are
+// not added again.
+type Radio struct {
+	player   *Player
+	provider RadioProvider
+	logger   *Logger
+	cancel   func()
+
+	mu        sync.Mutex
+	on        bool
+	filter    RadioFilter
+	seen      map[string]struct{}
+	refilling bool
+	// autoplay is set when the radio is started with nothing to play.
+	autoplay bool
+}
+
+// NewRadio returns a new Radio for the player. The radio is off until
+// Start is called.
+func NewRadio(player *Player, provider RadioProvider, logger *Logger) *Radio {
+	events, cancel := player.Subscribe()
+	r := &Radio{
+		player:   player,
+		provider: provider,
+		logger:   logger,
+		cancel:   cancel,
+		seen:     make(map[string]struct{}),
+	}
+	go r.listen(events)
+	return r
+}
+
+// Start turns on the radio with the filter. If the player is stopped with
+// an empty queue, the radio starts playing.
+func (r *Radio) Start(filter RadioFilter) {
+	idle := r.player.GetCurrentState() == Stopped && len(r.player.Queue()) == 0
+	r.mu.Lock()
+	r.on = true
+	r.filter = filter
+	r.autoplay = idle
+	r.mu.Unlock()
+	go r.refill()
+}
+
+// Stop turns off the radio. The tracks already queued are kept.
+func (r *Radio) Stop() {
+	r.mu.Lock()
+	r.on = false
+	r.autoplay = false
+	r.mu.Unlock()
+}
+
+// On returns true if the radio is on.
+func (r *Radio) On() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.on
+}
+
+// Filter returns the radio's current filter.
+func (r *Radio) Filter() RadioFilter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter
+}
+
+// Close stops listening to the player.
+func (r *Radio) Close() {
+	r.Stop()
+	r.cancel()
+}
+
+func (r *Radio) listen(events <-chan *Event) {
+	for e := range events {
+		if e.CurrentTrack != nil {
+			r.mu.Lock()
+			r.seen[e.CurrentTrack.ID] = struct{}{}
+			r.mu.Unlock()
+		}
+		if e.Type == TrackChanged || e.Type == QueueChanged {
+			go r.refill()
+		}
+	}
+}
+
+// refill adds tracks to the queue if the radio is on and the queue is
+// running low. Only one refill is done at the time.
+func (r *Radio) refill() {
+	r.mu.Lock()
+	if !r.on || r.refilling {
+		r.mu.Unlock()
+		return
+	}
+	r.refilling = true
+	filter := r.filter
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.refilling = false
+		r.mu.Unlock()
+	}()
+
+	queue := r.player.Queue()
+	if len(queue) >= radioLowWater {
+		return
+	}
+	current := r.player.CurrentTrack()
+	seed := current
+	if len(queue) > 0 {
+		seed = queue[len(queue)-1]
+	}
+	if current != nil {
+		queue = append(queue, current)
+	}
+	tracks, err := r.nextTracks(seed, &filter, queue)
+	if err != nil {
+		r.logger.ErrorLog("Radio failed to get tracks: " + err.Error())
+		return
+	}
+	r.mu.Lock()
+	if !r.on || len(tracks) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	for _, t := range tracks {
+		r.seen[t.ID] = struct{}{}
+	}
+	play := r.autoplay
+	r.autoplay = false
+	r.mu.Unlock()
+	r.player.Enqueue(tracks...)
+	if play {
+		r.player.Play()
+	}
+}
+
+// nextTracks returns new tracks similar to the seed. If there are none,
+// random tracks are returned.
+func (r *Radio) nextTracks(seed *Track, filter *RadioFilter, queue []*Track) ([]*Track, error) {
+	if seed != nil && seed.ArtistID != "" {
+		similar, err := r.provider.SimilarTracks(context.Background(), seed.ArtistID, radioBatchSize)
+		if err != nil {
+			r.logger.DebugLog("Radio failed to get similar tracks: " + err.Error())
+		}
+		if tracks := r.fresh(similar, filter, queue); len(tracks) > 0 {
+			return tracks, nil
+		}
+	}
+	random, err := r.provider.RandomTracks(context.Background(), filter, radioBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	return r.fresh(random, filter, queue), nil
+}
+
+// fresh returns the tracks matching the filter that haven't been played
+// and aren't in the queue.
+func (r *Radio) fresh(tracks []*Track, filter *RadioFilter, queue []*Track) []*Track {
+	queued := make(map[string]struct{}, len(queue))
+	for _, t := range queue {
+		queued[t.ID] = struct{}{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var fresh []*Track
+	for _, t := range tracks {
+		_, played := r.seen[t.ID]
+		_, inQueue := queued[t.ID]
+		if !played && !inQueue && filter.matches(t) {
+			fresh = append(fresh, t)
+			queued[t.ID] = struct{}{}
+		}
+	}
+	return fresh
+}
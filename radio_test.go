@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadio(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("start_when_idle", func(t *testing.T) {
+		p, _, _, _ := getPlayer()
+		provider := &mockRadioProvider{
+			random: []*Track{&Track{ID: "1"}, &Track{ID: "2"}, &Track{ID: "3"}, &Track{ID: "4"}},
+		}
+		r := NewRadio(p, provider, DefaultLogger())
+		defer r.Close()
+		r.Start(RadioFilter{})
+		waitFor(t, func() bool { return p.CurrentTrack() != nil })
+		assert.Equal("1", p.CurrentTrack().ID, "Should start playing the random tracks")
+		assert.True(r.On())
+		p.Close()
+	})
+
+	t.Run("similar_and_fresh", func(t *testing.T) {
+		p, _, _, _ := getPlayer()
+		provider := &mockRadioProvider{
+			similar: []*Track{&Track{ID: "1"}, &Track{ID: "2"}, &Track{ID: "3"}},
+		}
+		r := NewRadio(p, provider, DefaultLogger())
+		defer r.Close()
+		p.CreatePlayQueue([]*Track{&Track{ID: "1", ArtistID: "a1"}})
+		p.Play()
+		waitFor(t, func() bool { return p.CurrentTrack() != nil })
+		r.Start(RadioFilter{})
+		waitFor(t, func() bool { return len(p.Queue()) == 2 })
+		queue := p.Queue()
+		assert.Equal("2", queue[0].ID, "Played tracks should not be added again")
+		assert.Equal("3", queue[1].ID)
+		assert.Equal("a1", provider.getArtistID())
+		p.Close()
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		f := &RadioFilter{Genre: "rock", FromYear: 1990, ToYear: 1999}
+		assert.True(f.matches(&Track{Genre: "Rock", Year: 1995}))
+		assert.True(f.matches(&Track{}), "Unknown genre and year should match")
+		assert.False(f.matches(&Track{Genre: "Jazz"}))
+		assert.False(f.matches(&Track{Year: 2001}))
+	})
+
+	t.Run("off", func(t *testing.T) {
+		p, _, _, _ := getPlayer()
+		provider := &mockRadioProvider{err: errors.New("should not be called")}
+		r := NewRadio(p, provider, DefaultLogger())
+		p.CreatePlayQueue(tracks)
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(p.Queue(), len(tracks))
+		r.Close()
+		p.Close()
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for condition")
+}
+
+type mockRadioProvider struct {
+	mu       sync.Mutex
+	similar  []*Track
+	random   []*Track
+	err      error
+	artistID string
+}
+
+func (m *mockRadioProvider) SimilarTracks(ctx context.Context, artistID string, count int) ([]*Track, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.artistID = artistID
+	return m.similar, m.err
+}
+
+func (m *mockRadioProvider) RandomTracks(ctx context.Context, filter *RadioFilter, count int) ([]*Track, error) {
+	return m.random, m.err
+}
+
+func (m *mockRadioProvider) getArtistID() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.artistID
+}
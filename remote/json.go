@@ -0,0 +1,165 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// stateNames maps the player states to the names used in the API.
+var stateNames = map[jamsonic.State]string{
+	jamsonic.Stopped: "stopped",
+	jamsonic.Playing: "playing",
+	jamsonic.Paused:  "paused",
+}
+
+// eventNames maps the player events to the names used in the API.
+var eventNames = map[jamsonic.EventType]string{
+	jamsonic.StateChanged:       "state",
+	jamsonic.TrackChanged:       "track",
+	jamsonic.QueueChanged:       "queue",
+	jamsonic.VolumeChanged:      "volume",
+	jamsonic.PositionChanged:    "position",
+	jamsonic.StreamTitleChanged: "streamTitle",
+}
+
+type trackJSON struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Artist      string  `json:"artist,omitempty"`
+	Album       string  `json:"album,omitempty"`
+	AlbumArtist string  `json:"albumArtist,omitempty"`
+	TrackNumber uint32  `json:"track,omitempty"`
+	DiscNumber  uint8   `json:"disc,omitempty"`
+	Year        uint32  `json:"year,omitempty"`
+	Duration    float64 `json:"duration"`
+}
+
+type albumJSON struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Artist string       `json:"artist"`
+	Year   uint32       `json:"year,omitempty"`
+	Tracks []*trackJSON `json:"tracks"`
+}
+
+type artistJSON struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Albums []*albumJSON `json:"albums"`
+}
+
+// stateJSON is the player's state.
+type stateJSON struct {
+	State    string     `json:"state"`
+	Track    *trackJSON `json:"track"`
+	Position float64    `json:"position"`
+	Volume   int        `json:"volume"`
+}
+
+// eventJSON is sent to the WebSocket clients when the player changes.
+type eventJSON struct {
+	Type string `json:"type"`
+	stateJSON
+	Error string `json:"error,omitempty"`
+}
+
+type queueJSON struct {
+	Current *trackJSON   `json:"current"`
+	Tracks  []*trackJSON `json:"tracks"`
+}
+
+// queueRequest adds tracks to the play queue. If Replace is true, the
+// queue is replaced. If Play is true, the first of the tracks starts playing.
+type queueRequest struct {
+	IDs     []string `json:"ids"`
+	Replace bool     `json:"replace"`
+	Play    bool     `json:"play"`
+}
+
+// command is a player control command. It's used both by the REST API and
+// the WebSocket.
+type command struct {
+	Command string `json:"command"`
+	// Position is the seek offset in seconds.
+	Position float64 `json:"position"`
+	// Volume is the output volume in percent.
+	Volume int `json:"volume"`
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+func newTrackJSON(t *jamsonic.Track) *trackJSON {
+	if t == nil {
+		return nil
+	}
+	ms, _ := strconv.ParseInt(t.DurationMillis, 10, 64)
+	return &trackJSON{
+		ID:          t.ID,
+		Title:       t.Title,
+		Artist:      t.Artist,
+		Album:       t.Album,
+		AlbumArtist: t.AlbumArtist,
+		TrackNumber: t.TrackNumber,
+		DiscNumber:  t.DiscNumber,
+		Year:        t.Year,
+		Duration:    (time.Duration(ms) * time.Millisecond).Seconds(),
+	}
+}
+
+func newTracksJSON(tracks []*jamsonic.Track) []*trackJSON {
+	a := make([]*trackJSON, len(tracks))
+	for i, t := range tracks {
+		a[i] = newTrackJSON(t)
+	}
+	return a
+}
+
+// newArtistsJSON converts the library. The artist and album are filled in on
+// the tracks if they are missing.
+func newArtistsJSON(artists []*jamsonic.Artist) []*artistJSON {
+	a := make([]*artistJSON, len(artists))
+	for i, artist := range artists {
+		albums := make([]*albumJSON, len(artist.Albums))
+		for j, album := range artist.Albums {
+			tracks := newTracksJSON(album.Tracks)
+			for _, t := range tracks {
+				if t.Artist == "" {
+					t.Artist = artist.Name
+				}
+				if t.Album == "" {
+					t.Album = album.Name
+				}
+			}
+			albums[j] = &albumJSON{
+				ID:     album.ID,
+				Name:   album.Name,
+				Artist: album.Artist,
+				Year:   album.Year,
+				Tracks: tracks,
+			}
+		}
+		a[i] = &artistJSON{ID: artist.ID, Name: artist.Name, Albums: albums}
+	}
+	return a
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package remote implements an HTTP API to control the player. The library,
+// play queue and player state are served as JSON and the player's events are
+// pushed to WebSocket clients.
+//
+// All requests must be authenticated with the token, either with an
+// "Authorization: Bearer TOKEN" header or a "token" query parameter.
+//
+// Endpoints:
+//
+//	GET    /api/library         The cached library.
+//	POST   /api/library/refresh Fetch the library from the provider.
+//	GET    /api/player          The player's state.
+//	POST   /api/player          Send a command to the player.
+//	GET    /api/queue           The current track and the play queue.
+//	POST   /api/queue           Add tracks to the play queue.
+//	DELETE /api/queue           Stop and clear the play queue.
+//	GET    /api/events          WebSocket with player events and commands.
+package remote
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+var (
+	// ErrNoToken is returned by New if no token is given.
+	ErrNoToken = errors.New("a token is required for the remote API")
+	// ErrUnknownCommand is returned for unknown player commands.
+	ErrUnknownCommand = errors.New("unknown command")
+	// ErrTrackNotFound is returned when a track is not in the library.
+	ErrTrackNotFound = errors.New("track not found")
+)
+
+// Server serves the remote API.
+type Server struct {
+	player   *jamsonic.Player
+	store    jamsonic.MusicStore
+	provider jamsonic.Provider
+	logger   *jamsonic.Logger
+	token    []byte
+	http     *http.Server
+
+	// WebSocket connections are hijacked from the HTTP server and have to
+	// be closed by the Server.
+	wsMu    sync.Mutex
+	wsConns map[*wsConn]struct{}
+}
+
+// New returns a new Server for the player. The library is read from the store
+// and refreshed from the provider. Clients must authenticate with the token.
+func New(player *jamsonic.Player, store jamsonic.MusicStore, provider jamsonic.Provider, logger *jamsonic.Logger, token string) (*Server, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	s := &Server{
+		player:   player,
+		store:    store,
+		provider: provider,
+		logger:   logger,
+		token:    []byte(token),
+		wsConns:  make(map[*wsConn]struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/library", s.handleLibrary)
+	mux.HandleFunc("/api/library/refresh", s.handleRefresh)
+	mux.HandleFunc("/api/player", s.handlePlayer)
+	mux.HandleFunc("/api/queue", s.handleQueue)
+	mux.HandleFunc("/api/events", s.handleEvents)
+	s.http = &http.Server{Handler: s.authenticate(mux)}
+	return s, nil
+}
+
+// ListenAndServe listens on the TCP address and serves the API.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves the API on the listener. After Close, http.ErrServerClosed
+// is returned.
+func (s *Server) Serve(l net.Listener) error {
+	s.logger.InfoLog("Remote API listening on " + l.Addr().String())
+	return s.http.Serve(l)
+}
+
+// Close stops the server and closes all connections.
+func (s *Server) Close() error {
+	err := s.http.Close()
+	s.wsMu.Lock()
+	defer s.wsMu.Unlock()
+	for c := range s.wsConns {
+		c.Close()
+	}
+	return err
+}
+
+// authenticate only passes on requests with the right token.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			token = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), s.token) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, &errorJSON{Error: err.Error()})
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// statusForError returns the HTTP status code for errors from the player.
+func statusForError(err error) int {
+	switch err {
+	case jamsonic.ErrSeekNotSupported, jamsonic.ErrVolumeNotSupported:
+		return http.StatusNotImplemented
+	case ErrTrackNotFound:
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (s *Server) handleLibrary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	artists, err := s.store.Artists()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newArtistsJSON(artists))
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if _, err := jamsonic.SyncLibrary(r.Context(), s.store, s.provider); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlePlayer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.state())
+	case http.MethodPost:
+		cmd := new(command)
+		if err := json.NewDecoder(r.Body).Decode(cmd); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.execute(cmd); err != nil {
+			writeError(w, statusForError(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, &queueJSON{
+			Current: newTrackJSON(s.player.CurrentTrack()),
+			Tracks:  newTracksJSON(s.player.Queue()),
+		})
+	case http.MethodPost:
+		req := new(queueRequest)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.enqueue(req); err != nil {
+			writeError(w, statusForError(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.player.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodDelete)
+	}
+}
+
+// handleEvents upgrades the connection to a WebSocket. The player's state is
+// sent when the client connects and after every player event. The client can
+// send commands as JSON text messages.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrade(w, r)
+	if err != nil {
+		s.logger.DebugLog("WebSocket handshake failed: " + err.Error())
+		return
+	}
+	s.wsMu.Lock()
+	s.wsConns[conn] = struct{}{}
+	s.wsMu.Unlock()
+	defer func() {
+		s.wsMu.Lock()
+		delete(s.wsConns, conn)
+		s.wsMu.Unlock()
+		conn.Close()
+	}()
+	s.logger.DebugLog("WebSocket client connected from " + r.RemoteAddr)
+
+	events, cancel := s.player.Subscribe()
+	defer cancel()
+	go s.readCommands(conn, cancel)
+
+	if err := conn.writeJSON(&eventJSON{Type: eventNames[jamsonic.StateChanged], stateJSON: *s.state()}); err != nil {
+		return
+	}
+	for e := range events {
+		msg := &eventJSON{
+			Type: eventNames[e.Type],
+			stateJSON: stateJSON{
+				State:    stateNames[e.State],
+				Track:    newTrackJSON(e.CurrentTrack),
+				Position: e.Position.Seconds(),
+				Volume:   s.player.Volume(),
+			},
+		}
+		if err := conn.writeJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+// readCommands executes the commands sent by the WebSocket client. When the
+// client disconnects, the event subscription is cancelled.
+func (s *Server) readCommands(conn *wsConn, cancel func()) {
+	defer cancel()
+	for {
+		op, msg, err := conn.readMessage()
+		if err != nil {
+			return
+		}
+		if op != opText {
+			continue
+		}
+		cmd := new(command)
+		err = json.Unmarshal(msg, cmd)
+		if err == nil {
+			err = s.execute(cmd)
+		}
+		if err != nil {
+			conn.writeJSON(&eventJSON{Type: "error", stateJSON: *s.state(), Error: err.Error()})
+		}
+	}
+}
+
+// state returns the player's current state.
+func (s *Server) state() *stateJSON {
+	return &stateJSON{
+		State:    stateNames[s.player.GetCurrentState()],
+		Track:    newTrackJSON(s.player.CurrentTrack()),
+		Position: s.player.Position().Seconds(),
+		Volume:   s.player.Volume(),
+	}
+}
+
+// execute runs the player command.
+func (s *Server) execute(cmd *command) error {
+	switch cmd.Command {
+	case "play":
+		s.player.Play()
+	case "pause":
+		s.player.Pause()
+	case "stop":
+		s.player.Stop()
+	case "next":
+		s.player.Next()
+	case "previous":
+		s.player.Previous()
+	case "seek":
+		return s.player.Seek(time.Duration(cmd.Position * float64(time.Second)))
+	case "volume":
+		return s.player.SetVolume(cmd.Volume)
+	default:
+		return fmt.Errorf("%s: %q", ErrUnknownCommand, cmd.Command)
+	}
+	return nil
+}
+
+// enqueue adds the requested tracks to the play queue. The tracks are looked
+// up in the library.
+func (s *Server) enqueue(req *queueRequest) error {
+	artists, err := s.store.Artists()
+	if err != nil {
+		return err
+	}
+	lib := make(map[string]*jamsonic.Track)
+	for _, artist := range artists {
+		for _, album := range artist.Albums {
+			for _, t := range album.Tracks {
+				lib[t.ID] = t
+			}
+		}
+	}
+	tracks := make([]*jamsonic.Track, len(req.IDs))
+	for i, id := range req.IDs {
+		t, ok := lib[id]
+		if !ok {
+			return ErrTrackNotFound
+		}
+		tracks[i] = t
+	}
+	if req.Replace {
+		s.player.CreatePlayQueue(tracks)
+	} else {
+		s.player.Enqueue(tracks...)
+	}
+	if req.Play {
+		s.player.Play()
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "secret"
+
+func init() {
+	// Set to 0 since it's not needed for testing.
+	jamsonic.BufferingWait = time.Duration(0)
+}
+
+var testArtists = []*jamsonic.Artist{
+	&jamsonic.Artist{
+		Name: "Artist1",
+		ID:   "ar1",
+		Albums: []*jamsonic.Album{
+			&jamsonic.Album{Name: "Album1", Artist: "Artist1", ID: "al1",
+				Tracks: []*jamsonic.Track{
+					&jamsonic.Track{ID: "t1", Title: "Track1", DurationMillis: "2500"},
+					&jamsonic.Track{ID: "t2", Title: "Track2"},
+				},
+			},
+		},
+	},
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, nil, nil, nil, "")
+	assert.Equal(t, ErrNoToken, err)
+}
+
+func TestAPI(t *testing.T) {
+	assert := assert.New(t)
+	player := getPlayer()
+	defer player.Close()
+	store := &mockStore{artists: testArtists}
+	s, err := New(player, store, &mockProvider{}, jamsonic.NewLogger(ioutil.Discard), testToken)
+	require.NoError(t, err)
+	ts := httptest.NewServer(s.http.Handler)
+	defer ts.Close()
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/api/player")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+		resp, err = http.Get(ts.URL + "/api/player?token=wrong")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("library", func(t *testing.T) {
+		var artists []*artistJSON
+		assert.Equal(http.StatusOK, doRequest(t, ts, http.MethodGet, "/api/library", "", &artists))
+		require.Len(t, artists, 1)
+		track := artists[0].Albums[0].Tracks[0]
+		assert.Equal(&trackJSON{ID: "t1", Title: "Track1", Artist: "Artist1", Album: "Album1", Duration: 2.5}, track)
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		assert.Equal(http.StatusNoContent, doRequest(t, ts, http.MethodPost, "/api/queue", `{"ids":["t2","t1"]}`, nil))
+		var queue queueJSON
+		assert.Equal(http.StatusOK, doRequest(t, ts, http.MethodGet, "/api/queue", "", &queue))
+		assert.Nil(queue.Current)
+		require.Len(t, queue.Tracks, 2)
+		assert.Equal("t2", queue.Tracks[0].ID)
+
+		var e errorJSON
+		assert.Equal(http.StatusNotFound, doRequest(t, ts, http.MethodPost, "/api/queue", `{"ids":["x"]}`, &e))
+		assert.Equal(ErrTrackNotFound.Error(), e.Error)
+	})
+
+	t.Run("player", func(t *testing.T) {
+		assert.Equal(http.StatusNoContent, doRequest(t, ts, http.MethodPost, "/api/player", `{"command":"play"}`, nil))
+		var state stateJSON
+		for i := 0; i < 100 && state.Track == nil; i++ {
+			time.Sleep(10 * time.Millisecond)
+			assert.Equal(http.StatusOK, doRequest(t, ts, http.MethodGet, "/api/player", "", &state))
+		}
+		assert.Equal("playing", state.State)
+		assert.Equal("t2", state.Track.ID)
+
+		var e errorJSON
+		assert.Equal(http.StatusBadRequest, doRequest(t, ts, http.MethodPost, "/api/player", `{"command":"dance"}`, &e))
+		assert.Equal(http.StatusNotImplemented, doRequest(t, ts, http.MethodPost, "/api/player", `{"command":"seek"}`, &e))
+		assert.Equal(jamsonic.ErrSeekNotSupported.Error(), e.Error)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		conn, r := dialWebSocket(t, ts)
+		defer conn.Close()
+		var e eventJSON
+		readEvent(t, r, &e)
+		assert.Equal("state", e.Type)
+		assert.Equal("playing", e.State)
+
+		writeClientFrame(t, conn, opText, []byte(`{"command":"volume","volume":30}`))
+		readEvent(t, r, &e)
+		assert.Equal("volume", e.Type)
+		assert.Equal(30, e.Volume)
+
+		writeClientFrame(t, conn, opText, []byte(`{"command":"dance"}`))
+		readEvent(t, r, &e)
+		assert.Equal("error", e.Type)
+		assert.NotEmpty(e.Error)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		assert.Equal(http.StatusNoContent, doRequest(t, ts, http.MethodDelete, "/api/queue", "", nil))
+		var queue queueJSON
+		doRequest(t, ts, http.MethodGet, "/api/queue", "", &queue)
+		assert.Nil(queue.Current)
+		assert.Empty(queue.Tracks)
+	})
+}
+
+func doRequest(t *testing.T, ts *httptest.Server, method, path, body string, v interface{}) int {
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp.StatusCode
+}
+
+func dialWebSocket(t *testing.T, ts *httptest.Server) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+	require.NoError(t, err)
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	_, err = io.WriteString(conn, "GET /api/events?token="+testToken+" HTTP/1.1\r\n"+
+		"Host: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return conn, r
+}
+
+// readEvent reads an unmasked text frame from the server.
+func readEvent(t *testing.T, r *bufio.Reader, v interface{}) {
+	var h [2]byte
+	_, err := io.ReadFull(r, h[:])
+	require.NoError(t, err)
+	require.Equal(t, 0x80|opText, h[0])
+	size := int(h[1])
+	if size == 126 {
+		var b [2]byte
+		io.ReadFull(r, b[:])
+		size = int(b[0])<<8 | int(b[1])
+	}
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, v))
+}
+
+// writeClientFrame writes a masked frame like a client does.
+func writeClientFrame(t *testing.T, w io.Writer, op byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := w.Write(frame)
+	require.NoError(t, err)
+}
+
+func getPlayer() *jamsonic.Player {
+	player := jamsonic.NewPlayer(jamsonic.NewLogger(ioutil.Discard), &mockProvider{}, &mockHandler{errChan: make(chan error)}, nil, 0)
+	go func() {
+		for range player.Error {
+		}
+	}()
+	return player
+}
+
+type mockHandler struct {
+	errChan chan error
+}
+
+func (m *mockHandler) Finished() <-chan struct{} { return nil }
+func (m *mockHandler) Play(io.Reader) error      { return nil }
+func (m *mockHandler) Stop()                     {}
+func (m *mockHandler) Pause()                    {}
+func (m *mockHandler) Continue()                 {}
+func (m *mockHandler) Errors() <-chan error      { return m.errChan }
+func (m *mockHandler) SetVolume(percent int)     {}
+
+type mockProvider struct{}
+
+func (m *mockProvider) ListTracks(ctx context.Context) ([]*jamsonic.Track, error)    { return nil, nil }
+func (m *mockProvider) FetchLibrary(ctx context.Context) ([]*jamsonic.Artist, error) { return nil, nil }
+func (m *mockProvider) GetTrackInfo(context.Context, string) (*jamsonic.Track, error) {
+	return nil, nil
+}
+func (m *mockProvider) ListPlaylists(ctx context.Context) ([]*jamsonic.Playlist, error) {
+	return nil, nil
+}
+func (m *mockProvider) GetProvider() jamsonic.MusicProvider { return jamsonic.SubSonic }
+func (m *mockProvider) ListPlaylistEntries(ctx context.Context) ([]*jamsonic.PlaylistEntry, error) {
+	return nil, nil
+}
+
+func (m *mockProvider) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader(songID)), nil
+}
+
+type mockStore struct {
+	artists []*jamsonic.Artist
+}
+
+func (m *mockStore) AddTracks([]*jamsonic.Track) error { return nil }
+func (m *mockStore) AddPlaylists(context.Context, jamsonic.Provider, []*jamsonic.Playlist, []*jamsonic.PlaylistEntry) error {
+	return nil
+}
+func (m *mockStore) Artists() ([]*jamsonic.Artist, error) { return m.artists, nil }
+func (m *mockStore) SaveArtists(a []*jamsonic.Artist) error {
+	m.artists = a
+	return nil
+}
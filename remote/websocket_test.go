@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package remote
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMessage(t *testing.T) {
+	assert := assert.New(t)
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	c := &wsConn{conn: server, r: bufio.NewReader(server)}
+
+	t.Run("fragmented_with_ping", func(t *testing.T) {
+		go func() {
+			// First fragment without the fin bit.
+			frame := []byte{opText, 0x80 | 3, 0, 0, 0, 0, 'a', 'b', 'c'}
+			client.Write(frame)
+			writeClientFrame(t, client, opPing, []byte("p"))
+			writeClientFrame(t, client, opContinuation, []byte("def"))
+		}()
+		// Read the pong while the message is read.
+		pong := make(chan []byte)
+		go func() {
+			buf := make([]byte, 3)
+			io.ReadFull(client, buf)
+			pong <- buf
+		}()
+		op, msg, err := c.readMessage()
+		require.NoError(t, err)
+		assert.Equal(opText, op)
+		assert.Equal("abcdef", string(msg))
+		assert.Equal([]byte{0x80 | opPong, 1, 'p'}, <-pong)
+	})
+
+	t.Run("unmasked", func(t *testing.T) {
+		go client.Write([]byte{0x80 | opText, 1, 'a'})
+		_, _, err := c.readMessage()
+		assert.Equal(errProtocol, err)
+	})
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455.
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package jamsonic
+
+import "context"
+
+// SearchResult holds the artists, albums and tracks matching a search.
+// The artists and albums may be returned without their albums and tracks.
+type SearchResult struct {
+	Artists []*Artist
+	Albums  []*Album
+	Tracks  []*Track
+}
+
+// Len returns the total number of results.
+func (r *SearchResult) Len() int {
+	return len(r.Artists) + len(r.Albums) + len(r.Tracks)
+}
+
+// Searcher is implemented by providers that can search the library on
+// the server.
+type Searcher interface {
+	// Search returns up to count artists, albums and tracks matching the
+	// query. Offset is used to page through the results, it's applied to
+	// each kind of result.
+	Search(ctx context.Context, query string, count, offset int) (*SearchResult, error)
+}
+
+// LibrarySearcher is implemented by stores that can search the cached
+// library.
+type LibrarySearcher interface {
+	// SearchLibrary returns the artists, albums and tracks matching the
+	// query.
+	SearchLibrary(query string) (*SearchResult, error)
+}
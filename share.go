@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"time"
+)
+
+// Share is a public link to tracks on the server, for people without an
+// account.
+type Share struct {
+	// ID is the share's ID.
+	ID string
+	// URL is the public link.
+	URL string
+	// Description describes the share.
+	Description string
+	// Username is the user who created the share.
+	Username string
+	// Created is when the share was created.
+	Created time.Time
+	// Expires is when the link stops working. It's zero if it never
+	// expires.
+	Expires time.Time
+	// LastVisited is when the link was last opened, or zero if it hasn't
+	// been opened.
+	LastVisited time.Time
+	// VisitCount is how many times the link has been opened.
+	VisitCount int
+	// Tracks are the shared tracks.
+	Tracks []*Track
+}
+
+// ShareProvider is implemented by providers that can share tracks with
+// public links.
+type ShareProvider interface {
+	// Shares returns the user's shares.
+	Shares(ctx context.Context) ([]*Share, error)
+	// CreateShare creates a link to the tracks. A zero expires means the
+	// link never expires.
+	CreateShare(ctx context.Context, trackIDs []string, description string, expires time.Time) (*Share, error)
+	// UpdateShare changes the description and the expiry of the share.
+	UpdateShare(ctx context.Context, id, description string, expires time.Time) error
+	// DeleteShare revokes the share so the link stops working.
+	DeleteShare(ctx context.Context, id string) error
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// tmpPrefix is the prefix of images being downloaded.
+const tmpPrefix = ".download"
+
+// CoverArtCacheSize is the default maximum size in bytes of the cover art
+// cache.
+var CoverArtCacheSize int64 = 50 * 1024 * 1024
+
+// CoverArtDir returns the directory where the cover art is cached.
+func CoverArtDir() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "jamsonic", "coverart")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".cache", "jamsonic", "coverart")
+}
+
+// CoverArtCache caches the images from the provider on disk. When the cache
+// grows larger than the max size, the least recently used images are
+// removed.
+type CoverArtCache struct {
+	dir      string
+	maxSize  int64
+	provider jamsonic.CoverArtProvider
+	mu       sync.Mutex
+}
+
+// NewCoverArtCache returns a cache storing the images in the directory.
+// The directory is created if it doesn't exist.
+func NewCoverArtCache(dir string, maxSize int64, provider jamsonic.CoverArtProvider) (*CoverArtCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &CoverArtCache{dir: dir, maxSize: maxSize, provider: provider}, nil
+}
+
+// CoverArt returns the cached image. If the image is not cached, it's
+// fetched from the provider and added to the cache.
+func (c *CoverArtCache) CoverArt(ctx context.Context, id string, size int) (io.ReadCloser, error) {
+	path := c.path(id, size)
+	c.mu.Lock()
+	f, err := os.Open(path)
+	if err == nil {
+		// The modification time is used as the last access time.
+		now := time.Now()
+		os.Chtimes(path, now, now)
+		c.mu.Unlock()
+		return f, nil
+	}
+	c.mu.Unlock()
+
+	r, err := c.provider.CoverArt(ctx, id, size)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// Write to a temporary file first so a failed download is not cached.
+	tmp, err := ioutil.TempFile(c.dir, tmpPrefix)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	c.evict(path)
+	return os.Open(path)
+}
+
+// path returns the file path for the image. IDs are hashed since they can
+// contain characters not allowed in file names.
+func (c *CoverArtCache) path(id string, size int) string {
+	sum := sha1.Sum([]byte(id + "@" + strconv.Itoa(size)))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// evict removes the least recently used images until the cache fits the max
+// size. The image at keep is never removed.
+func (c *CoverArtCache) evict(keep string) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var total int64
+	images := files[:0]
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), tmpPrefix) {
+			continue
+		}
+		images = append(images, f)
+		total += f.Size()
+	}
+	files = images
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, f := range files {
+		if total <= c.maxSize {
+			return
+		}
+		path := filepath.Join(c.dir, f.Name())
+		if path == keep {
+			continue
+		}
+		if os.Remove(path) == nil {
+			total -= f.Size()
+		}
+	}
+}
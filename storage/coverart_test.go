@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCoverArtProvider struct {
+	calls int
+	fail  bool
+}
+
+func (m *mockCoverArtProvider) CoverArt(ctx context.Context, id string, size int) (io.ReadCloser, error) {
+	m.calls++
+	if m.fail {
+		return nil, errors.New("not found")
+	}
+	return ioutil.NopCloser(bytes.NewBufferString("0123456789" + id)), nil
+}
+
+func readCoverArt(t *testing.T, c *CoverArtCache, id string) string {
+	r, err := c.CoverArt(context.Background(), id, 100)
+	require.NoError(t, err)
+	defer r.Close()
+	buf, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestCoverArtCache(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	provider := &mockCoverArtProvider{}
+	// Room for two images.
+	cache, err := NewCoverArtCache(dir, 25, provider)
+	require.NoError(t, err)
+
+	t.Run("cached", func(t *testing.T) {
+		assert.Equal("0123456789a1", readCoverArt(t, cache, "a1"))
+		assert.Equal("0123456789a1", readCoverArt(t, cache, "a1"))
+		assert.Equal(1, provider.calls, "Second read should be served from the cache")
+	})
+
+	t.Run("evict_least_recently_used", func(t *testing.T) {
+		readCoverArt(t, cache, "a2")
+		// Make a2 the least recently used.
+		old := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(cache.path("a2", 100), old, old))
+		readCoverArt(t, cache, "a1")
+		readCoverArt(t, cache, "a3")
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(files, 2)
+		_, err = os.Stat(cache.path("a2", 100))
+		assert.True(os.IsNotExist(err), "a2 should have been evicted")
+		calls := provider.calls
+		readCoverArt(t, cache, "a1")
+		assert.Equal(calls, provider.calls, "a1 should still be cached")
+	})
+
+	t.Run("error_not_cached", func(t *testing.T) {
+		provider.fail = true
+		defer func() { provider.fail = false }()
+		_, err := cache.CoverArt(context.Background(), "a4", 100)
+		assert.Error(err)
+		_, err = os.Stat(cache.path("a4", 100))
+		assert.True(os.IsNotExist(err))
+	})
+}
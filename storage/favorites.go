@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// favoritesBucket has a key per library with the starred items.
+	favoritesBucket = []byte("Favorites")
+)
+
+// Favorites returns the cached starred items. If nothing is cached, an
+// empty Favorites is returned.
+func (d *BoltDB) Favorites() (*jamsonic.Favorites, error) {
+	favorites := new(jamsonic.Favorites)
+	err := d.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(favoritesBucket)
+		if b == nil {
+			return nil
+		}
+		buf := b.Get(d.LibName)
+		if buf == nil {
+			return nil
+		}
+		return json.Unmarshal(buf, favorites)
+	})
+	return favorites, err
+}
+
+// SaveFavorites replaces the cached starred items.
+func (d *BoltDB) SaveFavorites(favorites *jamsonic.Favorites) error {
+	buf, err := json.Marshal(favorites)
+	if err != nil {
+		return err
+	}
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(favoritesBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(d.LibName, buf)
+	})
+}
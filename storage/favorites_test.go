@@ -0,0 +1,55 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavorites(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	favorites, err := db.Favorites()
+	assert.NoError(err)
+	assert.Equal(&jamsonic.Favorites{}, favorites, "Should return empty favorites if nothing is cached")
+
+	expected := &jamsonic.Favorites{
+		Albums: []*jamsonic.Album{&jamsonic.Album{ID: "A1", Starred: true, Rating: 3}},
+		Tracks: []*jamsonic.Track{&jamsonic.Track{ID: "T1", Starred: true}},
+	}
+	require.NoError(t, db.SaveFavorites(expected))
+	favorites, err = db.Favorites()
+	assert.NoError(err)
+	assert.Equal(expected, favorites)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// lyricsBucket has a sub-bucket per library with the lyrics keyed by
+	// track ID.
+	lyricsBucket = []byte("Lyrics")
+)
+
+// Lyrics returns the cached lyrics for the track or nil if the lyrics are
+// not cached.
+func (d *BoltDB) Lyrics(trackID string) (*jamsonic.Lyrics, error) {
+	var lyrics *jamsonic.Lyrics
+	err := d.Bolt.View(func(tx *bolt.Tx) error {
+		mainBucket := tx.Bucket(lyricsBucket)
+		if mainBucket == nil {
+			return nil
+		}
+		b := mainBucket.Bucket(d.LibName)
+		if b == nil {
+			return nil
+		}
+		buf := b.Get([]byte(trackID))
+		if buf == nil {
+			return nil
+		}
+		lyrics = new(jamsonic.Lyrics)
+		return json.Unmarshal(buf, lyrics)
+	})
+	return lyrics, err
+}
+
+// SaveLyrics caches the lyrics for the track.
+func (d *BoltDB) SaveLyrics(trackID string, lyrics *jamsonic.Lyrics) error {
+	buf, err := json.Marshal(lyrics)
+	if err != nil {
+		return err
+	}
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket, err := tx.CreateBucketIfNotExists(lyricsBucket)
+		if err != nil {
+			return err
+		}
+		b, err := mainBucket.CreateBucketIfNotExists(d.LibName)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(trackID), buf)
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLyrics(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	lyrics, err := db.Lyrics("T1")
+	assert.NoError(err)
+	assert.Nil(lyrics, "Should return nil if nothing is cached")
+
+	expected := &jamsonic.Lyrics{Synced: true, Lines: []jamsonic.LyricLine{{Start: time.Second, Text: "one"}}}
+	require.NoError(t, db.SaveLyrics("T1", expected))
+	lyrics, err = db.Lyrics("T1")
+	assert.NoError(err)
+	assert.Equal(expected, lyrics)
+
+	require.NoError(t, db.SaveLyrics("T2", &jamsonic.Lyrics{}))
+	lyrics, err = db.Lyrics("T2")
+	assert.NoError(err)
+	assert.Equal(&jamsonic.Lyrics{}, lyrics, "Tracks without lyrics should be cached")
+}
@@ -19,12 +19,14 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
 	"github.com/boltdb/bolt"
@@ -40,7 +42,7 @@ var (
 )
 
 // AddPlaylists stores the playlists in the database.
-func (d *BoltDB) AddPlaylists(provider jamsonic.Provider, playlists []*jamsonic.Playlist, entries []*jamsonic.PlaylistEntry) error {
+func (d *BoltDB) AddPlaylists(ctx context.Context, provider jamsonic.Provider, playlists []*jamsonic.Playlist, entries []*jamsonic.PlaylistEntry) error {
 	db := d.Bolt
 	var pl *bolt.Bucket
 	var track *jamsonic.Track
@@ -56,8 +58,11 @@ func (d *BoltDB) AddPlaylists(provider jamsonic.Provider, playlists []*jamsonic.
 		}
 
 		for _, entry := range entries {
-			track, err = provider.GetTrackInfo(entry.TrackId)
+			track, err = provider.GetTrackInfo(ctx, entry.TrackId)
 			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
 				err = nil
 				continue
 			}
@@ -211,6 +216,75 @@ func (d *BoltDB) SaveArtists(artists []*jamsonic.Artist) error {
 	return err
 }
 
+// UpdateArtists saves the artists and removes the artists with the IDs.
+// Other artists are left as they are.
+func (d *BoltDB) UpdateArtists(artists []*jamsonic.Artist, removed []string) error {
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket, err := tx.CreateBucketIfNotExists(musicLibrary)
+		if err != nil {
+			return err
+		}
+		b, err := mainBucket.CreateBucketIfNotExists(d.LibName)
+		if err != nil {
+			return err
+		}
+		for _, artist := range artists {
+			buf, err := json.Marshal(artist)
+			if err != nil {
+				return err
+			}
+			if err = b.Put([]byte(artist.ID), buf); err != nil {
+				return err
+			}
+		}
+		for _, id := range removed {
+			if err = b.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LibrarySyncTime returns the server's time of the last sync of the library,
+// or the zero time if it hasn't been synced.
+func (d *BoltDB) LibrarySyncTime() (time.Time, error) {
+	var t time.Time
+	buf, err := d.setting(d.libraryKey("librarySync:"))
+	if err != nil || buf == nil {
+		return t, err
+	}
+	err = t.UnmarshalText(buf)
+	return t, err
+}
+
+// SaveLibrarySyncTime saves the server's time of the sync of the library.
+func (d *BoltDB) SaveLibrarySyncTime(t time.Time) error {
+	buf, err := t.MarshalText()
+	if err != nil {
+		return err
+	}
+	return d.saveSetting(d.libraryKey("librarySync:"), buf)
+}
+
+// libraryKey returns the settings key with the prefix for the library's own
+// setting.
+func (d *BoltDB) libraryKey(prefix string) []byte {
+	return append([]byte(prefix), d.LibName...)
+}
+
+// MusicFolder returns the ID of the library's selected music folder, or an
+// empty string if all folders are used.
+func (d *BoltDB) MusicFolder() (string, error) {
+	buf, err := d.setting(d.libraryKey("musicFolder:"))
+	return string(buf), err
+}
+
+// SaveMusicFolder saves the ID of the library's selected music folder.
+func (d *BoltDB) SaveMusicFolder(id string) error {
+	return d.saveSetting(d.libraryKey("musicFolder:"), []byte(id))
+}
+
 // GetTracks returns the tracks. This code was moved from
 // the UI package.
 // This code is depricated.
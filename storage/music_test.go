@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
 	"github.com/boltdb/bolt"
@@ -110,4 +111,34 @@ func TestArtists(t *testing.T) {
 		assert.Equal(artist2.Albums[0].Tracks[1], actualArtists[1].Albums[0].Tracks[1], "Should return same track values")
 		assert.Equal(artist2.Albums[0].Tracks[2], actualArtists[1].Albums[0].Tracks[2], "Should return same track values")
 	})
+
+	t.Run("update_artists", func(t *testing.T) {
+		artist3 := &jamsonic.Artist{Name: "Artist3", ID: "Artist3"}
+		changed := &jamsonic.Artist{Name: "Artist2 renamed", ID: "Artist2"}
+		assert.NoError(db.UpdateArtists([]*jamsonic.Artist{changed, artist3}, []string{"Artist1"}))
+		actualArtists, err := db.Artists()
+		assert.NoError(err)
+		assert.Equal([]*jamsonic.Artist{changed, artist3}, actualArtists, "Should only update the given artists")
+	})
+
+	t.Run("sync_time", func(t *testing.T) {
+		synced, err := db.LibrarySyncTime()
+		assert.NoError(err)
+		assert.True(synced.IsZero(), "Should be zero before the first sync")
+		now := time.Unix(1500000000, 0)
+		assert.NoError(db.SaveLibrarySyncTime(now))
+		synced, err = db.LibrarySyncTime()
+		assert.NoError(err)
+		assert.True(now.Equal(synced))
+	})
+
+	t.Run("music_folder", func(t *testing.T) {
+		folder, err := db.MusicFolder()
+		assert.NoError(err)
+		assert.Equal("", folder, "Should use all folders by default")
+		assert.NoError(db.SaveMusicFolder("2"))
+		folder, err = db.MusicFolder()
+		assert.NoError(err)
+		assert.Equal("2", folder)
+	})
 }
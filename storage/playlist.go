@@ -0,0 +1,123 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// playlistLibrary is separate from the deprecated "Playlists" bucket
+	// used by the Google Play Music provider.
+	playlistLibrary = []byte("PlaylistLib")
+)
+
+// Playlists returns the stored playlists sorted by name.
+func (d *BoltDB) Playlists() ([]*jamsonic.Playlist, error) {
+	playlists := make([]*jamsonic.Playlist, 0)
+	err := d.Bolt.View(func(tx *bolt.Tx) error {
+		mainBucket := tx.Bucket(playlistLibrary)
+		if mainBucket == nil {
+			return nil
+		}
+		b := mainBucket.Bucket(d.LibName)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k []byte, v []byte) error {
+			var p jamsonic.Playlist
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			playlists = append(playlists, &p)
+			return nil
+		})
+	})
+	sort.SliceStable(playlists, func(i, j int) bool {
+		return playlists[i].Name < playlists[j].Name
+	})
+	return playlists, err
+}
+
+// SavePlaylists replaces the stored playlists.
+func (d *BoltDB) SavePlaylists(playlists []*jamsonic.Playlist) error {
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket, err := tx.CreateBucketIfNotExists(playlistLibrary)
+		if err != nil {
+			return err
+		}
+		// Remove old cached data.
+		if mainBucket.Bucket(d.LibName) != nil {
+			if err = mainBucket.DeleteBucket(d.LibName); err != nil {
+				return err
+			}
+		}
+		b, err := mainBucket.CreateBucket(d.LibName)
+		if err != nil {
+			return err
+		}
+		for _, p := range playlists {
+			if err = putPlaylist(b, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SavePlaylist stores or updates the playlist.
+func (d *BoltDB) SavePlaylist(playlist *jamsonic.Playlist) error {
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket, err := tx.CreateBucketIfNotExists(playlistLibrary)
+		if err != nil {
+			return err
+		}
+		b, err := mainBucket.CreateBucketIfNotExists(d.LibName)
+		if err != nil {
+			return err
+		}
+		return putPlaylist(b, playlist)
+	})
+}
+
+// DeletePlaylist removes the playlist from the store.
+func (d *BoltDB) DeletePlaylist(id string) error {
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket := tx.Bucket(playlistLibrary)
+		if mainBucket == nil {
+			return nil
+		}
+		b := mainBucket.Bucket(d.LibName)
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+func putPlaylist(b *bolt.Bucket, p *jamsonic.Playlist) error {
+	buf, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(p.ID), buf)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaylists(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	t.Run("empty", func(t *testing.T) {
+		pls, err := db.Playlists()
+		assert.NoError(err)
+		assert.Empty(pls)
+	})
+
+	t.Run("save_all", func(t *testing.T) {
+		err := db.SavePlaylists([]*jamsonic.Playlist{
+			&jamsonic.Playlist{ID: "1", Name: "Rock", Tracks: []*jamsonic.Track{&jamsonic.Track{ID: "t1"}}},
+			&jamsonic.Playlist{ID: "2", Name: "Jazz"},
+		})
+		require.NoError(t, err)
+		pls, err := db.Playlists()
+		require.NoError(t, err)
+		require.Len(t, pls, 2)
+		assert.Equal("Jazz", pls[0].Name, "Playlists should be sorted by name")
+		assert.Equal("t1", pls[1].Tracks[0].ID)
+
+		require.NoError(t, db.SavePlaylists([]*jamsonic.Playlist{&jamsonic.Playlist{ID: "3", Name: "Pop"}}))
+		pls, _ = db.Playlists()
+		assert.Len(pls, 1, "Old playlists should be removed")
+	})
+
+	t.Run("save_and_delete", func(t *testing.T) {
+		require.NoError(t, db.SavePlaylist(&jamsonic.Playlist{ID: "3", Name: "Pop hits"}))
+		require.NoError(t, db.SavePlaylist(&jamsonic.Playlist{ID: "4", Name: "Blues"}))
+		pls, _ := db.Playlists()
+		require.Len(t, pls, 2)
+		assert.Equal("Pop hits", pls[1].Name)
+
+		require.NoError(t, db.DeletePlaylist("4"))
+		pls, _ = db.Playlists()
+		require.Len(t, pls, 1)
+		assert.Equal("3", pls[0].ID)
+	})
+}
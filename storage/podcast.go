@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// podcastBucket has a sub-bucket per library with the progress of the
+	// podcast episodes keyed by episode ID.
+	podcastBucket = []byte("PodcastProgress")
+)
+
+// PodcastProgress returns the stored progress of the episodes.
+func (d *BoltDB) PodcastProgress() (map[string]*jamsonic.EpisodeProgress, error) {
+	progress := make(map[string]*jamsonic.EpisodeProgress)
+	err := d.Bolt.View(func(tx *bolt.Tx) error {
+		mainBucket := tx.Bucket(podcastBucket)
+		if mainBucket == nil {
+			return nil
+		}
+		b := mainBucket.Bucket(d.LibName)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k []byte, v []byte) error {
+			var p jamsonic.EpisodeProgress
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			progress[string(k)] = &p
+			return nil
+		})
+	})
+	return progress, err
+}
+
+// SavePodcastProgress stores the progress of the episode.
+func (d *BoltDB) SavePodcastProgress(episodeID string, progress *jamsonic.EpisodeProgress) error {
+	buf, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		mainBucket, err := tx.CreateBucketIfNotExists(podcastBucket)
+		if err != nil {
+			return err
+		}
+		b, err := mainBucket.CreateBucketIfNotExists(d.LibName)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(episodeID), buf)
+	})
+}
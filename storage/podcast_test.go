@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodcastProgress(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	progress, err := db.PodcastProgress()
+	assert.NoError(err)
+	assert.Empty(progress)
+
+	require.NoError(t, db.SavePodcastProgress("E1", &jamsonic.EpisodeProgress{Position: time.Minute}))
+	require.NoError(t, db.SavePodcastProgress("E2", &jamsonic.EpisodeProgress{Played: true}))
+	require.NoError(t, db.SavePodcastProgress("E1", &jamsonic.EpisodeProgress{Position: 2 * time.Minute}))
+	progress, err = db.PodcastProgress()
+	assert.NoError(err)
+	assert.Equal(map[string]*jamsonic.EpisodeProgress{
+		"E1": &jamsonic.EpisodeProgress{Position: 2 * time.Minute},
+		"E2": &jamsonic.EpisodeProgress{Played: true},
+	}, progress)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"strings"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// SearchLibrary searches the cached library. See Search.
+func (d *BoltDB) SearchLibrary(query string) (*jamsonic.SearchResult, error) {
+	artists, err := d.Artists()
+	if err != nil {
+		return nil, err
+	}
+	return Search(artists, query), nil
+}
+
+// Search returns the artists, albums and tracks with a name containing
+// all the words in the query, ignoring case. The artist and album are
+// filled in on the returned tracks.
+//
+// It's cheap enough to call on every key press, which makes it suitable
+// for incremental search.
+func Search(artists []*jamsonic.Artist, query string) *jamsonic.SearchResult {
+	result := &jamsonic.SearchResult{}
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return result
+	}
+	for _, artist := range artists {
+		if matchesAll(artist.Name, words) {
+			result.Artists = append(result.Artists, artist)
+		}
+		for _, album := range artist.Albums {
+			if matchesAll(album.Name, words) {
+				result.Albums = append(result.Albums, album)
+			}
+			for _, tr := range album.Tracks {
+				if matchesAll(tr.Title, words) {
+					if tr.Artist == "" {
+						tr.Artist = artist.Name
+					}
+					if tr.Album == "" {
+						tr.Album = album.Name
+					}
+					result.Tracks = append(result.Tracks, tr)
+				}
+			}
+		}
+	}
+	return result
+}
+
+func matchesAll(s string, words []string) bool {
+	s = strings.ToLower(s)
+	for _, w := range words {
+		if !strings.Contains(s, w) {
+			return false
+		}
+	}
+	return true
+}
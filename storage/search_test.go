@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	assert := assert.New(t)
+	artists := []*jamsonic.Artist{
+		&jamsonic.Artist{Name: "The Blue Band", Albums: []*jamsonic.Album{
+			&jamsonic.Album{Name: "Blue Skies", Tracks: []*jamsonic.Track{
+				&jamsonic.Track{Title: "Sky High"},
+				&jamsonic.Track{Title: "Blue Monday"},
+			}},
+		}},
+		&jamsonic.Artist{Name: "Red"},
+	}
+
+	t.Run("empty_query", func(t *testing.T) {
+		assert.Equal(0, Search(artists, "  ").Len())
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		res := Search(artists, "BLUE")
+		assert.Len(res.Artists, 1)
+		assert.Len(res.Albums, 1)
+		assert.Len(res.Tracks, 1)
+		assert.Equal("The Blue Band", res.Tracks[0].Artist)
+		assert.Equal("Blue Skies", res.Tracks[0].Album)
+	})
+
+	t.Run("all_words", func(t *testing.T) {
+		res := Search(artists, "sky hi")
+		assert.Equal(1, res.Len())
+		assert.Equal("Sky High", res.Tracks[0].Title)
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package storage
+
+import (
+	"encoding/json"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// settingsBucket holds the settings shared by all libraries.
+	settingsBucket = []byte("Settings")
+	// streamProfilesKey is the key for the stream profiles.
+	streamProfilesKey = []byte("streamProfiles")
+	// activeStreamProfileKey is the key for the name of the active stream
+	// profile.
+	activeStreamProfileKey = []byte("activeStreamProfile")
+)
+
+// StreamProfiles returns the saved stream profiles or nil if none have been
+// saved.
+func (d *BoltDB) StreamProfiles() ([]*jamsonic.StreamProfile, error) {
+	var profiles []*jamsonic.StreamProfile
+	buf, err := d.setting(streamProfilesKey)
+	if err != nil || buf == nil {
+		return nil, err
+	}
+	err = json.Unmarshal(buf, &profiles)
+	return profiles, err
+}
+
+// SaveStreamProfiles replaces the saved stream profiles.
+func (d *BoltDB) SaveStreamProfiles(profiles []*jamsonic.StreamProfile) error {
+	buf, err := json.Marshal(profiles)
+	if err != nil {
+		return err
+	}
+	return d.saveSetting(streamProfilesKey, buf)
+}
+
+// ActiveStreamProfile returns the name of the active stream profile or an
+// empty string if none has been saved.
+func (d *BoltDB) ActiveStreamProfile() (string, error) {
+	buf, err := d.setting(activeStreamProfileKey)
+	return string(buf), err
+}
+
+// SaveActiveStreamProfile saves the name of the active stream profile.
+func (d *BoltDB) SaveActiveStreamProfile(name string) error {
+	return d.saveSetting(activeStreamProfileKey, []byte(name))
+}
+
+// setting returns a copy of the setting or nil if it's not saved.
+func (d *BoltDB) setting(key []byte) ([]byte, error) {
+	var buf []byte
+	err := d.Bolt.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(settingsBucket)
+		if b == nil {
+			return nil
+		}
+		if v := b.Get(key); v != nil {
+			buf = append([]byte{}, v...)
+		}
+		return nil
+	})
+	return buf, err
+}
+
+func (d *BoltDB) saveSetting(key, value []byte) error {
+	return d.Bolt.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(settingsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, value)
+	})
+}
+
+// Output returns the name of the library's output, or jamsonic.LocalOutput
+// if none has been saved.
+func (d *BoltDB) Output() (string, error) {
+	buf, err := d.setting(d.libraryKey("output:"))
+	if len(buf) == 0 {
+		return jamsonic.LocalOutput, err
+	}
+	return string(buf), err
+}
+
+// SaveOutput saves the name of the library's output.
+func (d *BoltDB) SaveOutput(name string) error {
+	return d.saveSetting(d.libraryKey("output:"), []byte(name))
+}
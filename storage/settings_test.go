@@ -0,0 +1,88 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamProfiles(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	profiles, err := db.StreamProfiles()
+	assert.NoError(err)
+	assert.Nil(profiles, "Should return nil if nothing is saved")
+	name, err := db.ActiveStreamProfile()
+	assert.NoError(err)
+	assert.Equal("", name)
+
+	expected := []*jamsonic.StreamProfile{
+		{Name: "lan", StreamSettings: jamsonic.StreamSettings{Format: jamsonic.RawFormat}},
+		{Name: "mobile", StreamSettings: jamsonic.StreamSettings{Format: "opus", MaxBitRate: 96}},
+	}
+	require.NoError(t, db.SaveStreamProfiles(expected))
+	require.NoError(t, db.SaveActiveStreamProfile("mobile"))
+	profiles, err = db.StreamProfiles()
+	assert.NoError(err)
+	assert.Equal(expected, profiles)
+	name, err = db.ActiveStreamProfile()
+	assert.NoError(err)
+	assert.Equal("mobile", name)
+}
+
+func TestOutput(t *testing.T) {
+	assert := assert.New(t)
+	f, err := ioutil.TempFile(os.TempDir(), "jamsonic-test")
+	require.NoError(t, err)
+	fileName := f.Name()
+	f.Close()
+	defer os.Remove(fileName)
+	b, err := bolt.Open(fileName, 0600, nil)
+	require.NoError(t, err)
+	defer b.Close()
+	db := &BoltDB{Bolt: b, LibName: []byte("testLibrary")}
+
+	output, err := db.Output()
+	assert.NoError(err)
+	assert.Equal(jamsonic.LocalOutput, output, "Should default to the local output")
+
+	require.NoError(t, db.SaveOutput(jamsonic.JukeboxOutput))
+	output, err = db.Output()
+	assert.NoError(err)
+	assert.Equal(jamsonic.JukeboxOutput, output)
+
+	db.LibName = []byte("otherLibrary")
+	output, err = db.Output()
+	assert.NoError(err)
+	assert.Equal(jamsonic.LocalOutput, output, "The output should be saved per library")
+}
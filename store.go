@@ -17,7 +17,11 @@
 
 package jamsonic
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 var (
 	// ErrNoCredentialsStored is returned if the backend does not have any
@@ -32,13 +36,25 @@ type MusicStore interface {
 	AddTracks([]*Track) error
 	// AddPlaylists stores the playlists to the database. This methods is
 	// deprecated and should not be used by new implementations.
-	AddPlaylists(Provider, []*Playlist, []*PlaylistEntry) error
+	AddPlaylists(context.Context, Provider, []*Playlist, []*PlaylistEntry) error
 	// Artists returns the stored artists from the database.
 	Artists() ([]*Artist, error)
 	// SaveArtists saves the artists to the database.
 	SaveArtists(artists []*Artist) error
 }
 
+// PlaylistStore is implemented by stores that can cache the playlists.
+type PlaylistStore interface {
+	// Playlists returns the stored playlists, including their tracks.
+	Playlists() ([]*Playlist, error)
+	// SavePlaylists replaces the stored playlists.
+	SavePlaylists(playlists []*Playlist) error
+	// SavePlaylist stores or updates the playlist.
+	SavePlaylist(playlist *Playlist) error
+	// DeletePlaylist removes the playlist from the store.
+	DeletePlaylist(id string) error
+}
+
 // AuthStore is the interface for databases which handles credentials.
 type AuthStore interface {
 	// GetCredentials gets the credentials from the database.
@@ -46,3 +62,16 @@ type AuthStore interface {
 	// SaveCredentials saves the credentials to the database.
 	SaveCredentials(key []byte, credStruct []byte) error
 }
+
+// LibrarySyncStore is implemented by stores that can update single artists
+// in the library, so only the changes have to be written after a sync.
+type LibrarySyncStore interface {
+	// UpdateArtists saves the artists and removes the artists with the
+	// IDs. Other artists are left as they are.
+	UpdateArtists(artists []*Artist, removed []string) error
+	// LibrarySyncTime returns the server's time of the last sync, or the
+	// zero time if the library hasn't been synced.
+	LibrarySyncTime() (time.Time, error)
+	// SaveLibrarySyncTime saves the server's time of the sync.
+	SaveLibrarySyncTime(t time.Time) error
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// RawFormat asks the server to stream the original file without
+// transcoding it.
+const RawFormat = "raw"
+
+// ErrUnknownStreamProfile is returned if no stream profile has the name.
+var ErrUnknownStreamProfile = errors.New("unknown stream profile")
+
+// DefaultStreamProfiles are used if no profiles have been saved. The first
+// profile is the default.
+var DefaultStreamProfiles = []*StreamProfile{
+	{Name: "default: mp3", StreamSettings: StreamSettings{Format: "mp3"}},
+	{Name: "home LAN: original", StreamSettings: StreamSettings{Format: RawFormat}},
+	{Name: "tethered: mp3 128", StreamSettings: StreamSettings{Format: "mp3", MaxBitRate: 128}},
+}
+
+// StreamSettings controls how the server encodes the streams.
+type StreamSettings struct {
+	// Format is the format the server transcodes the stream to. RawFormat
+	// or an empty string streams the original file.
+	Format string
+	// MaxBitRate is the highest bit rate in kbps. Zero means no limit.
+	MaxBitRate int
+	// TimeOffset is where in the track the stream starts. Servers usually
+	// only support it for transcoded streams.
+	TimeOffset time.Duration
+}
+
+// StreamProfile is a named set of stream settings, for example for a
+// network.
+type StreamProfile struct {
+	Name string
+	StreamSettings
+}
+
+// StreamConfigurer is implemented by providers that can transcode the
+// streams.
+type StreamConfigurer interface {
+	// SetStreamSettings changes the settings used by the following stream
+	// requests.
+	SetStreamSettings(settings StreamSettings)
+	// GetStreamAt returns a stream of the track starting at the offset.
+	GetStreamAt(ctx context.Context, songID string, offset time.Duration) (io.ReadCloser, error)
+}
+
+// StreamProfileStore is implemented by stores that can save the stream
+// profiles.
+type StreamProfileStore interface {
+	// StreamProfiles returns the saved profiles or nil if none have been
+	// saved.
+	StreamProfiles() ([]*StreamProfile, error)
+	// SaveStreamProfiles replaces the saved profiles.
+	SaveStreamProfiles(profiles []*StreamProfile) error
+	// ActiveStreamProfile returns the name of the active profile or an
+	// empty string if none has been saved.
+	ActiveStreamProfile() (string, error)
+	// SaveActiveStreamProfile saves the name of the active profile.
+	SaveActiveStreamProfile(name string) error
+}
+
+// GetStreamProfiles returns the saved profiles, or the default profiles if
+// none have been saved, and the active profile.
+func GetStreamProfiles(db StreamProfileStore) ([]*StreamProfile, *StreamProfile, error) {
+	profiles, err := db.StreamProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(profiles) == 0 {
+		profiles = DefaultStreamProfiles
+	}
+	name, err := db.ActiveStreamProfile()
+	if err != nil {
+		return nil, nil, err
+	}
+	active := FindStreamProfile(profiles, name)
+	if active == nil {
+		active = profiles[0]
+	}
+	return profiles, active, nil
+}
+
+// FindStreamProfile returns the profile with the name or nil if it's not
+// found.
+func FindStreamProfile(profiles []*StreamProfile, name string) *StreamProfile {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// ActivateStreamProfile makes the named profile active. The choice is saved
+// and the provider uses the profile for the next stream request.
+func ActivateStreamProfile(db StreamProfileStore, provider StreamConfigurer, name string) error {
+	profiles, _, err := GetStreamProfiles(db)
+	if err != nil {
+		return err
+	}
+	profile := FindStreamProfile(profiles, name)
+	if profile == nil {
+		return ErrUnknownStreamProfile
+	}
+	if err := db.SaveActiveStreamProfile(name); err != nil {
+		return err
+	}
+	provider.SetStreamSettings(profile.StreamSettings)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package jamsonic
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStreamProfileStore struct {
+	profiles []*StreamProfile
+	active   string
+}
+
+func (m *mockStreamProfileStore) StreamProfiles() ([]*StreamProfile, error) {
+	return m.profiles, nil
+}
+
+func (m *mockStreamProfileStore) SaveStreamProfiles(profiles []*StreamProfile) error {
+	m.profiles = profiles
+	return nil
+}
+
+func (m *mockStreamProfileStore) ActiveStreamProfile() (string, error) {
+	return m.active, nil
+}
+
+func (m *mockStreamProfileStore) SaveActiveStreamProfile(name string) error {
+	m.active = name
+	return nil
+}
+
+type mockStreamConfigurer struct {
+	settings StreamSettings
+}
+
+func (m *mockStreamConfigurer) SetStreamSettings(settings StreamSettings) {
+	m.settings = settings
+}
+
+func (m *mockStreamConfigurer) GetStreamAt(ctx context.Context, songID string, offset time.Duration) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func TestStreamProfiles(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		profiles, active, err := GetStreamProfiles(&mockStreamProfileStore{})
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultStreamProfiles, profiles)
+		assert.Equal(t, DefaultStreamProfiles[0], active, "The first profile should be the default")
+	})
+	t.Run("activate", func(t *testing.T) {
+		db := &mockStreamProfileStore{}
+		provider := &mockStreamConfigurer{}
+		assert.NoError(t, ActivateStreamProfile(db, provider, "tethered: mp3 128"))
+		assert.Equal(t, "tethered: mp3 128", db.active, "The choice should be saved")
+		assert.Equal(t, StreamSettings{Format: "mp3", MaxBitRate: 128}, provider.settings)
+		_, active, err := GetStreamProfiles(db)
+		assert.NoError(t, err)
+		assert.Equal(t, "tethered: mp3 128", active.Name)
+	})
+	t.Run("unknown", func(t *testing.T) {
+		db := &mockStreamProfileStore{active: "lan"}
+		assert.Equal(t, ErrUnknownStreamProfile, ActivateStreamProfile(db, &mockStreamConfigurer{}, "missing"))
+		assert.Equal(t, "lan", db.active, "The active profile should not change")
+	})
+	t.Run("saved", func(t *testing.T) {
+		custom := &StreamProfile{Name: "custom", StreamSettings: StreamSettings{Format: "opus"}}
+		db := &mockStreamProfileStore{profiles: []*StreamProfile{custom}, active: "gone"}
+		profiles, active, err := GetStreamProfiles(db)
+		assert.NoError(t, err)
+		assert.Equal(t, []*StreamProfile{custom}, profiles)
+		assert.Equal(t, custom, active, "An unknown active profile should fall back to the first")
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// ErrUnknownListType is returned for album list types not supported by the API.
+var ErrUnknownListType = errors.New("unknown album list type")
+
+// listTypes maps the album list types to the API's names.
+var listTypes = map[jamsonic.AlbumListType]string{
+	jamsonic.RecentAlbums:       "recent",
+	jamsonic.FrequentAlbums:     "frequent",
+	jamsonic.NewestAlbums:       "newest",
+	jamsonic.RandomAlbums:       "random",
+	jamsonic.HighestRatedAlbums: "highest",
+	jamsonic.StarredAlbums:      "starred",
+	jamsonic.AlbumsByName:       "alphabeticalByName",
+	jamsonic.AlbumsByArtist:     "alphabeticalByArtist",
+	jamsonic.AlbumsByYear:       "byYear",
+	jamsonic.AlbumsByGenre:      "byGenre",
+}
+
+// AlbumList returns the albums in the list using getAlbumList2. The server
+// returns at most 500 albums per request.
+func (c *Client) AlbumList(ctx context.Context, query *jamsonic.AlbumListQuery) ([]*jamsonic.Album, error) {
+	listType, ok := listTypes[query.Type]
+	if !ok {
+		return nil, ErrUnknownListType
+	}
+	u := c.makeRequestURL("getAlbumList2") + "&type=" + listType +
+		"&size=" + strconv.Itoa(query.Count) + "&offset=" + strconv.Itoa(query.Offset) +
+		c.musicFolderParam()
+	switch query.Type {
+	case jamsonic.AlbumsByYear:
+		u += "&fromYear=" + strconv.Itoa(query.FromYear) + "&toYear=" + strconv.Itoa(query.ToYear)
+	case jamsonic.AlbumsByGenre:
+		u += "&genre=" + url.QueryEscape(query.Genre)
+	}
+	data, err := c.sendRequest(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	albums := make([]*jamsonic.Album, len(data.AlbumList.Albums))
+	for i, a := range data.AlbumList.Albums {
+		albums[i] = newAlbum(a)
+	}
+	return albums, nil
+}
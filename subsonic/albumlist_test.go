@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlbumList(t *testing.T) {
+	assert := assert.New(t)
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", AlbumList: albumList{
+			Albums: []*album{&album{ID: "AA1", Name: "Album1", Artist: "Artist1", Year: 1999}},
+		}}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	t.Run("newest", func(t *testing.T) {
+		albums, err := c.AlbumList(context.Background(), &jamsonic.AlbumListQuery{Type: jamsonic.NewestAlbums, Count: 10, Offset: 20})
+		require.NoError(t, err)
+		require.Len(t, albums, 1)
+		assert.Equal("Artist1", albums[0].Artist)
+		assert.Equal("newest", query.Get("type"))
+		assert.Equal("10", query.Get("size"))
+		assert.Equal("20", query.Get("offset"))
+	})
+
+	t.Run("by_year", func(t *testing.T) {
+		_, err := c.AlbumList(context.Background(), &jamsonic.AlbumListQuery{Type: jamsonic.AlbumsByYear, FromYear: 1990, ToYear: 1999})
+		require.NoError(t, err)
+		assert.Equal("byYear", query.Get("type"))
+		assert.Equal("1990", query.Get("fromYear"))
+		assert.Equal("1999", query.Get("toYear"))
+	})
+
+	t.Run("by_genre", func(t *testing.T) {
+		_, err := c.AlbumList(context.Background(), &jamsonic.AlbumListQuery{Type: jamsonic.AlbumsByGenre, Genre: "Rock & Roll"})
+		require.NoError(t, err)
+		assert.Equal("Rock & Roll", query.Get("genre"))
+	})
+
+	t.Run("unknown_type", func(t *testing.T) {
+		_, err := c.AlbumList(context.Background(), &jamsonic.AlbumListQuery{Type: jamsonic.AlbumListType(-1)})
+		assert.Equal(ErrUnknownListType, err)
+	})
+}
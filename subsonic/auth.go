@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/satori/go.uuid"
+)
+
+// AuthMethod is how the client authenticates with the server.
+type AuthMethod string
+
+const (
+	// TokenAuth sends a salted MD5 token of the password.
+	TokenAuth AuthMethod = "token"
+	// PasswordAuth sends the hex encoded password. It's used with servers
+	// that can't check tokens, for example if the users are in LDAP.
+	PasswordAuth AuthMethod = "password"
+	// APIKeyAuth sends an API key. It's an OpenSubsonic extension.
+	APIKeyAuth AuthMethod = "apiKey"
+)
+
+// secretParams are the query parameters hidden in errors.
+var secretParams = []string{"t", "s", "p", "apiKey"}
+
+// LoginWithPassword authenticates with the server by sending the hex encoded
+// password instead of a token.
+func LoginWithPassword(username, password, host string) (*Client, error) {
+	c := passwordClient(username, password, host)
+	if err := c.Ping(context.Background()); err != nil {
+		return nil, loginError(err)
+	}
+	return c, nil
+}
+
+// LoginWithAPIKey authenticates with the server using an API key.
+func LoginWithAPIKey(apiKey, host string) (*Client, error) {
+	c := &Client{
+		Credentials: Credentials{
+			Host:       host,
+			AuthMethod: APIKeyAuth,
+			APIKey:     apiKey,
+		},
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		return nil, loginError(err)
+	}
+	return c, nil
+}
+
+// DetectAuthMethod returns the authentication method preferred by the
+// server. APIKeyAuth is returned if the server has the OpenSubsonic API key
+// extension. Otherwise TokenAuth is returned, and Login falls back to
+// PasswordAuth if the server rejects tokens.
+func DetectAuthMethod(host string) (AuthMethod, error) {
+	c := &Client{Credentials: Credentials{Host: host}}
+	extensions, err := c.extensions(context.Background())
+	if err != nil {
+		return "", err
+	}
+	for _, ext := range extensions {
+		if ext.Name == string(jamsonic.APIKeyAuthCapability) {
+			return APIKeyAuth, nil
+		}
+	}
+	return TokenAuth, nil
+}
+
+func tokenClient(username, password, host string) (*Client, error) {
+	randomUUID, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	salt := randomUUID.String()
+	hasher := md5.New()
+	hasher.Write([]byte(password + salt))
+	return &Client{
+		Credentials: Credentials{
+			Username:   username,
+			Host:       host,
+			Token:      hex.EncodeToString(hasher.Sum(nil)),
+			Salt:       salt,
+			AuthMethod: TokenAuth,
+		},
+	}, nil
+}
+
+func passwordClient(username, password, host string) *Client {
+	return &Client{
+		Credentials: Credentials{
+			Username:        username,
+			Host:            host,
+			AuthMethod:      PasswordAuth,
+			EncodedPassword: hex.EncodeToString([]byte(password)),
+		},
+	}
+}
+
+// loginError returns ErrAuthenticationFailed if the credentials were
+// rejected.
+func loginError(err error) error {
+	if err == ErrRequestFailed || IsAPIError(err, CodeWrongCredentials) || IsAPIError(err, CodeInvalidAPIKey) {
+		return ErrAuthenticationFailed
+	}
+	return err
+}
+
+// authParams returns the query parameters for the authentication method.
+func (c *Client) authParams() string {
+	switch c.AuthMethod {
+	case APIKeyAuth:
+		return "apiKey=" + url.QueryEscape(c.APIKey)
+	case PasswordAuth:
+		return "u=" + url.QueryEscape(c.Username) + "&p=enc:" + c.EncodedPassword
+	default:
+		return "u=" + url.QueryEscape(c.Username) + "&t=" + c.Token + "&s=" + c.Salt
+	}
+}
+
+// hideCredentials replaces the secrets in the URL's query with "xxx".
+func hideCredentials(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		// Hide the whole query if the URL can't be parsed.
+		return strings.SplitN(u, "?", 2)[0]
+	}
+	query := parsed.Query()
+	for _, p := range secretParams {
+		if query.Get(p) != "" {
+			query.Set(p, "xxx")
+		}
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthentication(t *testing.T) {
+	assert := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if strings.Contains(r.URL.Path, "getOpenSubsonicExtensions") {
+			writeServerReply(w, &apiData{Response: apiResponse{
+				Status:     "ok",
+				Extensions: []*extension{{Name: "songLyrics"}, {Name: string(jamsonic.APIKeyAuthCapability), Versions: []int{1}}},
+			}})
+			return
+		}
+		switch {
+		case q.Get("t") != "":
+			// Like a server with LDAP users.
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: int(CodeTokenAuthNotSupported)}}})
+		case q.Get("p") == "enc:"+hex.EncodeToString([]byte("secret")) && q.Get("u") == "user":
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		case q.Get("apiKey") == "key" && q.Get("u") == "":
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		case q.Get("apiKey") != "":
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: int(CodeInvalidAPIKey)}}})
+		default:
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: int(CodeWrongCredentials)}}})
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("password_fallback", func(t *testing.T) {
+		c, err := Login("user", "secret", ts.URL)
+		require.NoError(t, err, "Should fall back to the encoded password")
+		assert.Equal(PasswordAuth, c.AuthMethod)
+		assert.Equal(hex.EncodeToString([]byte("secret")), c.EncodedPassword)
+		assert.Equal("", c.Token)
+		_, err = Login("user", "wrong", ts.URL)
+		assert.Equal(ErrAuthenticationFailed, err)
+	})
+	t.Run("password", func(t *testing.T) {
+		c, err := LoginWithPassword("user", "secret", ts.URL)
+		require.NoError(t, err)
+		assert.Contains(c.makeRequestURL("ping"), "p=enc:")
+	})
+	t.Run("api_key", func(t *testing.T) {
+		c, err := LoginWithAPIKey("key", ts.URL)
+		require.NoError(t, err)
+		assert.Equal(APIKeyAuth, c.AuthMethod)
+		_, err = LoginWithAPIKey("bad", ts.URL)
+		assert.Equal(ErrAuthenticationFailed, err)
+	})
+	t.Run("detect", func(t *testing.T) {
+		method, err := DetectAuthMethod(ts.URL)
+		assert.NoError(err)
+		assert.Equal(APIKeyAuth, method)
+
+		plain := httptest.NewServer(http.NotFoundHandler())
+		defer plain.Close()
+		method, err = DetectAuthMethod(plain.URL)
+		assert.NoError(err)
+		assert.Equal(TokenAuth, method, "Should default to tokens without OpenSubsonic")
+	})
+	t.Run("hide_credentials", func(t *testing.T) {
+		c := &Client{Credentials: Credentials{Host: "http://127.0.0.1:1", Username: "user", Token: "token", Salt: "salt"}}
+		_, err := c.sendRequest(context.Background(), c.makeRequestURL("ping"))
+		require.Error(t, err)
+		assert.NotContains(err.Error(), "token")
+		assert.NotContains(err.Error(), "salt")
+		assert.Contains(err.Error(), "u=user")
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// Bookmarks returns the user's bookmarks using getBookmarks.
+func (c *Client) Bookmarks(ctx context.Context) ([]*jamsonic.Bookmark, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getBookmarks"))
+	if err != nil {
+		return nil, err
+	}
+	var list []*jamsonic.Bookmark
+	for _, b := range data.Bookmarks.Bookmarks {
+		if b.Entry == nil {
+			continue
+		}
+		changed, _ := time.Parse(time.RFC3339, b.Changed)
+		list = append(list, &jamsonic.Bookmark{
+			Track:    newTrack(b.Entry),
+			Position: time.Duration(b.Position) * time.Millisecond,
+			Comment:  b.Comment,
+			Changed:  changed,
+		})
+	}
+	return list, nil
+}
+
+// CreateBookmark saves the position in the track using createBookmark. The
+// server replaces the track's bookmark if it has one.
+func (c *Client) CreateBookmark(ctx context.Context, trackID string, position time.Duration, comment string) error {
+	u := c.makeRequestURL("createBookmark") + "&id=" + url.QueryEscape(trackID) +
+		"&position=" + strconv.FormatInt(int64(position/time.Millisecond), 10)
+	if comment != "" {
+		u += "&comment=" + url.QueryEscape(comment)
+	}
+	_, err := c.sendRequest(ctx, u)
+	return err
+}
+
+// DeleteBookmark removes the track's bookmark using deleteBookmark.
+func (c *Client) DeleteBookmark(ctx context.Context, trackID string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("deleteBookmark")+"&id="+url.QueryEscape(trackID))
+	return err
+}
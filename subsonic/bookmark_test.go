@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarks(t *testing.T) {
+	assert := assert.New(t)
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch methodName(r.URL.Path) {
+		case "createBookmark", "deleteBookmark":
+			query = r.URL.Query()
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		case "getBookmarks":
+			w.Write([]byte(`{"subsonic-response":{"status":"ok","bookmarks":{"bookmark":[{"position":5400500,` +
+				`"comment":"Chapter 12","changed":"2018-05-01T10:00:00.000Z","entry":{"id":"7","title":"The Hobbit"}}]}}}`))
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+
+	t.Run("create", func(t *testing.T) {
+		err := c.CreateBookmark(context.Background(), "7", 90*time.Minute+500*time.Millisecond, "Chapter 12")
+		require.NoError(t, err)
+		assert.Equal("7", query.Get("id"))
+		assert.Equal("5400500", query.Get("position"))
+		assert.Equal("Chapter 12", query.Get("comment"))
+	})
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.DeleteBookmark(context.Background(), "7"))
+		assert.Equal("7", query.Get("id"))
+	})
+	t.Run("get", func(t *testing.T) {
+		bookmarks, err := c.Bookmarks(context.Background())
+		require.NoError(t, err)
+		require.Len(t, bookmarks, 1)
+		b := bookmarks[0]
+		assert.Equal("7", b.Track.ID)
+		assert.Equal("The Hobbit", b.Track.Title)
+		assert.Equal(90*time.Minute+500*time.Millisecond, b.Position)
+		assert.Equal("Chapter 12", b.Comment)
+		assert.Equal(time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC), b.Changed)
+	})
+}
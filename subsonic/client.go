@@ -18,20 +18,25 @@
 package subsonic
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/TcM1911/jamsonic"
-	"github.com/satori/go.uuid"
 )
 
 const (
-	apiVersion = "1.13.0"
-	clientName = "Jamsonic"
+	// minAPIVersion is the version used until the server's version is known.
+	// It's the first version with token authentication.
+	minAPIVersion = "1.13.0"
+	// maxAPIVersion is the newest version known by the client.
+	maxAPIVersion = "1.16.1"
+	clientName    = "Jamsonic"
 )
 
 var (
@@ -39,6 +44,9 @@ var (
 	CredentialKey = []byte("subsonicCredsKey")
 	// ErrAuthenticationFailed is returned if authentication with the server failed.
 	ErrAuthenticationFailed = errors.New("authentication failed")
+	// ErrRequestFailed is returned if the server failed the request without
+	// an error message.
+	ErrRequestFailed = errors.New("request failed")
 )
 
 // Client is the Subsonic client which talks to the Subsonic server.
@@ -46,6 +54,21 @@ type Client struct {
 	Credentials
 	lib    []*jamsonic.Artist
 	logger *jamsonic.Logger
+
+	// serverVersion is the API version reported by the server. It's empty
+	// until the server has answered a ping.
+	serverVersion string
+	// capabilities are the server's OpenSubsonic extensions known by the
+	// client. They are fetched by Ping.
+	capabilities []jamsonic.Capability
+	// streamSettings holds the jamsonic.StreamSettings used for the stream
+	// requests. If it's not set, defaultStreamSettings are used.
+	streamSettings atomic.Value
+	// syncProgress holds the progressFunc set by SetSyncProgress.
+	syncProgress atomic.Value
+	// musicFolder holds the ID of the selected music folder. All folders
+	// are used if it's not set or empty.
+	musicFolder atomic.Value
 }
 
 // Credentials is structure for subsonic credentials.
@@ -58,6 +81,13 @@ type Credentials struct {
 	Salt string
 	// Host is the URL to the server.
 	Host string
+	// AuthMethod is how the client authenticates. An empty method is the
+	// same as TokenAuth.
+	AuthMethod AuthMethod
+	// EncodedPassword is the hex encoded password used by PasswordAuth.
+	EncodedPassword string
+	// APIKey is the key used by APIKeyAuth.
+	APIKey string
 }
 
 // New returns a new instance of the Subsonic client. If credentials are stored
@@ -101,40 +131,107 @@ func New(db jamsonic.AuthStore, credRequester jamsonic.CredentialRequester, logg
 		return nil, err
 	}
 	client := Client{Credentials: creds, logger: logger}
+	if err := client.Ping(context.Background()); err != nil {
+		// The default version is used until the server can be reached.
+		logger.ErrorLog("Failed to ping the server: " + err.Error())
+	}
 	return &client, nil
 }
 
+// Login authenticates with the server using the username and password. A
+// salted token of the password is sent. If the server can't use tokens, for
+// example if the users are in LDAP, the hex encoded password is sent instead.
 func Login(username, password, host string) (*Client, error) {
-	hasher := md5.New()
-	randomUUID, err := uuid.NewV4()
+	c, err := tokenClient(username, password, host)
 	if err != nil {
 		return nil, err
 	}
-	salt := randomUUID.String()
-	hasher.Write([]byte(password + salt))
-	c := &Client{
-		Credentials: Credentials{
-			Username: username,
-			Host:     host,
-			Token:    hex.EncodeToString(hasher.Sum(nil)),
-			Salt:     salt,
-		},
-	}
-	url := c.makeRequestURL("ping")
-	res, err := http.Get(url)
+	ctx := context.Background()
+	err = c.Ping(ctx)
+	if IsAPIError(err, CodeTokenAuthNotSupported) || IsAPIError(err, CodeAuthNotSupported) {
+		c = passwordClient(username, password, host)
+		err = c.Ping(ctx)
+	}
 	if err != nil {
-		return nil, err
+		return nil, loginError(err)
+	}
+	return c, nil
+}
+
+// Ping checks the connection to the server and negotiates the API version.
+func (c *Client) Ping(ctx context.Context) error {
+	res, err := c.do(ctx, c.makeRequestURL("ping"))
+	if err != nil {
+		return err
 	}
+	defer res.Body.Close()
 	var data apiData
-	json.NewDecoder(res.Body).Decode(&data)
+	if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return err
+	}
+	// The version is sent even if the request failed.
+	if data.Response.Version != "" {
+		c.serverVersion = data.Response.Version
+	}
+	if err = responseError(&data.Response); err != nil {
+		return err
+	}
 	if data.Response.Status != "ok" {
-		return nil, ErrAuthenticationFailed
+		return ErrRequestFailed
 	}
-	return c, nil
+	c.capabilities = nil
+	if !data.Response.OpenSubsonic {
+		return nil
+	}
+	extensions, err := c.extensions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ext := range extensions {
+		if knownCapabilities[jamsonic.Capability(ext.Name)] {
+			c.capabilities = append(c.capabilities, jamsonic.Capability(ext.Name))
+		}
+	}
+	return nil
+}
+
+// Capabilities returns the server's OpenSubsonic extensions known by the
+// client.
+func (c *Client) Capabilities() []jamsonic.Capability {
+	return c.capabilities
+}
+
+// APIVersion returns the API version used for the requests. It's the
+// server's version, capped to the newest version known by the client.
+func (c *Client) APIVersion() string {
+	if c.serverVersion == "" {
+		return minAPIVersion
+	}
+	if compareVersions(c.serverVersion, maxAPIVersion) > 0 {
+		return maxAPIVersion
+	}
+	return c.serverVersion
 }
 
-func sendRequest(url string) (*apiResponse, error) {
-	res, err := http.Get(url)
+// supports returns an UnsupportedError if the server's API version is too
+// old for the method. If the version isn't known, the method is assumed to
+// be supported.
+func (c *Client) supports(method string) error {
+	required, ok := methodVersions[method]
+	if !ok || c.serverVersion == "" || compareVersions(c.serverVersion, required) >= 0 {
+		return nil
+	}
+	return &UnsupportedError{Method: method, Required: required, Server: c.serverVersion}
+}
+
+// sendRequest sends the request and returns the response. An APIError is
+// returned if the server failed the request.
+func (c *Client) sendRequest(ctx context.Context, u string) (*apiResponse, error) {
+	method := methodName(u)
+	if err := c.supports(method); err != nil {
+		return nil, err
+	}
+	res, err := c.do(ctx, u)
 	if err != nil {
 		return nil, err
 	}
@@ -144,17 +241,53 @@ func sendRequest(url string) (*apiResponse, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err = responseError(&data.Response); err != nil {
+		return nil, err
+	}
 	return &data.Response, nil
 }
 
+// mediaResponseError returns an error if the response to a stream or image
+// request isn't the media. The body is closed if an error is returned.
+func mediaResponseError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return errors.New(resp.Status)
+	}
+	// Errors are returned as a normal API response instead of the media.
+	// The JSON format is requested, but some servers answer with XML.
+	ct := strings.Split(resp.Header.Get("Content-Type"), ";")[0]
+	switch {
+	case ct == "application/json":
+		defer resp.Body.Close()
+		var data apiData
+		if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return err
+		}
+		if err := responseError(&data.Response); err != nil {
+			return err
+		}
+		return ErrRequestFailed
+	case strings.HasSuffix(ct, "/xml"):
+		defer resp.Body.Close()
+		var data xmlResponse
+		if err := xml.NewDecoder(resp.Body).Decode(&data); err != nil {
+			return err
+		}
+		if err := responseError(&apiResponse{Status: data.Status, Error: data.Error}); err != nil {
+			return err
+		}
+		return ErrRequestFailed
+	}
+	return nil
+}
+
 func (c *Client) makeRequestURL(method string) string {
-	return fmt.Sprintf("%s/rest/%s.view?u=%s&t=%s&s=%s&v=%s&c=%s&f=json",
+	return fmt.Sprintf("%s/rest/%s.view?%s&v=%s&c=%s&f=json",
 		c.Credentials.Host,
 		method,
-		c.Username,
-		c.Token,
-		c.Salt,
-		apiVersion,
+		c.authParams(),
+		c.APIVersion(),
 		clientName,
 	)
 }
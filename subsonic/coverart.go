@@ -0,0 +1,42 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// CoverArt returns the cover art image. If size is larger than zero, the
+// server scales the image.
+func (c *Client) CoverArt(ctx context.Context, id string, size int) (io.ReadCloser, error) {
+	u := c.makeRequestURL("getCoverArt") + "&id=" + url.QueryEscape(id)
+	if size > 0 {
+		u += "&size=" + strconv.Itoa(size)
+	}
+	resp, err := c.do(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if err := mediaResponseError(resp); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoverArt(t *testing.T) {
+	assert := assert.New(t)
+	var last *url.URL
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL
+		if r.URL.Query().Get("id") == "missing" {
+			w.Header().Set("Content-Type", "application/json")
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: 70, Message: "Cover art not found"}}})
+			return
+		}
+		if r.URL.Query().Get("id") == "xml" {
+			w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<subsonic-response xmlns="http://subsonic.org/restapi" status="failed" version="1.16.1">
+<error code="70" message="Cover art not found"/>
+</subsonic-response>`))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("image"))
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	t.Run("image", func(t *testing.T) {
+		r, err := c.CoverArt(context.Background(), "al-1", 300)
+		require.NoError(t, err)
+		defer r.Close()
+		buf, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal("image", string(buf))
+		assert.Equal("/rest/getCoverArt.view", last.Path)
+		assert.Equal("al-1", last.Query().Get("id"))
+		assert.Equal("300", last.Query().Get("size"))
+	})
+
+	t.Run("original_size", func(t *testing.T) {
+		r, err := c.CoverArt(context.Background(), "al-1", 0)
+		require.NoError(t, err)
+		r.Close()
+		assert.Empty(last.Query().Get("size"))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := c.CoverArt(context.Background(), "missing", 300)
+		assert.EqualError(err, "Cover art not found")
+	})
+
+	t.Run("xml_error", func(t *testing.T) {
+		_, err := c.CoverArt(context.Background(), "xml", 300)
+		require.IsType(t, &APIError{}, err, "Should decode the XML error")
+		assert.Equal(CodeNotFound, err.(*APIError).Code)
+		assert.EqualError(err, "Cover art not found")
+	})
+}
@@ -17,22 +17,147 @@
 
 package subsonic
 
+import "encoding/json"
+
 type apiData struct {
 	Response apiResponse `json:"subsonic-response"`
 }
 
 type apiResponse struct {
-	Status     string     `json:"status"`
-	Version    string     `json:"version"`
-	ArtistList artistList `json:"artists"`
-	Artist     artist     `json:"artist"`
-	Album      album      `json:"album"`
+	Status         string          `json:"status"`
+	Version        string          `json:"version"`
+	OpenSubsonic   bool            `json:"openSubsonic"`
+	ArtistList     artistList      `json:"artists"`
+	Indexes        indexes         `json:"indexes"`
+	MusicFolders   musicFolders    `json:"musicFolders"`
+	PlayQueue      playQueue       `json:"playQueue"`
+	Bookmarks      bookmarks       `json:"bookmarks"`
+	NowPlaying     nowPlaying      `json:"nowPlaying"`
+	Shares         shares          `json:"shares"`
+	JukeboxStatus  jukeboxStatus   `json:"jukeboxStatus"`
+	Artist         artist          `json:"artist"`
+	Album          album           `json:"album"`
+	Playlists      playlists       `json:"playlists"`
+	Playlist       playlist        `json:"playlist"`
+	SearchResult   searchResult    `json:"searchResult3"`
+	Starred        searchResult    `json:"starred2"`
+	AlbumList      albumList       `json:"albumList2"`
+	SimilarSongs   songList        `json:"similarSongs2"`
+	RandomSongs    songList        `json:"randomSongs"`
+	LyricsList     lyricsList      `json:"lyricsList"`
+	Lyrics         lyrics          `json:"lyrics"`
+	RadioStations  radioStations   `json:"internetRadioStations"`
+	Podcasts       podcasts        `json:"podcasts"`
+	NewestPodcasts podcastEpisodes `json:"newestPodcasts"`
+	Error          *apiError       `json:"error"`
+	Extensions     []*extension    `json:"openSubsonicExtensions"`
+}
+
+type apiError struct {
+	Code    int    `json:"code" xml:"code,attr"`
+	Message string `json:"message" xml:"message,attr"`
+}
+
+// xmlResponse is the part of an XML reply needed to read an error. Some
+// servers answer stream and image requests in XML even when JSON is asked for.
+type xmlResponse struct {
+	Status string    `xml:"status,attr"`
+	Error  *apiError `xml:"error"`
+}
+
+type extension struct {
+	Name     string `json:"name"`
+	Versions []int  `json:"versions"`
 }
 
 type artistList struct {
 	Index []index `json:"index"`
 }
 
+type musicFolders struct {
+	Folders []*musicFolder `json:"musicFolder"`
+}
+
+type musicFolder struct {
+	// ID is a number in the API.
+	ID   json.Number `json:"id"`
+	Name string      `json:"name"`
+}
+
+type playQueue struct {
+	Entries   []*song `json:"entry"`
+	Current   flexID  `json:"current"`
+	Position  int64   `json:"position"`
+	Changed   string  `json:"changed"`
+	ChangedBy string  `json:"changedBy"`
+}
+
+type bookmarks struct {
+	Bookmarks []*bookmark `json:"bookmark"`
+}
+
+type bookmark struct {
+	Entry    *song  `json:"entry"`
+	Position int64  `json:"position"`
+	Comment  string `json:"comment"`
+	Changed  string `json:"changed"`
+}
+
+type nowPlaying struct {
+	Entries []*nowPlayingEntry `json:"entry"`
+}
+
+type nowPlayingEntry struct {
+	song
+	Username   string `json:"username"`
+	MinutesAgo int    `json:"minutesAgo"`
+	PlayerName string `json:"playerName"`
+}
+
+type shares struct {
+	Shares []*share `json:"share"`
+}
+
+type share struct {
+	ID          string  `json:"id"`
+	URL         string  `json:"url"`
+	Description string  `json:"description"`
+	Username    string  `json:"username"`
+	Created     string  `json:"created"`
+	Expires     string  `json:"expires"`
+	LastVisited string  `json:"lastVisited"`
+	VisitCount  int     `json:"visitCount"`
+	Entries     []*song `json:"entry"`
+}
+
+type jukeboxStatus struct {
+	CurrentIndex int     `json:"currentIndex"`
+	Playing      bool    `json:"playing"`
+	Gain         float64 `json:"gain"`
+	Position     int     `json:"position"`
+}
+
+// flexID is an ID sent as a number by some servers and as a string by
+// others.
+type flexID string
+
+func (id *flexID) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		err := json.Unmarshal(b, &s)
+		*id = flexID(s)
+		return err
+	}
+	if string(b) != "null" {
+		*id = flexID(b)
+	}
+	return nil
+}
+
+type indexes struct {
+	LastModified int64 `json:"lastModified"`
+}
+
 type index struct {
 	Name    string    `json:"name"`
 	Artists []*artist `json:"artist"`
@@ -44,27 +169,155 @@ type artist struct {
 	CoverArt   string   `json:"coverArt"`
 	AlbumCount int      `json:"albumCount"`
 	Albums     []*album `json:"album"`
+	Starred    string   `json:"starred"`
+	UserRating int      `json:"userRating"`
 }
 
 type album struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Artist     string  `json:"artist"`
+	ArtistID   string  `json:"artistId"`
+	CoverArt   string  `json:"coverArt"`
+	SongCount  int     `json:"songCount"`
+	Duration   int     `json:"duration"`
+	Year       int     `json:"year"`
+	Genre      string  `json:"genre"`
+	Songs      []*song `json:"song"`
+	Starred    string  `json:"starred"`
+	UserRating int     `json:"userRating"`
+	Created    string  `json:"created"`
+	// Changed is sent by some servers when the album was last updated.
+	Changed string `json:"changed"`
+	// OpenSubsonic fields.
+	Artists        []*artistRef `json:"artists"`
+	Moods          []string     `json:"moods"`
+	ExplicitStatus string       `json:"explicitStatus"`
+}
+
+// artistRef is an artist in the OpenSubsonic artists lists.
+type artistRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type songList struct {
+	Songs []*song `json:"song"`
+}
+
+type lyricsList struct {
+	StructuredLyrics []*structuredLyrics `json:"structuredLyrics"`
+}
+
+type structuredLyrics struct {
+	Lang   string       `json:"lang"`
+	Synced bool         `json:"synced"`
+	Offset int          `json:"offset"`
+	Lines  []*lyricLine `json:"line"`
+}
+
+type lyricLine struct {
+	Start int    `json:"start"`
+	Value string `json:"value"`
+}
+
+type lyrics struct {
+	Artist string `json:"artist"`
+	Title  string `json:"title"`
+	Value  string `json:"value"`
+}
+
+type podcasts struct {
+	Channels []*podcastChannel `json:"channel"`
+}
+
+type podcastChannel struct {
+	ID          string            `json:"id"`
+	URL         string            `json:"url"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	CoverArt    string            `json:"coverArt"`
+	Status      string            `json:"status"`
+	Episodes    []*podcastEpisode `json:"episode"`
+}
+
+type podcastEpisodes struct {
+	Episodes []*podcastEpisode `json:"episode"`
+}
+
+type podcastEpisode struct {
+	ID          string `json:"id"`
+	StreamID    string `json:"streamId"`
+	ChannelID   string `json:"channelId"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	PublishDate string `json:"publishDate"`
+	Status      string `json:"status"`
+	Duration    int    `json:"duration"`
+	CoverArt    string `json:"coverArt"`
+}
+
+type radioStations struct {
+	Stations []*radioStation `json:"internetRadioStation"`
+}
+
+type radioStation struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	StreamURL   string `json:"streamUrl"`
+	HomePageURL string `json:"homePageUrl"`
+}
+
+type albumList struct {
+	Albums []*album `json:"album"`
+}
+
+type searchResult struct {
+	Artists []*artist `json:"artist"`
+	Albums  []*album  `json:"album"`
+	Songs   []*song   `json:"song"`
+}
+
+type playlists struct {
+	Playlists []*playlist `json:"playlist"`
+}
+
+type playlist struct {
 	ID        string  `json:"id"`
 	Name      string  `json:"name"`
-	Artist    string  `json:"artist"`
-	ArtistID  string  `json:"artistId"`
-	CoverArt  string  `json:"coverArt"`
+	Comment   string  `json:"comment"`
+	Owner     string  `json:"owner"`
+	Public    bool    `json:"public"`
 	SongCount int     `json:"songCount"`
 	Duration  int     `json:"duration"`
-	Year      int     `json:"year"`
-	Genre     string  `json:"genre"`
-	Songs     []*song `json:"song"`
+	Entries   []*song `json:"entry"`
 }
 
 type song struct {
-	ID         string `json:"id"`
-	Title      string `json:"title"`
-	Track      int    `json:"track"`
-	Year       int    `json:"year"`
-	Size       int    `json:"size"`
-	Duration   int    `json:"duration"`
-	DiscNumber int    `json:"discNumber"`
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	ArtistID    string `json:"artistId"`
+	Album       string `json:"album"`
+	Genre       string `json:"genre"`
+	Track       int    `json:"track"`
+	Year        int    `json:"year"`
+	Size        int64  `json:"size"`
+	Duration    int    `json:"duration"`
+	DiscNumber  int    `json:"discNumber"`
+	Starred     string `json:"starred"`
+	BitRate     int    `json:"bitRate"`
+	Suffix      string `json:"suffix"`
+	ContentType string `json:"contentType"`
+	Path        string `json:"path"`
+	Created     string `json:"created"`
+	UserRating  int    `json:"userRating"`
+	CoverArt    string `json:"coverArt"`
+	// OpenSubsonic fields.
+	Artists            []*artistRef `json:"artists"`
+	AlbumArtists       []*artistRef `json:"albumArtists"`
+	DisplayAlbumArtist string       `json:"displayAlbumArtist"`
+	Moods              []string     `json:"moods"`
+	BPM                int          `json:"bpm"`
+	ExplicitStatus     string       `json:"explicitStatus"`
 }
@@ -0,0 +1,182 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode is the code of an error returned by the server.
+type ErrorCode int
+
+// The error codes defined by the Subsonic API.
+const (
+	// CodeGeneric is a generic error.
+	CodeGeneric ErrorCode = 0
+	// CodeMissingParameter is returned if a required parameter is missing.
+	CodeMissingParameter ErrorCode = 10
+	// CodeClientTooOld is returned if the server needs a newer API version
+	// than the client uses.
+	CodeClientTooOld ErrorCode = 20
+	// CodeServerTooOld is returned if the client uses a newer API version
+	// than the server supports.
+	CodeServerTooOld ErrorCode = 30
+	// CodeWrongCredentials is returned if the username or password is wrong.
+	CodeWrongCredentials ErrorCode = 40
+	// CodeTokenAuthNotSupported is returned if the server doesn't support
+	// token authentication for the user.
+	CodeTokenAuthNotSupported ErrorCode = 41
+	// CodeAuthNotSupported is returned by OpenSubsonic servers if they don't
+	// support the authentication method.
+	CodeAuthNotSupported ErrorCode = 42
+	// CodeConflictingAuth is returned by OpenSubsonic servers if more than
+	// one authentication method is used.
+	CodeConflictingAuth ErrorCode = 43
+	// CodeInvalidAPIKey is returned by OpenSubsonic servers if the API key
+	// is invalid.
+	CodeInvalidAPIKey ErrorCode = 44
+	// CodeNotAuthorized is returned if the user isn't allowed to do the
+	// operation.
+	CodeNotAuthorized ErrorCode = 50
+	// CodeNotFound is returned if the requested data wasn't found.
+	CodeNotFound ErrorCode = 70
+)
+
+// errorDescriptions are used if the server doesn't send a message.
+var errorDescriptions = map[ErrorCode]string{
+	CodeGeneric:               "generic error",
+	CodeMissingParameter:      "required parameter is missing",
+	CodeClientTooOld:          "incompatible protocol version, the client must be upgraded",
+	CodeServerTooOld:          "incompatible protocol version, the server must be upgraded",
+	CodeWrongCredentials:      "wrong username or password",
+	CodeTokenAuthNotSupported: "token authentication not supported",
+	CodeAuthNotSupported:      "authentication method not supported",
+	CodeConflictingAuth:       "multiple conflicting authentication methods",
+	CodeInvalidAPIKey:         "invalid API key",
+	CodeNotAuthorized:         "user is not authorized for the given operation",
+	CodeNotFound:              "the requested data was not found",
+}
+
+// APIError is an error returned by the server.
+type APIError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if desc, ok := errorDescriptions[e.Code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("error code %d", e.Code)
+}
+
+// IsAPIError returns true if the error is an APIError with the code.
+func IsAPIError(err error, code ErrorCode) bool {
+	e, ok := err.(*APIError)
+	return ok && e.Code == code
+}
+
+// UnsupportedError is returned if the server's API version is too old for
+// the method.
+type UnsupportedError struct {
+	// Method is the API method.
+	Method string
+	// Required is the API version needed by the method.
+	Required string
+	// Server is the API version of the server.
+	Server string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("the server doesn't support %s: it needs API version %s but the server has %s", e.Method, e.Required, e.Server)
+}
+
+// responseError returns the error in the response, or nil if the request
+// succeeded.
+func responseError(resp *apiResponse) error {
+	if resp.Error != nil {
+		return &APIError{Code: ErrorCode(resp.Error.Code), Message: resp.Error.Message}
+	}
+	if resp.Status == "failed" {
+		return ErrRequestFailed
+	}
+	return nil
+}
+
+// methodVersions are the API versions needed by the methods added after
+// version 1.0.0. Methods not listed are in all versions.
+var methodVersions = map[string]string{
+	"createPlaylist":           "1.2.0",
+	"deletePlaylist":           "1.2.0",
+	"getLyrics":                "1.2.0",
+	"getRandomSongs":           "1.2.0",
+	"jukeboxControl":           "1.2.0",
+	"createShare":              "1.6.0",
+	"deleteShare":              "1.6.0",
+	"getPodcasts":              "1.6.0",
+	"getShares":                "1.6.0",
+	"setRating":                "1.6.0",
+	"updateShare":              "1.6.0",
+	"getAlbum":                 "1.8.0",
+	"getAlbumList2":            "1.8.0",
+	"getArtist":                "1.8.0",
+	"getArtists":               "1.8.0",
+	"getStarred2":              "1.8.0",
+	"search3":                  "1.8.0",
+	"star":                     "1.8.0",
+	"unstar":                   "1.8.0",
+	"updatePlaylist":           "1.8.0",
+	"createBookmark":           "1.9.0",
+	"createPodcastChannel":     "1.9.0",
+	"deleteBookmark":           "1.9.0",
+	"deletePodcastChannel":     "1.9.0",
+	"downloadPodcastEpisode":   "1.9.0",
+	"getBookmarks":             "1.9.0",
+	"getInternetRadioStations": "1.9.0",
+	"refreshPodcasts":          "1.9.0",
+	"getSimilarSongs2":         "1.11.0",
+	"getPlayQueue":             "1.12.0",
+	"savePlayQueue":            "1.12.0",
+	"getNewestPodcasts":        "1.13.0",
+}
+
+// compareVersions returns -1, 0 or 1 if a is older than, the same as or
+// newer than b. Missing parts are treated as 0.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIErrors(t *testing.T) {
+	assert := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "ping"):
+			writeServerReply(w, &apiData{Response: apiResponse{
+				Status:  "failed",
+				Version: "1.16.1",
+				Error:   &apiError{Code: int(CodeTokenAuthNotSupported)},
+			}})
+		default:
+			writeServerReply(w, &apiData{Response: apiResponse{
+				Status: "failed",
+				Error:  &apiError{Code: int(CodeNotFound), Message: "Playlist not found"},
+			}})
+		}
+	}))
+	defer ts.Close()
+
+	t.Run("typed_error", func(t *testing.T) {
+		c := &Client{Credentials: Credentials{Host: ts.URL}}
+		_, err := c.Playlist(context.Background(), "1")
+		require.Error(t, err)
+		assert.True(IsAPIError(err, CodeNotFound), "Should return an APIError with the code")
+		assert.False(IsAPIError(err, CodeGeneric))
+		assert.Equal("Playlist not found", err.Error())
+	})
+	t.Run("login", func(t *testing.T) {
+		_, err := Login("user", "pass", ts.URL)
+		assert.True(IsAPIError(err, CodeTokenAuthNotSupported), "Should not be reported as wrong credentials")
+		assert.Equal("token authentication not supported", err.Error(), "Should describe errors without a message")
+	})
+}
+
+func TestVersionNegotiation(t *testing.T) {
+	assert := assert.New(t)
+	var version, requested string
+	var called bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Query().Get("v")
+		if !strings.Contains(r.URL.Path, "ping") {
+			called = true
+		}
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", Version: version}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+
+	t.Run("unknown", func(t *testing.T) {
+		assert.Equal(minAPIVersion, c.APIVersion(), "Should use the min version before the ping")
+		assert.NoError(c.supports("getNewestPodcasts"))
+	})
+	t.Run("old_server", func(t *testing.T) {
+		version = "1.8.0"
+		require.NoError(t, c.Ping(context.Background()))
+		assert.Equal("1.8.0", c.APIVersion())
+		_, err := c.SimilarTracks(context.Background(), "1", 10)
+		assert.Equal(&UnsupportedError{Method: "getSimilarSongs2", Required: "1.11.0", Server: "1.8.0"}, err)
+		assert.False(called, "The request should not be sent")
+		_, err = c.ListPlaylists(context.Background())
+		assert.NoError(err)
+		assert.Equal("1.8.0", requested, "The server's version should be sent")
+	})
+	t.Run("new_server", func(t *testing.T) {
+		version = "1.99.0"
+		require.NoError(t, c.Ping(context.Background()))
+		assert.Equal(maxAPIVersion, c.APIVersion(), "Should be capped to the client's version")
+	})
+	t.Run("compare", func(t *testing.T) {
+		assert.Equal(0, compareVersions("1.16", "1.16.0"))
+		assert.Equal(-1, compareVersions("1.9.0", "1.10.0"))
+		assert.Equal(1, compareVersions("1.13.1", "1.13.0"))
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// knownCapabilities are the OpenSubsonic extensions used by the client.
+var knownCapabilities = map[jamsonic.Capability]bool{
+	jamsonic.TranscodeOffsetCapability: true,
+	jamsonic.SongLyricsCapability:      true,
+	jamsonic.FormPostCapability:        true,
+	jamsonic.APIKeyAuthCapability:      true,
+}
+
+// extensions returns the server's OpenSubsonic extensions. Servers without
+// OpenSubsonic have no extensions.
+func (c *Client) extensions(ctx context.Context) ([]*extension, error) {
+	// The extensions can be requested without authentication.
+	res, err := c.do(ctx, c.Host()+"/rest/getOpenSubsonicExtensions.view?v="+c.APIVersion()+"&c="+clientName+"&f=json")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var data apiData
+	// Servers without OpenSubsonic may answer with an error or a page that
+	// isn't JSON.
+	if res.StatusCode != http.StatusOK || json.NewDecoder(res.Body).Decode(&data) != nil {
+		return nil, nil
+	}
+	return data.Response.Extensions, nil
+}
+
+// hasCapability returns true if the server has the OpenSubsonic extension.
+func (c *Client) hasCapability(capability jamsonic.Capability) bool {
+	for _, cp := range c.capabilities {
+		if cp == capability {
+			return true
+		}
+	}
+	return false
+}
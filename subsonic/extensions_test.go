@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	assert := assert.New(t)
+	var methods []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "getOpenSubsonicExtensions") {
+			writeServerReply(w, &apiData{Response: apiResponse{
+				Status: "ok",
+				Extensions: []*extension{
+					{Name: "formPost", Versions: []int{1}},
+					{Name: "songLyrics", Versions: []int{1}},
+					{Name: "somethingNew", Versions: []int{1}},
+				},
+			}})
+			return
+		}
+		methods = append(methods, r.Method)
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", Version: "1.16.1", OpenSubsonic: true}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL, Username: "user", Token: "token", Salt: "salt"}}
+
+	assert.False(jamsonic.HasCapability(c, jamsonic.FormPostCapability), "Nothing is known before the ping")
+	require.NoError(t, c.Ping(context.Background()))
+	assert.Equal([]jamsonic.Capability{jamsonic.FormPostCapability, jamsonic.SongLyricsCapability}, c.Capabilities(), "Only known extensions should be listed")
+	assert.True(jamsonic.HasCapability(c, jamsonic.SongLyricsCapability))
+	assert.False(jamsonic.HasCapability(c, jamsonic.TranscodeOffsetCapability))
+
+	_, err := c.ListPlaylists(context.Background())
+	assert.NoError(err)
+	assert.Equal([]string{http.MethodGet, http.MethodPost}, methods, "Requests should be posted after formPost is found")
+}
+
+func TestOpenSubsonicFields(t *testing.T) {
+	assert := assert.New(t)
+	var s song
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "1",
+		"title": "Duet",
+		"artist": "A & B",
+		"artists": [{"id": "a", "name": "A"}, {"id": "b", "name": "B"}],
+		"albumArtists": [{"id": "a", "name": "A"}],
+		"displayAlbumArtist": "A",
+		"moods": ["happy", "calm"],
+		"bpm": 120,
+		"explicitStatus": "clean"
+	}`), &s))
+	tr := newTrack(&s)
+	assert.Equal("A & B", tr.Artist)
+	assert.Equal([]string{"A", "B"}, tr.Artists)
+	assert.Equal([]string{"A"}, tr.AlbumArtists)
+	assert.Equal("A", tr.AlbumArtist)
+	assert.Equal([]string{"happy", "calm"}, tr.Moods)
+	assert.Equal(120, tr.BPM)
+	assert.Equal("clean", tr.ExplicitStatus)
+
+	tr = newTrack(&song{ID: "2"})
+	assert.Nil(tr.Artists, "Classic servers don't list the artists")
+}
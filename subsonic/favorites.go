@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// idParams maps the item kinds to the parameter used by star and unstar.
+var idParams = map[jamsonic.ItemKind]string{
+	jamsonic.ArtistItem: "artistId",
+	jamsonic.AlbumItem:  "albumId",
+	jamsonic.TrackItem:  "id",
+}
+
+// Starred returns the starred artists, albums and songs using getStarred2.
+func (c *Client) Starred(ctx context.Context) (*jamsonic.Favorites, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getStarred2"))
+	if err != nil {
+		return nil, err
+	}
+	artists, albums, tracks := data.Starred.convert()
+	return &jamsonic.Favorites{Artists: artists, Albums: albums, Tracks: tracks}, nil
+}
+
+// Star stars the item.
+func (c *Client) Star(ctx context.Context, kind jamsonic.ItemKind, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("star")+"&"+idParams[kind]+"="+url.QueryEscape(id))
+	return err
+}
+
+// Unstar removes the star from the item.
+func (c *Client) Unstar(ctx context.Context, kind jamsonic.ItemKind, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("unstar")+"&"+idParams[kind]+"="+url.QueryEscape(id))
+	return err
+}
+
+// SetRating rates the item from 1 to 5. A rating of 0 removes the rating.
+func (c *Client) SetRating(ctx context.Context, id string, rating int) error {
+	if rating < 0 || rating > 5 {
+		return jamsonic.ErrInvalidRating
+	}
+	_, err := c.sendRequest(ctx, c.makeRequestURL("setRating")+"&id="+url.QueryEscape(id)+"&rating="+strconv.Itoa(rating))
+	return err
+}
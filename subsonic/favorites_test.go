@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavorites(t *testing.T) {
+	assert := assert.New(t)
+	var last *url.URL
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", Starred: searchResult{
+			Artists: []*artist{&artist{ID: "A1", Name: "Artist1", Starred: "2018-01-01T00:00:00Z"}},
+			Songs:   []*song{&song{ID: "S1", Title: "Song1", Starred: "2018-01-01T00:00:00Z", UserRating: 4}},
+		}}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	t.Run("starred", func(t *testing.T) {
+		f, err := c.Starred(context.Background())
+		require.NoError(t, err)
+		require.Len(t, f.Artists, 1)
+		assert.True(f.Artists[0].Starred)
+		assert.Empty(f.Albums)
+		require.Len(t, f.Tracks, 1)
+		assert.Equal(4, f.Tracks[0].Rating)
+	})
+
+	t.Run("star", func(t *testing.T) {
+		assert.NoError(c.Star(context.Background(), jamsonic.AlbumItem, "AA1"))
+		assert.Equal("/rest/star.view", last.Path)
+		assert.Equal("AA1", last.Query().Get("albumId"))
+
+		assert.NoError(c.Unstar(context.Background(), jamsonic.TrackItem, "S1"))
+		assert.Equal("/rest/unstar.view", last.Path)
+		assert.Equal("S1", last.Query().Get("id"))
+	})
+
+	t.Run("rating", func(t *testing.T) {
+		assert.NoError(c.SetRating(context.Background(), "S1", 5))
+		assert.Equal("5", last.Query().Get("rating"))
+		assert.Equal(jamsonic.ErrInvalidRating, c.SetRating(context.Background(), "S1", 6))
+	})
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+const (
+	// jukeboxTimeout limits the time of a jukebox command.
+	jukeboxTimeout = 10 * time.Second
+)
+
+// ErrJukeboxStream is returned if the jukebox is asked to play a stream
+// instead of a track on the server, like an internet radio station.
+var ErrJukeboxStream = errors.New("the jukebox can only play tracks on the server")
+
+// jukeboxPollInterval is how often the jukebox's status is checked to find
+// out when the track has finished.
+var jukeboxPollInterval = time.Second
+
+// Jukebox plays the tracks on the server's sound card using jukeboxControl,
+// instead of streaming them. The Player keeps the play queue, so the
+// jukebox's playlist only holds the current track. The user needs the
+// jukebox role on the server.
+type Jukebox struct {
+	client   *Client
+	logger   *jamsonic.Logger
+	finished chan struct{}
+	errChan  chan error
+
+	mu sync.Mutex
+	// done stops polling the status. It's nil if the status isn't polled.
+	done chan struct{}
+	// started is set when the server has reported playing the track.
+	started bool
+}
+
+// Jukebox returns a stream handler that plays the tracks on the server.
+func (c *Client) Jukebox(logger *jamsonic.Logger) jamsonic.TrackStreamHandler {
+	return &Jukebox{
+		client:   c,
+		logger:   logger,
+		finished: make(chan struct{}, 1),
+		errChan:  make(chan error),
+	}
+}
+
+// PlayTrack replaces the jukebox's playlist with the track and starts
+// playing it from the offset.
+func (j *Jukebox) PlayTrack(track *jamsonic.Track, offset time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), jukeboxTimeout)
+	defer cancel()
+	if _, err := j.control(ctx, "set", "&id="+url.QueryEscape(track.ID)); err != nil {
+		return err
+	}
+	if offset > 0 {
+		if _, err := j.control(ctx, "skip", "&index=0&offset="+strconv.Itoa(int(offset/time.Second))); err != nil {
+			return err
+		}
+	}
+	if _, err := j.control(ctx, "start", ""); err != nil {
+		return err
+	}
+	j.startPolling()
+	return nil
+}
+
+// Play returns ErrJukeboxStream since the jukebox can't play streams.
+func (j *Jukebox) Play(r io.Reader) error {
+	return ErrJukeboxStream
+}
+
+// Stop stops the jukebox.
+func (j *Jukebox) Stop() {
+	j.stopPolling()
+	// Drop a finish that the player hasn't seen.
+	select {
+	case <-j.finished:
+	default:
+	}
+	j.send("stop", "")
+}
+
+// Pause stops the jukebox, which keeps the position in the track.
+func (j *Jukebox) Pause() {
+	j.stopPolling()
+	j.send("stop", "")
+}
+
+// Continue starts the jukebox from where it was paused.
+func (j *Jukebox) Continue() {
+	j.send("start", "")
+	j.startPolling()
+}
+
+// SetVolume sets the jukebox's gain.
+func (j *Jukebox) SetVolume(percent int) {
+	j.send("setGain", "&gain="+strconv.FormatFloat(float64(percent)/100, 'f', 2, 64))
+}
+
+// Finished returns a channel that receives when the track has been played.
+func (j *Jukebox) Finished() <-chan struct{} {
+	return j.finished
+}
+
+// Errors returns a channel with the errors of the commands sent by Stop,
+// Pause, Continue and SetVolume and of the status checks.
+func (j *Jukebox) Errors() <-chan error {
+	return j.errChan
+}
+
+// control sends the action to the jukebox and returns its status.
+func (j *Jukebox) control(ctx context.Context, action, params string) (*jukeboxStatus, error) {
+	data, err := j.client.sendRequest(ctx, j.client.makeRequestURL("jukeboxControl")+"&action="+action+params)
+	if err != nil {
+		return nil, err
+	}
+	return &data.JukeboxStatus, nil
+}
+
+// send sends the action and passes any error to the error channel.
+func (j *Jukebox) send(action, params string) {
+	ctx, cancel := context.WithTimeout(context.Background(), jukeboxTimeout)
+	defer cancel()
+	if _, err := j.control(ctx, action, params); err != nil {
+		j.sendError(err)
+	}
+}
+
+func (j *Jukebox) sendError(err error) {
+	go func() {
+		j.errChan <- err
+	}()
+}
+
+// startPolling checks the status until the track has finished or
+// stopPolling is called.
+func (j *Jukebox) startPolling() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.done != nil {
+		close(j.done)
+	}
+	j.done = make(chan struct{})
+	j.started = false
+	go j.poll(j.done)
+}
+
+func (j *Jukebox) stopPolling() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.done != nil {
+		close(j.done)
+		j.done = nil
+	}
+}
+
+// poll reports the track as finished when the server has played it and
+// stopped.
+func (j *Jukebox) poll(done chan struct{}) {
+	ticker := time.NewTicker(jukeboxPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), jukeboxTimeout)
+		s, err := j.control(ctx, "status", "")
+		cancel()
+		if err != nil {
+			j.sendError(err)
+			continue
+		}
+		j.mu.Lock()
+		if j.done != done {
+			// Stopped while the status was requested.
+			j.mu.Unlock()
+			return
+		}
+		if s.Playing || !j.started {
+			j.started = j.started || s.Playing
+			j.mu.Unlock()
+			continue
+		}
+		j.done = nil
+		j.mu.Unlock()
+		select {
+		case j.finished <- struct{}{}:
+		default:
+		}
+		return
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	jukeboxPollInterval = 10 * time.Millisecond
+}
+
+// mockJukebox records the jukebox commands and plays a track for a few
+// status checks.
+type mockJukebox struct {
+	mu       sync.Mutex
+	commands []string
+	playing  int
+}
+
+func (m *mockJukebox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := r.URL.Query()
+	action := q.Get("action")
+	cmd := action
+	for _, p := range []string{"id", "index", "offset", "gain"} {
+		if v := q.Get(p); v != "" {
+			cmd += " " + p + "=" + v
+		}
+	}
+	if action != "status" {
+		m.commands = append(m.commands, cmd)
+	}
+	if action == "start" {
+		m.playing = 3
+	}
+	playing := m.playing > 0
+	if action == "status" && m.playing > 0 {
+		m.playing--
+	}
+	writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", JukeboxStatus: jukeboxStatus{Playing: playing}}})
+}
+
+func (m *mockJukebox) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commands = nil
+}
+
+func (m *mockJukebox) sent() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string{}, m.commands...)
+}
+
+func TestJukebox(t *testing.T) {
+	assert := assert.New(t)
+	server := &mockJukebox{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+	j := c.Jukebox(jamsonic.DefaultLogger())
+
+	t.Run("play_track", func(t *testing.T) {
+		require.NoError(t, j.PlayTrack(&jamsonic.Track{ID: "42"}, 90*time.Second))
+		assert.Equal([]string{"set id=42", "skip index=0 offset=90", "start"}, server.sent())
+		select {
+		case <-j.Finished():
+		case <-time.After(time.Second):
+			t.Fatal("Finished not sent after the track was played")
+		}
+	})
+	t.Run("pause_and_continue", func(t *testing.T) {
+		server.reset()
+		j.Pause()
+		j.Continue()
+		assert.Equal([]string{"stop", "start"}, server.sent())
+	})
+	t.Run("stop", func(t *testing.T) {
+		server.reset()
+		j.Stop()
+		assert.Equal([]string{"stop"}, server.sent())
+		select {
+		case <-j.Finished():
+			t.Fatal("Finished should not be sent after stop")
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+	t.Run("volume", func(t *testing.T) {
+		server.reset()
+		j.(jamsonic.VolumeStreamHandler).SetVolume(50)
+		assert.Equal([]string{"setGain gain=0.50"}, server.sent())
+	})
+	t.Run("stream", func(t *testing.T) {
+		assert.Equal(ErrJukeboxStream, j.Play(strings.NewReader("")))
+	})
+}
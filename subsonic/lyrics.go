@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// Lyrics returns the lyrics for the track. Synced lyrics from the
+// OpenSubsonic getLyricsBySongId extension are preferred. If the server
+// doesn't support the extension or has no lyrics for the track, the classic
+// getLyrics endpoint is used to search by artist and title. The extension is
+// only tried if the server has it, or if the server's extensions are not
+// known yet.
+func (c *Client) Lyrics(ctx context.Context, track *jamsonic.Track) (*jamsonic.Lyrics, error) {
+	if c.serverVersion == "" || c.hasCapability(jamsonic.SongLyricsCapability) {
+		data, err := c.sendRequest(ctx, c.makeRequestURL("getLyricsBySongId")+"&id="+url.QueryEscape(track.ID))
+		if err == nil {
+			if l := structuredLyricsToLyrics(data.LyricsList.StructuredLyrics); l != nil {
+				return l, nil
+			}
+		} else {
+			c.logger.DebugLog("getLyricsBySongId failed: " + err.Error())
+		}
+	}
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getLyrics")+"&artist="+url.QueryEscape(track.Artist)+"&title="+url.QueryEscape(track.Title))
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(strings.Replace(data.Lyrics.Value, "\r\n", "\n", -1))
+	if text == "" {
+		return nil, jamsonic.ErrNoLyrics
+	}
+	lines := strings.Split(text, "\n")
+	l := &jamsonic.Lyrics{Lines: make([]jamsonic.LyricLine, len(lines))}
+	for i, line := range lines {
+		l.Lines[i].Text = line
+	}
+	return l, nil
+}
+
+// structuredLyricsToLyrics picks the synced lyrics if there are any, or the
+// first lyrics. Nil is returned if there are no lyrics.
+func structuredLyricsToLyrics(list []*structuredLyrics) *jamsonic.Lyrics {
+	var picked *structuredLyrics
+	for _, s := range list {
+		if len(s.Lines) == 0 {
+			continue
+		}
+		if picked == nil || (s.Synced && !picked.Synced) {
+			picked = s
+		}
+	}
+	if picked == nil {
+		return nil
+	}
+	l := &jamsonic.Lyrics{Synced: picked.Synced, Lines: make([]jamsonic.LyricLine, len(picked.Lines))}
+	for i, line := range picked.Lines {
+		l.Lines[i].Text = line.Value
+		if picked.Synced {
+			// A positive offset shows the lines earlier.
+			l.Lines[i].Start = time.Duration(line.Start-picked.Offset) * time.Millisecond
+		}
+	}
+	return l
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLyrics(t *testing.T) {
+	assert := assert.New(t)
+	var structured []*structuredLyrics
+	var classic lyrics
+	var extension bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/getLyricsBySongId.view":
+			if !extension {
+				writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: 0, Message: "Unknown method"}}})
+				return
+			}
+			assert.Equal("S1", r.URL.Query().Get("id"))
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", LyricsList: lyricsList{StructuredLyrics: structured}}})
+		case "/rest/getLyrics.view":
+			assert.Equal("Artist", r.URL.Query().Get("artist"))
+			assert.Equal("Title", r.URL.Query().Get("title"))
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", Lyrics: classic}})
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}, logger: jamsonic.DefaultLogger()}
+	track := &jamsonic.Track{ID: "S1", Artist: "Artist", Title: "Title"}
+
+	t.Run("synced", func(t *testing.T) {
+		extension = true
+		structured = []*structuredLyrics{
+			{Lang: "eng", Lines: []*lyricLine{{Value: "plain"}}},
+			{Lang: "eng", Synced: true, Offset: 100, Lines: []*lyricLine{{Start: 1100, Value: "one"}, {Start: 2100, Value: "two"}}},
+		}
+		l, err := c.Lyrics(context.Background(), track)
+		require.NoError(t, err)
+		assert.True(l.Synced)
+		assert.Equal([]jamsonic.LyricLine{{Start: time.Second, Text: "one"}, {Start: 2 * time.Second, Text: "two"}}, l.Lines)
+	})
+
+	t.Run("fallback_unsupported", func(t *testing.T) {
+		extension = false
+		classic = lyrics{Value: "one\r\ntwo\n"}
+		l, err := c.Lyrics(context.Background(), track)
+		require.NoError(t, err)
+		assert.False(l.Synced)
+		assert.Equal([]jamsonic.LyricLine{{Text: "one"}, {Text: "two"}}, l.Lines)
+	})
+
+	t.Run("fallback_empty", func(t *testing.T) {
+		extension = true
+		structured = nil
+		classic = lyrics{Value: "one"}
+		l, err := c.Lyrics(context.Background(), track)
+		require.NoError(t, err)
+		assert.Len(l.Lines, 1)
+	})
+
+	t.Run("no_lyrics", func(t *testing.T) {
+		classic = lyrics{}
+		_, err := c.Lyrics(context.Background(), track)
+		assert.Equal(jamsonic.ErrNoLyrics, err)
+	})
+}
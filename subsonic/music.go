@@ -18,51 +18,80 @@
 package subsonic
 
 import (
-	"errors"
+	"context"
 	"io"
-	"net/http"
+	"net/url"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
 )
 
 // ListTracks is an old API and is not implemeted for this provider.
 // Instead, FetchLibrary should be used.
-func (c *Client) ListTracks() ([]*jamsonic.Track, error) {
+func (c *Client) ListTracks(ctx context.Context) ([]*jamsonic.Track, error) {
 	panic("should not be called.")
 }
 
-// ListPlaylists should return the playlists. Currently this feature
-// is not implemented for this provider.
-func (c *Client) ListPlaylists() ([]*jamsonic.Playlist, error) {
-	panic("not implemented")
-}
-
 // ListPlaylistEntries is an old API and is not implemented for this provider.
-func (c *Client) ListPlaylistEntries() ([]*jamsonic.PlaylistEntry, error) {
+func (c *Client) ListPlaylistEntries(ctx context.Context) ([]*jamsonic.PlaylistEntry, error) {
 	panic("should not be called.")
 }
 
 // GetTrackInfo is an old API and is not implemented for this provider.
-func (c *Client) GetTrackInfo(trackID string) (*jamsonic.Track, error) {
+func (c *Client) GetTrackInfo(ctx context.Context, trackID string) (*jamsonic.Track, error) {
 	panic("should not be called.")
 }
 
+// defaultStreamSettings transcode the streams to MP3.
+var defaultStreamSettings = jamsonic.StreamSettings{Format: "mp3"}
+
 // GetStream returns a ReadCloser stream of the track. The audio is encoded
-// as a MP3.
-func (c *Client) GetStream(songID string) (io.ReadCloser, error) {
-	url := c.makeRequestURL("stream") + "&format=mp3&id=" + songID
-	resp, err := http.Get(url)
+// according to the stream settings, by default as a MP3.
+func (c *Client) GetStream(ctx context.Context, songID string) (io.ReadCloser, error) {
+	return c.GetStreamAt(ctx, songID, 0)
+}
+
+// GetStreamAt returns a ReadCloser stream of the track starting at the
+// offset. The offset is added to the time offset in the stream settings.
+func (c *Client) GetStreamAt(ctx context.Context, songID string, offset time.Duration) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, c.streamURL(songID, offset))
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+	if err := mediaResponseError(resp); err != nil {
+		return nil, err
 	}
 	return resp.Body, nil
 }
 
+// SetStreamSettings changes the format, bit rate and time offset used by the
+// following stream requests.
+func (c *Client) SetStreamSettings(settings jamsonic.StreamSettings) {
+	c.streamSettings.Store(settings)
+}
+
+// streamURL returns the URL for streaming the track with the current stream
+// settings.
+func (c *Client) streamURL(songID string, offset time.Duration) string {
+	settings, ok := c.streamSettings.Load().(jamsonic.StreamSettings)
+	if !ok {
+		settings = defaultStreamSettings
+	}
+	u := c.makeRequestURL("stream") + "&id=" + url.QueryEscape(songID)
+	if settings.Format != "" {
+		u += "&format=" + url.QueryEscape(settings.Format)
+	}
+	if settings.MaxBitRate > 0 {
+		u += "&maxBitRate=" + strconv.Itoa(settings.MaxBitRate)
+	}
+	if offset += settings.TimeOffset; offset > 0 {
+		u += "&timeOffset=" + strconv.Itoa(int(offset/time.Second))
+	}
+	return u
+}
+
 // GetProvider returns the provider identifier.
 func (c *Client) GetProvider() jamsonic.MusicProvider {
 	return jamsonic.SubSonic
@@ -77,19 +106,27 @@ func (c *Client) GetProvider() jamsonic.MusicProvider {
 //			Album2{...}
 //		}
 //		Artist2{...}
-func (c *Client) FetchLibrary() ([]*jamsonic.Artist, error) {
-	artists, err := getAllArtists(c)
+func (c *Client) FetchLibrary(ctx context.Context) ([]*jamsonic.Artist, error) {
+	artists, err := getAllArtists(ctx, c)
 	if err != nil {
-		return nil, err
+		return nil, syncError(ctx, err)
 	}
+	p := c.newProgress()
+	defer p.close()
+	p.update(func(s *jamsonic.SyncProgress) {
+		s.ArtistsTotal = len(artists)
+		for _, a := range artists {
+			s.AlbumsTotal += a.AlbumCount
+		}
+	})
 
 	jobs := make(chan *artist)
 	results := make(chan *jamsonic.Artist)
 	var wgroup sync.WaitGroup
 
-	for i := 0; i < 10; i++ {
+	for i := 0; i < syncWorkers(); i++ {
 		wgroup.Add(1)
-		go getArtistSongs(c, jobs, results, &wgroup)
+		go getArtistSongs(ctx, c, p, jobs, results, &wgroup)
 	}
 
 	// Observer ensures result channels is closed when done processing.
@@ -109,55 +146,60 @@ func (c *Client) FetchLibrary() ([]*jamsonic.Artist, error) {
 	for a := range results {
 		as = append(as, a)
 	}
+	if err := ctx.Err(); err != nil {
+		// A partial library is not returned.
+		return nil, err
+	}
 	return as, nil
 }
 
-func getArtistSongs(c *Client, ajob <-chan *artist, result chan<- *jamsonic.Artist, wg *sync.WaitGroup) {
+func getArtistSongs(ctx context.Context, c *Client, p *progress, ajob <-chan *artist, result chan<- *jamsonic.Artist, wg *sync.WaitGroup) {
 	defer wg.Done()
 	logger := c.logger
 	for a := range ajob {
+		// The rest of the jobs are drained once the sync is cancelled.
+		if ctx.Err() != nil {
+			continue
+		}
 		logger.InfoLog("Downloading tracks for " + a.Name)
-		albumRes, err := getArtistAlbums(c, a.ID)
+		p.update(func(s *jamsonic.SyncProgress) { s.Current = a.Name })
+		albumRes, err := getArtistAlbums(ctx, c, a.ID)
 		albums := make([]*jamsonic.Album, len(albumRes))
 		if err != nil {
 			logger.ErrorLog("Failed to process " + a.Name)
+			p.fail(a.Name, err)
+			p.update(func(s *jamsonic.SyncProgress) {
+				s.ArtistsDone++
+				s.AlbumsDone += a.AlbumCount
+			})
 			continue
 		}
 		for k, album := range albumRes {
 			logger.DebugLog("Processing " + album.Name)
-			songs, err := getAlbumSongs(c, album.ID)
+			p.update(func(s *jamsonic.SyncProgress) { s.Current = a.Name + " - " + album.Name })
+			songs, err := getAlbumSongs(ctx, c, album.ID)
 			if err != nil {
 				logger.ErrorLog("Failed to process " + album.Name)
+				p.fail(a.Name+" - "+album.Name, err)
 			}
-			tracks := make([]*jamsonic.Track, len(songs))
-			for i, v := range songs {
-				tracks[i] = &jamsonic.Track{
-					Title:          v.Title,
-					ID:             v.ID,
-					TrackNumber:    uint32(v.Track),
-					DiscNumber:     uint8(v.DiscNumber),
-					Year:           uint32(v.Year),
-					DurationMillis: strconv.Itoa(v.Duration * 1000),
-				}
-			}
-			albums[k] = &jamsonic.Album{
-				Artist: a.Name,
-				ID:     album.ID,
-				Name:   album.Name,
-				Tracks: tracks,
-				Year:   uint32(album.Year),
-			}
-		}
-		result <- &jamsonic.Artist{
-			Name:   a.Name,
-			ID:     a.ID,
-			Albums: albums,
+			albums[k] = newAlbum(album)
+			albums[k].Artist = a.Name
+			albums[k].Tracks = albumTracks(songs, album)
+			p.update(func(s *jamsonic.SyncProgress) { s.AlbumsDone++ })
 		}
+		// The album count from getArtists may be out of date.
+		p.update(func(s *jamsonic.SyncProgress) {
+			s.ArtistsDone++
+			s.AlbumsTotal += len(albumRes) - a.AlbumCount
+		})
+		artist := newArtist(a)
+		artist.Albums = albums
+		result <- artist
 	}
 }
 
-func getAllArtists(c *Client) ([]*artist, error) {
-	data, err := sendRequest(c.makeRequestURL("getArtists"))
+func getAllArtists(ctx context.Context, c *Client) ([]*artist, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getArtists")+c.musicFolderParam())
 	if err != nil {
 		return nil, err
 	}
@@ -168,18 +210,121 @@ func getAllArtists(c *Client) ([]*artist, error) {
 	return artists, nil
 }
 
-func getArtistAlbums(c *Client, artistID string) ([]*album, error) {
-	data, err := sendRequest(c.makeRequestURL("getArtist") + "&id=" + artistID)
+func getArtistAlbums(ctx context.Context, c *Client, artistID string) ([]*album, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getArtist")+"&id="+artistID)
 	if err != nil {
 		return nil, err
 	}
 	return data.Artist.Albums, nil
 }
 
-func getAlbumSongs(c *Client, albumID string) ([]*song, error) {
-	data, err := sendRequest(c.makeRequestURL("getAlbum") + "&id=" + albumID)
+func getAlbumSongs(ctx context.Context, c *Client, albumID string) ([]*song, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getAlbum")+"&id="+albumID)
 	if err != nil {
 		return nil, err
 	}
 	return data.Album.Songs, nil
 }
+
+func newArtist(a *artist) *jamsonic.Artist {
+	return &jamsonic.Artist{
+		Name:     a.Name,
+		ID:       a.ID,
+		Starred:  a.Starred != "",
+		Rating:   a.UserRating,
+		CoverArt: a.CoverArt,
+	}
+}
+
+func newAlbum(a *album) *jamsonic.Album {
+	return &jamsonic.Album{
+		Artist:         a.Artist,
+		ID:             a.ID,
+		Name:           a.Name,
+		Year:           uint32(a.Year),
+		Starred:        a.Starred != "",
+		Rating:         a.UserRating,
+		CoverArt:       a.CoverArt,
+		Artists:        artistNames(a.Artists),
+		Moods:          a.Moods,
+		ExplicitStatus: a.ExplicitStatus,
+		Changed:        albumChanged(a),
+	}
+}
+
+// albumChanged returns when the album was last changed, or when it was
+// added if the server doesn't say.
+func albumChanged(a *album) time.Time {
+	changed, err := time.Parse(time.RFC3339, a.Changed)
+	if err != nil {
+		changed, _ = time.Parse(time.RFC3339, a.Created)
+	}
+	return changed
+}
+
+// albumTracks returns the album's tracks. Tracks without their own cover art
+// get the album's.
+func albumTracks(songs []*song, a *album) []*jamsonic.Track {
+	tracks := newTracks(songs)
+	for _, t := range tracks {
+		if t.CoverArt == "" {
+			t.CoverArt = a.CoverArt
+		}
+		if t.AlbumArtist == "" {
+			t.AlbumArtist = a.Artist
+		}
+		if t.Album == "" {
+			t.Album = a.Name
+		}
+	}
+	return tracks
+}
+
+func newTrack(s *song) *jamsonic.Track {
+	created, _ := time.Parse(time.RFC3339, s.Created)
+	starred, _ := time.Parse(time.RFC3339, s.Starred)
+	var size string
+	if s.Size > 0 {
+		size = strconv.FormatInt(s.Size, 10)
+	}
+	return &jamsonic.Track{
+		Title:          s.Title,
+		ID:             s.ID,
+		Artist:         s.Artist,
+		ArtistID:       s.ArtistID,
+		Album:          s.Album,
+		Genre:          s.Genre,
+		TrackNumber:    uint32(s.Track),
+		DiscNumber:     uint8(s.DiscNumber),
+		Year:           uint32(s.Year),
+		DurationMillis: strconv.Itoa(s.Duration * 1000),
+		EstimatedSize:  size,
+		BitRate:        s.BitRate,
+		Suffix:         s.Suffix,
+		ContentType:    s.ContentType,
+		Path:           s.Path,
+		Created:        created,
+		Starred:        s.Starred != "",
+		StarredAt:      starred,
+		Rating:         s.UserRating,
+		CoverArt:       s.CoverArt,
+		AlbumArtist:    s.DisplayAlbumArtist,
+		Artists:        artistNames(s.Artists),
+		AlbumArtists:   artistNames(s.AlbumArtists),
+		Moods:          s.Moods,
+		BPM:            s.BPM,
+		ExplicitStatus: s.ExplicitStatus,
+	}
+}
+
+// artistNames returns the names of the artists, or nil if there are none.
+func artistNames(artists []*artistRef) []string {
+	if len(artists) == 0 {
+		return nil
+	}
+	names := make([]string, len(artists))
+	for i, a := range artists {
+		names[i] = a.Name
+	}
+	return names
+}
@@ -18,12 +18,15 @@
 package subsonic
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/TcM1911/jamsonic"
 	"github.com/stretchr/testify/assert"
@@ -50,20 +53,43 @@ func TestGetStream(t *testing.T) {
 	}
 
 	t.Run("handle_error_code", func(t *testing.T) {
-		_, err := c.GetStream("codefail")
+		_, err := c.GetStream(context.Background(), "codefail")
 		assert.Error(err, "Return error if 200 ok is not returned.")
 		assert.Equal("400 Bad Request", err.Error(), "Wrong error message")
 	})
 	t.Run("get_stream", func(t *testing.T) {
-		b, err := c.GetStream("stream")
+		b, err := c.GetStream(context.Background(), "stream")
 		assert.NoError(err, "Should not return an error if a stream is returned.")
 		actual, _ := ioutil.ReadAll(b)
 		b.Close()
 		assert.Equal(stream, actual, "Wrong stream returned.")
 	})
+	t.Run("stream_settings", func(t *testing.T) {
+		c := &Client{}
+		q := func(offset time.Duration) url.Values {
+			u, err := url.Parse(c.streamURL("42", offset))
+			assert.NoError(err)
+			return u.Query()
+		}
+		query := q(0)
+		assert.Equal("mp3", query.Get("format"), "Should default to MP3")
+		assert.Equal("", query.Get("maxBitRate"))
+		assert.Equal("", query.Get("timeOffset"))
+
+		c.SetStreamSettings(jamsonic.StreamSettings{Format: jamsonic.RawFormat, MaxBitRate: 128, TimeOffset: 10 * time.Second})
+		query = q(5 * time.Second)
+		assert.Equal("42", query.Get("id"))
+		assert.Equal("raw", query.Get("format"))
+		assert.Equal("128", query.Get("maxBitRate"))
+		assert.Equal("15", query.Get("timeOffset"))
+
+		c.SetStreamSettings(jamsonic.StreamSettings{})
+		_, ok := q(0)["format"]
+		assert.False(ok, "No format should be sent if it's empty")
+	})
 	t.Run("request_error", func(t *testing.T) {
 		c.Credentials.Host = "http://localhost:-8080"
-		b, err := c.GetStream("empty")
+		b, err := c.GetStream(context.Background(), "empty")
 		assert.Error(err, "Should return an error")
 		assert.Nil(b, "No reader should be returned if it's empty")
 	})
@@ -164,7 +190,7 @@ func TestFetchLibrary(t *testing.T) {
 		logger: jamsonic.DefaultLogger(),
 	}
 	t.Run("get_lib", func(t *testing.T) {
-		l, err := c.FetchLibrary()
+		l, err := c.FetchLibrary(context.Background())
 		assert.NoError(err, "Should not return an error on success.")
 		assert.Len(l, 2, "Should return 2 artists")
 		for _, v := range l {
@@ -192,12 +218,49 @@ func TestFetchLibrary(t *testing.T) {
 	})
 	t.Run("handle_hard_fail", func(t *testing.T) {
 		c.Credentials.Host = "http://localhost:-8080"
-		l, err := c.FetchLibrary()
+		l, err := c.FetchLibrary(context.Background())
 		assert.Nil(l, "Should return nil if failed")
 		assert.Error(err, "Should return an error on hard fail.")
 	})
 }
 
+func TestSongMetadata(t *testing.T) {
+	assert := assert.New(t)
+	a := &album{ID: "AL1", Name: "Compilation", Artist: "Various Artists", CoverArt: "al-1"}
+	s := &song{
+		ID:          "1",
+		Title:       "Title",
+		Artist:      "Guest",
+		Size:        31457280,
+		BitRate:     1000,
+		Suffix:      "flac",
+		ContentType: "audio/flac",
+		Path:        "Various Artists/Compilation/01 - Title.flac",
+		Created:     "2018-01-02T03:04:05.000Z",
+		Starred:     "2018-02-03T04:05:06.000Z",
+	}
+	tracks := albumTracks([]*song{s}, a)
+	assert.Len(tracks, 1, "Should return one track")
+	tr := tracks[0]
+	assert.Equal("Guest", tr.Artist, "Should keep the track artist")
+	assert.Equal("Various Artists", tr.AlbumArtist, "Should fill in the album artist")
+	assert.Equal("Compilation", tr.Album, "Should fill in the album name")
+	assert.Equal("al-1", tr.CoverArt, "Should fill in the cover art")
+	assert.Equal("31457280", tr.EstimatedSize, "Wrong size")
+	assert.Equal(1000, tr.BitRate, "Wrong bit rate")
+	assert.Equal("flac", tr.Suffix, "Wrong suffix")
+	assert.Equal("audio/flac", tr.ContentType, "Wrong content type")
+	assert.Equal(s.Path, tr.Path, "Wrong path")
+	assert.Equal(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC), tr.Created, "Wrong created date")
+	assert.True(tr.Starred, "Should be starred")
+	assert.Equal(time.Date(2018, 2, 3, 4, 5, 6, 0, time.UTC), tr.StarredAt, "Wrong starred date")
+
+	tr = newTrack(&song{ID: "2"})
+	assert.Empty(tr.EstimatedSize, "Should not report a size the server didn't send")
+	assert.True(tr.Created.IsZero(), "Should not have a created date")
+	assert.False(tr.Starred, "Should not be starred")
+}
+
 func writeServerReply(w http.ResponseWriter, data *apiData) {
 	buf, _ := json.Marshal(&data)
 	w.Write(buf)
@@ -206,8 +269,7 @@ func writeServerReply(w http.ResponseWriter, data *apiData) {
 func TestPanics(t *testing.T) {
 	assert := assert.New(t)
 	c := &Client{}
-	assert.PanicsWithValue("should not be called.", func() { _, _ = c.ListTracks() }, "Method should panic.")
-	assert.PanicsWithValue("not implemented", func() { _, _ = c.ListPlaylists() }, "Method should panic.")
-	assert.PanicsWithValue("should not be called.", func() { _, _ = c.ListPlaylistEntries() }, "Method should panic.")
-	assert.PanicsWithValue("should not be called.", func() { _, _ = c.GetTrackInfo("") }, "Method should panic.")
+	assert.PanicsWithValue("should not be called.", func() { _, _ = c.ListTracks(context.Background()) }, "Method should panic.")
+	assert.PanicsWithValue("should not be called.", func() { _, _ = c.ListPlaylistEntries(context.Background()) }, "Method should panic.")
+	assert.PanicsWithValue("should not be called.", func() { _, _ = c.GetTrackInfo(context.Background(), "") }, "Method should panic.")
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// MusicFolders returns the server's music folders using getMusicFolders.
+func (c *Client) MusicFolders(ctx context.Context) ([]*jamsonic.MusicFolder, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getMusicFolders"))
+	if err != nil {
+		return nil, err
+	}
+	folders := make([]*jamsonic.MusicFolder, len(data.MusicFolders.Folders))
+	for i, f := range data.MusicFolders.Folders {
+		folders[i] = &jamsonic.MusicFolder{ID: f.ID.String(), Name: f.Name}
+	}
+	return folders, nil
+}
+
+// SetMusicFolder limits the library, album lists, searches and random tracks
+// to the folder. An empty ID is all folders.
+func (c *Client) SetMusicFolder(id string) {
+	c.musicFolder.Store(id)
+}
+
+// musicFolderParam returns the musicFolderId parameter for the selected
+// folder, or an empty string if all folders are used.
+func (c *Client) musicFolderParam() string {
+	id, _ := c.musicFolder.Load().(string)
+	if id == "" {
+		return ""
+	}
+	return "&musicFolderId=" + url.QueryEscape(id)
+}
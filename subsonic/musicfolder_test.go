@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMusicFolders(t *testing.T) {
+	assert := assert.New(t)
+	var folderID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		folderID = r.URL.Query().Get("musicFolderId")
+		if methodName(r.URL.Path) == "getMusicFolders" {
+			w.Write([]byte(`{"subsonic-response":{"status":"ok","musicFolders":{"musicFolder":[{"id":1,"name":"Music"},{"id":2,"name":"Audiobooks"}]}}}`))
+			return
+		}
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+
+	t.Run("list", func(t *testing.T) {
+		folders, err := c.MusicFolders(context.Background())
+		require.NoError(t, err)
+		assert.Equal([]*jamsonic.MusicFolder{{ID: "1", Name: "Music"}, {ID: "2", Name: "Audiobooks"}}, folders)
+	})
+	t.Run("selected", func(t *testing.T) {
+		c.SetMusicFolder("2")
+		defer c.SetMusicFolder("")
+		_, err := getAllArtists(context.Background(), c)
+		assert.NoError(err)
+		assert.Equal("2", folderID, "Should limit the library to the folder")
+		_, err = c.Search(context.Background(), "query", 10, 0)
+		assert.NoError(err)
+		assert.Equal("2", folderID, "Should limit the search to the folder")
+		_, err = c.RandomTracks(context.Background(), &jamsonic.RadioFilter{}, 10)
+		assert.NoError(err)
+		assert.Equal("2", folderID, "Should limit the random tracks to the folder")
+		_, err = c.AlbumList(context.Background(), &jamsonic.AlbumListQuery{Type: jamsonic.NewestAlbums, Count: 10})
+		assert.NoError(err)
+		assert.Equal("2", folderID, "Should limit the album list to the folder")
+	})
+	t.Run("all", func(t *testing.T) {
+		_, err := getAllArtists(context.Background(), c)
+		assert.NoError(err)
+		assert.Equal("", folderID)
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// NowPlaying returns what the other users are playing using getNowPlaying.
+// The tracks played with the client's own account are left out.
+func (c *Client) NowPlaying(ctx context.Context) ([]*jamsonic.NowPlaying, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getNowPlaying"))
+	if err != nil {
+		return nil, err
+	}
+	var list []*jamsonic.NowPlaying
+	for _, e := range data.NowPlaying.Entries {
+		if e.Username == c.Credentials.Username {
+			continue
+		}
+		list = append(list, &jamsonic.NowPlaying{
+			Track:      newTrack(&e.song),
+			Username:   e.Username,
+			PlayerName: e.PlayerName,
+			Started:    time.Duration(e.MinutesAgo) * time.Minute,
+		})
+	}
+	return list, nil
+}
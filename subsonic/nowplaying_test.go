@@ -0,0 +1,49 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNowPlaying(t *testing.T) {
+	assert := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"subsonic-response":{"status":"ok","nowPlaying":{"entry":[` +
+			`{"id":"1","title":"Song A","artist":"Artist A","username":"alice","minutesAgo":3,"playerName":"DSub"},` +
+			`{"id":"2","title":"Song B","username":"me","minutesAgo":0}]}}}`))
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL, Username: "me"}}
+
+	list, err := c.NowPlaying(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1, "Own tracks should be left out")
+	assert.Equal("alice", list[0].Username)
+	assert.Equal("DSub", list[0].PlayerName)
+	assert.Equal(3*time.Minute, list[0].Started)
+	assert.Equal("1", list[0].Track.ID)
+	assert.Equal("Song A", list[0].Track.Title)
+	assert.Equal("Artist A", list[0].Track.Artist)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// ErrPlaylistNotFound is returned if a created playlist can't be found.
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// ListPlaylists returns the playlists without their tracks.
+func (c *Client) ListPlaylists(ctx context.Context) ([]*jamsonic.Playlist, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getPlaylists"))
+	if err != nil {
+		return nil, err
+	}
+	pls := make([]*jamsonic.Playlist, len(data.Playlists.Playlists))
+	for i, p := range data.Playlists.Playlists {
+		pls[i] = newPlaylist(p)
+	}
+	return pls, nil
+}
+
+// Playlist returns the playlist with its tracks.
+func (c *Client) Playlist(ctx context.Context, id string) (*jamsonic.Playlist, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getPlaylist")+"&id="+url.QueryEscape(id))
+	if err != nil {
+		return nil, err
+	}
+	p := newPlaylist(&data.Playlist)
+	p.Tracks = newTracks(data.Playlist.Entries)
+	return p, nil
+}
+
+// CreatePlaylist creates a new playlist with the tracks.
+func (c *Client) CreatePlaylist(ctx context.Context, name string, trackIDs []string) (*jamsonic.Playlist, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("createPlaylist")+"&name="+url.QueryEscape(name)+songParams("songId", trackIDs))
+	if err != nil {
+		return nil, err
+	}
+	if data.Playlist.ID != "" {
+		return c.Playlist(ctx, data.Playlist.ID)
+	}
+	// Servers implementing API versions before 1.14.0 don't return the
+	// new playlist, so the newest playlist with the name is used.
+	pls, err := c.ListPlaylists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(pls) - 1; i >= 0; i-- {
+		if pls[i].Name == name {
+			return c.Playlist(ctx, pls[i].ID)
+		}
+	}
+	return nil, ErrPlaylistNotFound
+}
+
+// RenamePlaylist changes the name of the playlist.
+func (c *Client) RenamePlaylist(ctx context.Context, id, name string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("updatePlaylist")+"&playlistId="+url.QueryEscape(id)+"&name="+url.QueryEscape(name))
+	return err
+}
+
+// AppendToPlaylist adds the tracks to the end of the playlist.
+func (c *Client) AppendToPlaylist(ctx context.Context, id string, trackIDs []string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("updatePlaylist")+"&playlistId="+url.QueryEscape(id)+songParams("songIdToAdd", trackIDs))
+	return err
+}
+
+// RemoveFromPlaylist removes the tracks at the indexes from the playlist.
+func (c *Client) RemoveFromPlaylist(ctx context.Context, id string, indexes []int) error {
+	params := make([]string, len(indexes))
+	for i, index := range indexes {
+		params[i] = strconv.Itoa(index)
+	}
+	_, err := c.sendRequest(ctx, c.makeRequestURL("updatePlaylist")+"&playlistId="+url.QueryEscape(id)+songParams("songIndexToRemove", params))
+	return err
+}
+
+// ReorderPlaylist replaces the tracks in the playlist with the tracks in the
+// given order. The API doesn't have a move operation, so the playlist is
+// overwritten with createPlaylist.
+func (c *Client) ReorderPlaylist(ctx context.Context, id string, trackIDs []string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("createPlaylist")+"&playlistId="+url.QueryEscape(id)+songParams("songId", trackIDs))
+	return err
+}
+
+// DeletePlaylist deletes the playlist.
+func (c *Client) DeletePlaylist(ctx context.Context, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("deletePlaylist")+"&id="+url.QueryEscape(id))
+	return err
+}
+
+// songParams returns the values as repeated query parameters.
+func songParams(key string, values []string) string {
+	var s string
+	for _, v := range values {
+		s += "&" + key + "=" + url.QueryEscape(v)
+	}
+	return s
+}
+
+func newPlaylist(p *playlist) *jamsonic.Playlist {
+	return &jamsonic.Playlist{
+		ID:        p.ID,
+		Name:      p.Name,
+		Comment:   p.Comment,
+		Owner:     p.Owner,
+		Public:    p.Public,
+		SongCount: p.SongCount,
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaylists(t *testing.T) {
+	assert := assert.New(t)
+	pl := playlist{ID: "P1", Name: "Mix", Owner: "user", SongCount: 2, Entries: []*song{
+		&song{ID: "S1", Title: "Song1", Artist: "Artist1", Album: "Album1", Duration: 3},
+		&song{ID: "S2", Title: "Song2"},
+	}}
+	var last *url.URL
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL
+		switch {
+		case strings.Contains(r.URL.Path, "getPlaylists.view"):
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok",
+				Playlists: playlists{Playlists: []*playlist{&playlist{ID: "P0", Name: "Mix"}, &playlist{ID: "P1", Name: "Mix"}}}}})
+		case strings.Contains(r.URL.Path, "getPlaylist.view"):
+			if r.URL.Query().Get("id") != "P1" {
+				writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: 70, Message: "Playlist not found"}}})
+				return
+			}
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", Playlist: pl}})
+		default:
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	t.Run("list", func(t *testing.T) {
+		pls, err := c.ListPlaylists(context.Background())
+		require.NoError(t, err)
+		require.Len(t, pls, 2)
+		assert.Equal("P0", pls[0].ID)
+		assert.Nil(pls[0].Tracks)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		p, err := c.Playlist(context.Background(), "P1")
+		require.NoError(t, err)
+		assert.Equal("Mix", p.Name)
+		assert.Equal("user", p.Owner)
+		require.Len(t, p.Tracks, 2)
+		assert.Equal("Artist1", p.Tracks[0].Artist)
+		assert.Equal("3000", p.Tracks[0].DurationMillis)
+
+		_, err = c.Playlist(context.Background(), "missing")
+		assert.EqualError(err, "Playlist not found")
+	})
+
+	t.Run("create_without_reply", func(t *testing.T) {
+		p, err := c.CreatePlaylist(context.Background(), "Mix", []string{"S1"})
+		require.NoError(t, err)
+		assert.Equal("P1", p.ID, "The newest playlist with the name should be returned")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		assert.NoError(c.RenamePlaylist(context.Background(), "P1", "New name"))
+		assert.Equal("New name", last.Query().Get("name"))
+
+		assert.NoError(c.AppendToPlaylist(context.Background(), "P1", []string{"S3", "S4"}))
+		assert.Equal([]string{"S3", "S4"}, last.Query()["songIdToAdd"])
+
+		assert.NoError(c.RemoveFromPlaylist(context.Background(), "P1", []int{0, 2}))
+		assert.Equal([]string{"0", "2"}, last.Query()["songIndexToRemove"])
+
+		assert.NoError(c.ReorderPlaylist(context.Background(), "P1", []string{"S2", "S1"}))
+		assert.True(strings.HasSuffix(last.Path, "createPlaylist.view"))
+		assert.Equal("P1", last.Query().Get("playlistId"))
+		assert.Equal([]string{"S2", "S1"}, last.Query()["songId"])
+
+		assert.NoError(c.DeletePlaylist(context.Background(), "P1"))
+		assert.True(strings.HasSuffix(last.Path, "deletePlaylist.view"))
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// SavePlayQueue saves the tracks with the IDs, the current track and the
+// position in it using savePlayQueue.
+func (c *Client) SavePlayQueue(ctx context.Context, trackIDs []string, current string, position time.Duration) error {
+	u := c.makeRequestURL("savePlayQueue")
+	for _, id := range trackIDs {
+		u += "&id=" + url.QueryEscape(id)
+	}
+	if current != "" {
+		u += "&current=" + url.QueryEscape(current) +
+			"&position=" + strconv.FormatInt(int64(position/time.Millisecond), 10)
+	}
+	_, err := c.sendRequest(ctx, u)
+	return err
+}
+
+// PlayQueue returns the play queue saved on the server using getPlayQueue,
+// or nil if none is saved.
+func (c *Client) PlayQueue(ctx context.Context) (*jamsonic.SavedPlayQueue, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getPlayQueue"))
+	// Some servers answer with not found if no queue is saved.
+	if IsAPIError(err, CodeNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	q := data.PlayQueue
+	if len(q.Entries) == 0 {
+		return nil, nil
+	}
+	changed, _ := time.Parse(time.RFC3339, q.Changed)
+	return &jamsonic.SavedPlayQueue{
+		Tracks:    newTracks(q.Entries),
+		Current:   string(q.Current),
+		Position:  time.Duration(q.Position) * time.Millisecond,
+		Changed:   changed,
+		ChangedBy: q.ChangedBy,
+	}, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayQueue(t *testing.T) {
+	assert := assert.New(t)
+	var saved url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch methodName(r.URL.Path) {
+		case "savePlayQueue":
+			saved = r.URL.Query()
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		case "getPlayQueue":
+			w.Write([]byte(`{"subsonic-response":{"status":"ok","playQueue":{"current":12,"position":61500,` +
+				`"changed":"2018-05-01T10:00:00.000Z","changedBy":"DSub","entry":[{"id":"11"},{"id":"12"}]}}}`))
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+
+	t.Run("save", func(t *testing.T) {
+		err := c.SavePlayQueue(context.Background(), []string{"1", "2"}, "2", 90500*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal([]string{"1", "2"}, saved["id"])
+		assert.Equal("2", saved.Get("current"))
+		assert.Equal("90500", saved.Get("position"))
+	})
+	t.Run("get", func(t *testing.T) {
+		q, err := c.PlayQueue(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, q)
+		assert.Equal("12", q.Current, "Should accept numeric IDs")
+		assert.Equal(61500*time.Millisecond, q.Position)
+		assert.Equal("DSub", q.ChangedBy)
+		assert.Equal(time.Date(2018, 5, 1, 10, 0, 0, 0, time.UTC), q.Changed)
+		assert.Len(q.Tracks, 2)
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// Podcasts returns the podcast channels, with their episodes if
+// includeEpisodes is true.
+func (c *Client) Podcasts(ctx context.Context, includeEpisodes bool) ([]*jamsonic.PodcastChannel, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getPodcasts")+"&includeEpisodes="+strconv.FormatBool(includeEpisodes))
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]*jamsonic.PodcastChannel, len(data.Podcasts.Channels))
+	for i, ch := range data.Podcasts.Channels {
+		channels[i] = &jamsonic.PodcastChannel{
+			ID:          ch.ID,
+			URL:         ch.URL,
+			Title:       ch.Title,
+			Description: ch.Description,
+			CoverArt:    ch.CoverArt,
+			Status:      ch.Status,
+		}
+		if includeEpisodes {
+			channels[i].Episodes = newEpisodes(ch.Episodes)
+		}
+	}
+	return channels, nil
+}
+
+// NewestPodcasts returns up to count of the newest episodes.
+func (c *Client) NewestPodcasts(ctx context.Context, count int) ([]*jamsonic.PodcastEpisode, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getNewestPodcasts")+"&count="+strconv.Itoa(count))
+	if err != nil {
+		return nil, err
+	}
+	return newEpisodes(data.NewestPodcasts.Episodes), nil
+}
+
+// DownloadPodcastEpisode asks the server to download the episode.
+func (c *Client) DownloadPodcastEpisode(ctx context.Context, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("downloadPodcastEpisode")+"&id="+url.QueryEscape(id))
+	return err
+}
+
+// CreatePodcastChannel subscribes to the podcast feed.
+func (c *Client) CreatePodcastChannel(ctx context.Context, feedURL string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("createPodcastChannel")+"&url="+url.QueryEscape(feedURL))
+	return err
+}
+
+// DeletePodcastChannel unsubscribes from the podcast.
+func (c *Client) DeletePodcastChannel(ctx context.Context, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("deletePodcastChannel")+"&id="+url.QueryEscape(id))
+	return err
+}
+
+// RefreshPodcasts asks the server to check the feeds for new episodes.
+func (c *Client) RefreshPodcasts(ctx context.Context) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("refreshPodcasts"))
+	return err
+}
+
+func newEpisodes(episodes []*podcastEpisode) []*jamsonic.PodcastEpisode {
+	result := make([]*jamsonic.PodcastEpisode, len(episodes))
+	for i, e := range episodes {
+		// The date is left as zero if it can't be parsed.
+		published, _ := time.Parse(time.RFC3339, e.PublishDate)
+		result[i] = &jamsonic.PodcastEpisode{
+			ID:          e.ID,
+			StreamID:    e.StreamID,
+			ChannelID:   e.ChannelID,
+			Title:       e.Title,
+			Description: e.Description,
+			Published:   published,
+			Status:      e.Status,
+			Duration:    time.Duration(e.Duration) * time.Second,
+			CoverArt:    e.CoverArt,
+		}
+	}
+	return result
+}
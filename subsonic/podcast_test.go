@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPodcasts(t *testing.T) {
+	assert := assert.New(t)
+	var last *url.URL
+	episodes := []*podcastEpisode{&podcastEpisode{
+		ID:          "E1",
+		StreamID:    "S1",
+		ChannelID:   "C1",
+		Title:       "Episode",
+		PublishDate: "2018-02-03T14:46:43.000Z",
+		Status:      "completed",
+		Duration:    3600,
+	}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL
+		writeServerReply(w, &apiData{Response: apiResponse{
+			Status:         "ok",
+			Podcasts:       podcasts{Channels: []*podcastChannel{&podcastChannel{ID: "C1", Title: "Channel", Status: "completed", Episodes: episodes}}},
+			NewestPodcasts: podcastEpisodes{Episodes: episodes},
+		}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+	expected := &jamsonic.PodcastEpisode{
+		ID:        "E1",
+		StreamID:  "S1",
+		ChannelID: "C1",
+		Title:     "Episode",
+		Published: time.Date(2018, 2, 3, 14, 46, 43, 0, time.UTC),
+		Status:    "completed",
+		Duration:  time.Hour,
+	}
+
+	t.Run("channels", func(t *testing.T) {
+		channels, err := c.Podcasts(context.Background(), true)
+		require.NoError(t, err)
+		require.Len(t, channels, 1)
+		assert.Equal("Channel", channels[0].Title)
+		require.Len(t, channels[0].Episodes, 1)
+		assert.Equal(expected, channels[0].Episodes[0])
+		assert.Equal("/rest/getPodcasts.view", last.Path)
+		assert.Equal("true", last.Query().Get("includeEpisodes"))
+
+		channels, err = c.Podcasts(context.Background(), false)
+		require.NoError(t, err)
+		assert.Nil(channels[0].Episodes)
+	})
+
+	t.Run("newest", func(t *testing.T) {
+		episodes, err := c.NewestPodcasts(context.Background(), 10)
+		require.NoError(t, err)
+		assert.Equal([]*jamsonic.PodcastEpisode{expected}, episodes)
+		assert.Equal("/rest/getNewestPodcasts.view", last.Path)
+		assert.Equal("10", last.Query().Get("count"))
+	})
+
+	t.Run("manage", func(t *testing.T) {
+		require.NoError(t, c.DownloadPodcastEpisode(context.Background(), "E1"))
+		assert.Equal("/rest/downloadPodcastEpisode.view", last.Path)
+		assert.Equal("E1", last.Query().Get("id"))
+		require.NoError(t, c.CreatePodcastChannel(context.Background(), "http://feed/rss?a=1"))
+		assert.Equal("/rest/createPodcastChannel.view", last.Path)
+		assert.Equal("http://feed/rss?a=1", last.Query().Get("url"))
+		require.NoError(t, c.DeletePodcastChannel(context.Background(), "C1"))
+		assert.Equal("/rest/deletePodcastChannel.view", last.Path)
+		assert.Equal("C1", last.Query().Get("id"))
+		require.NoError(t, c.RefreshPodcasts(context.Background()))
+		assert.Equal("/rest/refreshPodcasts.view", last.Path)
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"sync"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// SyncWorkers is the number of artists or albums downloaded at the same time
+// when the library is synced.
+var SyncWorkers = 10
+
+// progressFunc wraps the function set by SetSyncProgress so a nil function
+// can be stored.
+type progressFunc struct {
+	fn func(jamsonic.SyncProgress)
+}
+
+// SetSyncProgress sets the function called each time a library sync makes
+// progress. A nil function turns off the reports.
+func (c *Client) SetSyncProgress(fn func(jamsonic.SyncProgress)) {
+	c.syncProgress.Store(progressFunc{fn})
+}
+
+// syncWorkers returns the number of workers to use, at least one.
+func syncWorkers() int {
+	if SyncWorkers < 1 {
+		return 1
+	}
+	return SyncWorkers
+}
+
+// progress tracks a sync and reports the updates. The reports are made by
+// a goroutine of their own so the workers don't wait for them. Updates made
+// while a report is running are reported together.
+type progress struct {
+	mu      sync.Mutex
+	state   jamsonic.SyncProgress
+	report  func(jamsonic.SyncProgress)
+	updated chan struct{}
+	done    chan struct{}
+}
+
+// newProgress starts the reports. close must be called when the sync is
+// done.
+func (c *Client) newProgress() *progress {
+	pf, _ := c.syncProgress.Load().(progressFunc)
+	p := &progress{report: pf.fn, updated: make(chan struct{}, 1), done: make(chan struct{})}
+	go p.reports()
+	return p
+}
+
+// update changes the progress and asks for it to be reported.
+func (p *progress) update(fn func(s *jamsonic.SyncProgress)) {
+	p.mu.Lock()
+	fn(&p.state)
+	p.mu.Unlock()
+	select {
+	case p.updated <- struct{}{}:
+	default:
+		// A report of the latest state is already pending.
+	}
+}
+
+// reports reports the latest state after each update until closed.
+func (p *progress) reports() {
+	defer close(p.done)
+	for range p.updated {
+		if p.report == nil {
+			continue
+		}
+		p.mu.Lock()
+		s := p.state
+		s.Failures = append([]*jamsonic.SyncFailure(nil), p.state.Failures...)
+		p.mu.Unlock()
+		p.report(s)
+	}
+}
+
+// close waits for the last update to be reported.
+func (p *progress) close() {
+	close(p.updated)
+	<-p.done
+}
+
+// fail records the failure of the artist or album.
+func (p *progress) fail(name string, err error) {
+	p.update(func(s *jamsonic.SyncProgress) {
+		s.Failures = append(s.Failures, &jamsonic.SyncFailure{Name: name, Err: err})
+	})
+}
+
+// failures returns the failures so far.
+func (p *progress) failures() []*jamsonic.SyncFailure {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state.Failures
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// SimilarTracks returns tracks similar to the artist's using
+// getSimilarSongs2.
+func (c *Client) SimilarTracks(ctx context.Context, artistID string, count int) ([]*jamsonic.Track, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getSimilarSongs2")+"&id="+url.QueryEscape(artistID)+"&count="+strconv.Itoa(count))
+	if err != nil {
+		return nil, err
+	}
+	return newTracks(data.SimilarSongs.Songs), nil
+}
+
+// RandomTracks returns random tracks matching the filter using
+// getRandomSongs.
+func (c *Client) RandomTracks(ctx context.Context, filter *jamsonic.RadioFilter, count int) ([]*jamsonic.Track, error) {
+	u := c.makeRequestURL("getRandomSongs") + "&size=" + strconv.Itoa(count) + c.musicFolderParam()
+	if filter.Genre != "" {
+		u += "&genre=" + url.QueryEscape(filter.Genre)
+	}
+	if filter.FromYear != 0 {
+		u += "&fromYear=" + strconv.Itoa(filter.FromYear)
+	}
+	if filter.ToYear != 0 {
+		u += "&toYear=" + strconv.Itoa(filter.ToYear)
+	}
+	data, err := c.sendRequest(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return newTracks(data.RandomSongs.Songs), nil
+}
+
+func newTracks(songs []*song) []*jamsonic.Track {
+	tracks := make([]*jamsonic.Track, len(songs))
+	for i, s := range songs {
+		tracks[i] = newTrack(s)
+	}
+	return tracks
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadioTracks(t *testing.T) {
+	assert := assert.New(t)
+	var last *url.URL
+	songs := songList{Songs: []*song{&song{ID: "S1", ArtistID: "A1", Genre: "Rock"}}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = r.URL
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", SimilarSongs: songs, RandomSongs: songs}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	t.Run("similar", func(t *testing.T) {
+		tracks, err := c.SimilarTracks(context.Background(), "A1", 10)
+		require.NoError(t, err)
+		require.Len(t, tracks, 1)
+		assert.Equal("A1", tracks[0].ArtistID)
+		assert.Equal("Rock", tracks[0].Genre)
+		assert.Equal("/rest/getSimilarSongs2.view", last.Path)
+		assert.Equal("A1", last.Query().Get("id"))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		_, err := c.RandomTracks(context.Background(), &jamsonic.RadioFilter{Genre: "Rock", FromYear: 1990}, 5)
+		require.NoError(t, err)
+		assert.Equal("/rest/getRandomSongs.view", last.Path)
+		assert.Equal("5", last.Query().Get("size"))
+		assert.Equal("Rock", last.Query().Get("genre"))
+		assert.Equal("1990", last.Query().Get("fromYear"))
+		assert.Empty(last.Query().Get("toYear"))
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// Search uses search3 to find artists, albums and songs matching the query.
+// The artists and albums are returned without their albums and tracks.
+func (c *Client) Search(ctx context.Context, query string, count, offset int) (*jamsonic.SearchResult, error) {
+	n := strconv.Itoa(count)
+	o := strconv.Itoa(offset)
+	data, err := c.sendRequest(ctx, c.makeRequestURL("search3")+"&query="+url.QueryEscape(query)+
+		"&artistCount="+n+"&artistOffset="+o+
+		"&albumCount="+n+"&albumOffset="+o+
+		"&songCount="+n+"&songOffset="+o+c.musicFolderParam())
+	if err != nil {
+		return nil, err
+	}
+	artists, albums, tracks := data.SearchResult.convert()
+	return &jamsonic.SearchResult{Artists: artists, Albums: albums, Tracks: tracks}, nil
+}
+
+// convert returns the results as jamsonic types. It's used for both search
+// results and starred items.
+func (r *searchResult) convert() ([]*jamsonic.Artist, []*jamsonic.Album, []*jamsonic.Track) {
+	artists := make([]*jamsonic.Artist, len(r.Artists))
+	for i, a := range r.Artists {
+		artists[i] = newArtist(a)
+	}
+	albums := make([]*jamsonic.Album, len(r.Albums))
+	for i, a := range r.Albums {
+		albums[i] = newAlbum(a)
+	}
+	return artists, albums, newTracks(r.Songs)
+}
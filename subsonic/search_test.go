@@ -0,0 +1,54 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	assert := assert.New(t)
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", SearchResult: searchResult{
+			Artists: []*artist{&artist{ID: "A1", Name: "Artist1"}},
+			Albums:  []*album{&album{ID: "AA1", Name: "Album1", Artist: "Artist1", Year: 2001}},
+			Songs:   []*song{&song{ID: "S1", Title: "Song1", Artist: "Artist1", Album: "Album1"}},
+		}}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	res, err := c.Search(context.Background(), "art ist", 20, 40)
+	require.NoError(t, err)
+	assert.Equal("art ist", query.Get("query"))
+	assert.Equal("20", query.Get("songCount"))
+	assert.Equal("40", query.Get("albumOffset"))
+	assert.Equal(3, res.Len())
+	assert.Equal("Artist1", res.Artists[0].Name)
+	assert.Equal(uint32(2001), res.Albums[0].Year)
+	assert.Equal("Album1", res.Tracks[0].Album)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// ErrNoShareCreated is returned if the server doesn't return the new share.
+var ErrNoShareCreated = errors.New("no share returned by the server")
+
+// Shares returns the user's shares using getShares.
+func (c *Client) Shares(ctx context.Context) ([]*jamsonic.Share, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getShares"))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*jamsonic.Share, len(data.Shares.Shares))
+	for i, s := range data.Shares.Shares {
+		list[i] = newShare(s)
+	}
+	return list, nil
+}
+
+// CreateShare creates a public link to the tracks using createShare. A zero
+// expires means the link never expires.
+func (c *Client) CreateShare(ctx context.Context, trackIDs []string, description string, expires time.Time) (*jamsonic.Share, error) {
+	u := c.makeRequestURL("createShare")
+	for _, id := range trackIDs {
+		u += "&id=" + url.QueryEscape(id)
+	}
+	u += shareParams(description, expires)
+	data, err := c.sendRequest(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(data.Shares.Shares) == 0 {
+		return nil, ErrNoShareCreated
+	}
+	return newShare(data.Shares.Shares[0]), nil
+}
+
+// UpdateShare changes the description and the expiry of the share using
+// updateShare. A zero expires means the link never expires.
+func (c *Client) UpdateShare(ctx context.Context, id, description string, expires time.Time) error {
+	u := c.makeRequestURL("updateShare") + "&id=" + url.QueryEscape(id)
+	if expires.IsZero() {
+		// Zero removes the expiry.
+		u += "&expires=0"
+	}
+	_, err := c.sendRequest(ctx, u+shareParams(description, expires))
+	return err
+}
+
+// DeleteShare revokes the share using deleteShare.
+func (c *Client) DeleteShare(ctx context.Context, id string) error {
+	_, err := c.sendRequest(ctx, c.makeRequestURL("deleteShare")+"&id="+url.QueryEscape(id))
+	return err
+}
+
+// shareParams returns the description and expires parameters. The expiry
+// is sent in milliseconds since the epoch.
+func shareParams(description string, expires time.Time) string {
+	var p string
+	if description != "" {
+		p += "&description=" + url.QueryEscape(description)
+	}
+	if !expires.IsZero() {
+		p += "&expires=" + strconv.FormatInt(expires.UnixNano()/int64(time.Millisecond), 10)
+	}
+	return p
+}
+
+func newShare(s *share) *jamsonic.Share {
+	created, _ := time.Parse(time.RFC3339, s.Created)
+	expires, _ := time.Parse(time.RFC3339, s.Expires)
+	visited, _ := time.Parse(time.RFC3339, s.LastVisited)
+	return &jamsonic.Share{
+		ID:          s.ID,
+		URL:         s.URL,
+		Description: s.Description,
+		Username:    s.Username,
+		Created:     created,
+		Expires:     expires,
+		LastVisited: visited,
+		VisitCount:  s.VisitCount,
+		Tracks:      newTracks(s.Entries),
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShares(t *testing.T) {
+	assert := assert.New(t)
+	var query url.Values
+	const reply = `{"subsonic-response":{"status":"ok","shares":{"share":[{"id":"12",` +
+		`"url":"https://music.example.com/share/abc","description":"Road trip","username":"me",` +
+		`"created":"2018-05-01T10:00:00.000Z","expires":"2018-06-01T10:00:00.000Z","visitCount":3,` +
+		`"entry":[{"id":"1","title":"Song A"},{"id":"2","title":"Song B"}]}]}}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		switch methodName(r.URL.Path) {
+		case "createShare", "getShares":
+			w.Write([]byte(reply))
+		default:
+			writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+	expires := time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("create", func(t *testing.T) {
+		s, err := c.CreateShare(context.Background(), []string{"1", "2"}, "Road trip", expires)
+		require.NoError(t, err)
+		assert.Equal([]string{"1", "2"}, query["id"])
+		assert.Equal("Road trip", query.Get("description"))
+		assert.Equal("1527847200000", query.Get("expires"))
+		assert.Equal("12", s.ID)
+		assert.Equal("https://music.example.com/share/abc", s.URL)
+	})
+	t.Run("create_without_expiry", func(t *testing.T) {
+		_, err := c.CreateShare(context.Background(), []string{"1"}, "", time.Time{})
+		require.NoError(t, err)
+		_, ok := query["expires"]
+		assert.False(ok, "Expires should not be sent")
+	})
+	t.Run("get", func(t *testing.T) {
+		list, err := c.Shares(context.Background())
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		s := list[0]
+		assert.Equal("Road trip", s.Description)
+		assert.Equal(expires, s.Expires)
+		assert.True(s.LastVisited.IsZero())
+		assert.Equal(3, s.VisitCount)
+		assert.Len(s.Tracks, 2)
+	})
+	t.Run("update", func(t *testing.T) {
+		require.NoError(t, c.UpdateShare(context.Background(), "12", "Holiday", time.Time{}))
+		assert.Equal("12", query.Get("id"))
+		assert.Equal("Holiday", query.Get("description"))
+		assert.Equal("0", query.Get("expires"), "Zero should remove the expiry")
+	})
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, c.DeleteShare(context.Background(), "12"))
+		assert.Equal("12", query.Get("id"))
+	})
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"github.com/TcM1911/jamsonic"
+)
+
+// InternetRadioStations returns the internet radio stations on the server.
+func (c *Client) InternetRadioStations(ctx context.Context) ([]*jamsonic.InternetRadioStation, error) {
+	data, err := c.sendRequest(ctx, c.makeRequestURL("getInternetRadioStations"))
+	if err != nil {
+		return nil, err
+	}
+	stations := make([]*jamsonic.InternetRadioStation, len(data.RadioStations.Stations))
+	for i, s := range data.RadioStations.Stations {
+		stations[i] = &jamsonic.InternetRadioStation{
+			ID:          s.ID,
+			Name:        s.Name,
+			StreamURL:   s.StreamURL,
+			HomePageURL: s.HomePageURL,
+		}
+	}
+	return stations, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternetRadioStations(t *testing.T) {
+	assert := assert.New(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/rest/getInternetRadioStations.view", r.URL.Path)
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok", RadioStations: radioStations{Stations: []*radioStation{
+			&radioStation{ID: "1", Name: "Station", StreamURL: "http://radio/stream", HomePageURL: "http://radio"},
+		}}}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Username: "username", Host: ts.URL}}
+
+	stations, err := c.InternetRadioStations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stations, 1)
+	assert.Equal(&jamsonic.InternetRadioStation{ID: "1", Name: "Station", StreamURL: "http://radio/stream", HomePageURL: "http://radio"}, stations[0])
+	track := stations[0].Track()
+	assert.True(track.Live)
+	assert.Equal("http://radio/stream", track.StreamURL)
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// albumPageSize is the most albums the server returns per getAlbumList2
+// request.
+const albumPageSize = 500
+
+// FetchLibraryChanges returns the changes to the cached artists since the
+// last sync. getIndexes is first asked if the library has been modified since
+// then. If it has, all albums are listed with getAlbumList2 and only the new
+// albums and the albums with a new timestamp, name or song count are
+// downloaded with getAlbum. If any album fails, the sync time isn't moved
+// forward so the album is tried again.
+func (c *Client) FetchLibraryChanges(ctx context.Context, cached []*jamsonic.Artist, since time.Time) (*jamsonic.LibraryUpdate, error) {
+	modified, err := c.lastModified(ctx, since)
+	if err != nil {
+		return nil, syncError(ctx, err)
+	}
+	// Servers that don't say when the library was modified are always
+	// checked.
+	if !since.IsZero() && !modified.IsZero() && !modified.After(since) {
+		return &jamsonic.LibraryUpdate{Modified: since}, nil
+	}
+	artists, err := getAllArtists(ctx, c)
+	if err != nil {
+		return nil, syncError(ctx, err)
+	}
+	albums, err := c.allAlbums(ctx)
+	if err != nil {
+		return nil, syncError(ctx, err)
+	}
+
+	oldArtists := make(map[string]*jamsonic.Artist, len(cached))
+	oldAlbums := make(map[string]*jamsonic.Album)
+	for _, a := range cached {
+		oldArtists[a.ID] = a
+		for _, album := range a.Albums {
+			oldAlbums[album.ID] = album
+		}
+	}
+	byArtist := make(map[string][]*album)
+	var outdated []*album
+	for _, a := range albums {
+		byArtist[a.ArtistID] = append(byArtist[a.ArtistID], a)
+		if prev, ok := oldAlbums[a.ID]; !ok || albumOutdated(prev, a) {
+			outdated = append(outdated, a)
+		}
+	}
+	p := c.newProgress()
+	defer p.close()
+	fetched := c.fetchAlbums(ctx, p, outdated)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	update := &jamsonic.LibraryUpdate{Modified: modified}
+	if len(p.failures()) > 0 {
+		// The failed albums are tried again by the next sync.
+		update.Modified = since
+	}
+	found := make(map[string]bool, len(artists))
+	for _, a := range artists {
+		found[a.ID] = true
+		artist := newArtist(a)
+		prev, ok := oldArtists[a.ID]
+		changed := !ok || prev.Name != artist.Name || prev.Starred != artist.Starred ||
+			prev.Rating != artist.Rating || prev.CoverArt != artist.CoverArt ||
+			len(prev.Albums) != len(byArtist[a.ID])
+		for _, al := range byArtist[a.ID] {
+			if album, ok := fetched[al.ID]; ok {
+				album.Artist = a.Name
+				artist.Albums = append(artist.Albums, album)
+				changed = true
+			} else if album, ok := oldAlbums[al.ID]; ok {
+				artist.Albums = append(artist.Albums, album)
+			}
+		}
+		if changed {
+			update.Artists = append(update.Artists, artist)
+		}
+	}
+	for _, a := range cached {
+		if !found[a.ID] {
+			update.Removed = append(update.Removed, a.ID)
+		}
+	}
+	return update, nil
+}
+
+// lastModified returns when the library was last modified according to
+// getIndexes, or the zero time if the server doesn't say.
+func (c *Client) lastModified(ctx context.Context, since time.Time) (time.Time, error) {
+	u := c.makeRequestURL("getIndexes") + c.musicFolderParam()
+	if !since.IsZero() {
+		u += "&ifModifiedSince=" + strconv.FormatInt(since.UnixNano()/int64(time.Millisecond), 10)
+	}
+	data, err := c.sendRequest(ctx, u)
+	if err != nil || data.Indexes.LastModified == 0 {
+		return time.Time{}, err
+	}
+	return time.Unix(0, data.Indexes.LastModified*int64(time.Millisecond)), nil
+}
+
+// allAlbums returns all albums in the library, without their songs.
+func (c *Client) allAlbums(ctx context.Context) ([]*album, error) {
+	var albums []*album
+	for offset := 0; ; offset += albumPageSize {
+		data, err := c.sendRequest(ctx, c.makeRequestURL("getAlbumList2")+"&type=alphabeticalByArtist&size="+
+			strconv.Itoa(albumPageSize)+"&offset="+strconv.Itoa(offset)+c.musicFolderParam())
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, data.AlbumList.Albums...)
+		if len(data.AlbumList.Albums) < albumPageSize {
+			return albums, nil
+		}
+	}
+}
+
+// syncError returns the context's error if the sync was cancelled. A
+// cancelled request is otherwise reported as a *url.Error.
+func syncError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// albumOutdated returns true if the cached album differs from the album in
+// the album list.
+func albumOutdated(cached *jamsonic.Album, a *album) bool {
+	return !cached.Changed.Equal(albumChanged(a)) || cached.Name != a.Name ||
+		len(cached.Tracks) != a.SongCount || cached.CoverArt != a.CoverArt ||
+		cached.Starred != (a.Starred != "") || cached.Rating != a.UserRating
+}
+
+// fetchAlbums downloads the albums with their tracks. Albums that fail to
+// download are reported and left out.
+func (c *Client) fetchAlbums(ctx context.Context, p *progress, albums []*album) map[string]*jamsonic.Album {
+	p.update(func(s *jamsonic.SyncProgress) { s.AlbumsTotal = len(albums) })
+	jobs := make(chan *album)
+	fetched := make(map[string]*jamsonic.Album, len(albums))
+	var mu sync.Mutex
+	var wgroup sync.WaitGroup
+	for i := 0; i < syncWorkers(); i++ {
+		wgroup.Add(1)
+		go func() {
+			defer wgroup.Done()
+			for a := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				c.logger.DebugLog("Downloading tracks for " + a.Name)
+				p.update(func(s *jamsonic.SyncProgress) { s.Current = a.Artist + " - " + a.Name })
+				data, err := c.sendRequest(ctx, c.makeRequestURL("getAlbum")+"&id="+a.ID)
+				if err != nil {
+					c.logger.ErrorLog("Failed to process " + a.Name)
+					p.fail(a.Artist+" - "+a.Name, err)
+				} else {
+					album := newAlbum(&data.Album)
+					album.Tracks = albumTracks(data.Album.Songs, &data.Album)
+					mu.Lock()
+					fetched[a.ID] = album
+					mu.Unlock()
+				}
+				p.update(func(s *jamsonic.SyncProgress) { s.AlbumsDone++ })
+			}
+		}()
+	}
+	for _, a := range albums {
+		jobs <- a
+	}
+	close(jobs)
+	wgroup.Wait()
+	return fetched
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchLibraryChanges(t *testing.T) {
+	assert := assert.New(t)
+	var mu sync.Mutex
+	lastModified := int64(1000)
+	artists := []*artist{{ID: "A1", Name: "A1"}, {ID: "A2", Name: "A2"}}
+	albums := []*album{
+		{ID: "AA1", Name: "AA1", ArtistID: "A1", SongCount: 1, Created: "2018-01-01T10:00:00.000Z"},
+		{ID: "AB1", Name: "AB1", ArtistID: "A2", SongCount: 1, Created: "2018-01-01T10:00:00.000Z"},
+	}
+	var fetched []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		switch methodName(r.URL.Path) {
+		case "getIndexes":
+			writeServerReply(w, &apiData{Response: apiResponse{Indexes: indexes{LastModified: lastModified}}})
+		case "getArtists":
+			writeServerReply(w, &apiData{Response: apiResponse{ArtistList: artistList{Index: []index{{Artists: artists}}}}})
+		case "getAlbumList2":
+			list := albums
+			if q.Get("offset") != "0" {
+				list = nil
+			}
+			writeServerReply(w, &apiData{Response: apiResponse{AlbumList: albumList{Albums: list}}})
+		case "getAlbum":
+			fetched = append(fetched, q.Get("id"))
+			for _, a := range albums {
+				if a.ID == q.Get("id") {
+					reply := *a
+					reply.Songs = []*song{{ID: a.ID + "1", Title: a.Name}}
+					writeServerReply(w, &apiData{Response: apiResponse{Album: reply}})
+				}
+			}
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}, logger: jamsonic.DefaultLogger()}
+
+	var cached []*jamsonic.Artist
+	var since time.Time
+	t.Run("first_sync", func(t *testing.T) {
+		update, err := c.FetchLibraryChanges(context.Background(), nil, time.Time{})
+		require.NoError(t, err)
+		assert.Len(update.Artists, 2)
+		assert.ElementsMatch([]string{"AA1", "AB1"}, fetched)
+		assert.Equal(time.Unix(1, 0), update.Modified)
+		assert.Equal("A1", update.Artists[0].Albums[0].Artist)
+		assert.Len(update.Artists[0].Albums[0].Tracks, 1)
+		cached, since = update.Artists, update.Modified
+	})
+	t.Run("not_modified", func(t *testing.T) {
+		fetched = nil
+		update, err := c.FetchLibraryChanges(context.Background(), cached, since)
+		require.NoError(t, err)
+		assert.Empty(update.Artists)
+		assert.Empty(fetched)
+		assert.Equal(since, update.Modified)
+	})
+	t.Run("changed", func(t *testing.T) {
+		mu.Lock()
+		fetched = nil
+		lastModified = 2000
+		artists = artists[:1]
+		albums = []*album{
+			{ID: "AA1", Name: "AA1", ArtistID: "A1", SongCount: 1, Created: "2018-01-01T10:00:00.000Z"},
+			{ID: "AA2", Name: "AA2", ArtistID: "A1", SongCount: 1, Created: "2018-02-01T10:00:00.000Z"},
+		}
+		mu.Unlock()
+		update, err := c.FetchLibraryChanges(context.Background(), cached, since)
+		require.NoError(t, err)
+		assert.Equal([]string{"AA2"}, fetched, "Should only download the new album")
+		require.Len(t, update.Artists, 1)
+		assert.Len(update.Artists[0].Albums, 2)
+		assert.Equal([]string{"A2"}, update.Removed)
+	})
+	t.Run("cancel_paging", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		page := make([]*album, albumPageSize)
+		for i := range page {
+			page[i] = &album{ID: "P" + strconv.Itoa(i), ArtistID: "A1"}
+		}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch methodName(r.URL.Path) {
+			case "getArtists":
+				writeServerReply(w, &apiData{Response: apiResponse{ArtistList: artistList{Index: []index{{Artists: artists}}}}})
+			case "getAlbumList2":
+				if r.URL.Query().Get("offset") == "0" {
+					writeServerReply(w, &apiData{Response: apiResponse{AlbumList: albumList{Albums: page}}})
+					return
+				}
+				// Cancel while the next page is downloaded.
+				cancel()
+				<-r.Context().Done()
+			default:
+				writeServerReply(w, &apiData{Response: apiResponse{}})
+			}
+		}))
+		defer ts.Close()
+		c := &Client{Credentials: Credentials{Host: ts.URL}, logger: jamsonic.DefaultLogger()}
+		update, err := c.FetchLibraryChanges(ctx, nil, time.Time{})
+		assert.Equal(context.Canceled, err, "Should report the cancel")
+		assert.Nil(update)
+	})
+}
+
+func TestSyncProgress(t *testing.T) {
+	assert := assert.New(t)
+	var albumRequests, slow int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		q := r.URL.Query()
+		switch methodName(r.URL.Path) {
+		case "getArtists":
+			artists := []*artist{{ID: "A1", Name: "A1", AlbumCount: 2}, {ID: "A2", Name: "A2", AlbumCount: 1}}
+			writeServerReply(w, &apiData{Response: apiResponse{ArtistList: artistList{Index: []index{{Artists: artists}}}}})
+		case "getArtist":
+			if q.Get("id") == "A2" {
+				writeServerReply(w, &apiData{Response: apiResponse{Status: "failed", Error: &apiError{Code: int(CodeNotFound)}}})
+				return
+			}
+			albums := []*album{{ID: "AA1", Name: "AA1"}, {ID: "AA2", Name: "AA2"}}
+			writeServerReply(w, &apiData{Response: apiResponse{Artist: artist{ID: "A1", Name: "A1", Albums: albums}}})
+		case "getAlbum":
+			atomic.AddInt32(&albumRequests, 1)
+			writeServerReply(w, &apiData{Response: apiResponse{Album: album{ID: q.Get("id"), Songs: []*song{{ID: "1"}}}}})
+		}
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}, logger: jamsonic.DefaultLogger()}
+
+	t.Run("report", func(t *testing.T) {
+		var reports []jamsonic.SyncProgress
+		c.SetSyncProgress(func(p jamsonic.SyncProgress) { reports = append(reports, p) })
+		defer c.SetSyncProgress(nil)
+		artists, err := c.FetchLibrary(context.Background())
+		require.NoError(t, err)
+		assert.Len(artists, 1)
+		require.NotEmpty(t, reports)
+		last := reports[len(reports)-1]
+		assert.Equal(2, last.ArtistsDone)
+		assert.Equal(2, last.ArtistsTotal)
+		assert.Equal(3, last.AlbumsDone)
+		assert.Equal(3, last.AlbumsTotal)
+		require.Len(t, last.Failures, 1, "Should report the failed artist")
+		assert.Equal("A2", last.Failures[0].Name)
+	})
+	t.Run("workers", func(t *testing.T) {
+		defer func(n int) { SyncWorkers = n }(SyncWorkers)
+		SyncWorkers = 0
+		artists, err := c.FetchLibrary(context.Background())
+		assert.NoError(err, "Should use at least one worker")
+		assert.Len(artists, 1)
+	})
+	t.Run("slow_report", func(t *testing.T) {
+		release := make(chan struct{})
+		c.SetSyncProgress(func(p jamsonic.SyncProgress) { <-release })
+		defer c.SetSyncProgress(nil)
+		atomic.StoreInt32(&albumRequests, 0)
+		done := make(chan struct{})
+		go func() {
+			c.FetchLibrary(context.Background())
+			close(done)
+		}()
+		// The albums are downloaded while the first report is blocked.
+		deadline := time.Now().Add(time.Second)
+		for atomic.LoadInt32(&albumRequests) < 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(int32(2), atomic.LoadInt32(&albumRequests), "Should not wait for the report")
+		close(release)
+		<-done
+	})
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.SetSyncProgress(func(p jamsonic.SyncProgress) { cancel() })
+		defer c.SetSyncProgress(nil)
+		// The artists are still downloading when the report cancels.
+		atomic.StoreInt32(&slow, 1)
+		defer atomic.StoreInt32(&slow, 0)
+		artists, err := c.FetchLibrary(ctx)
+		assert.Equal(context.Canceled, err)
+		assert.Nil(artists)
+	})
+}
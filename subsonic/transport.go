@@ -0,0 +1,299 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// ErrNoCACertificates is returned if the CA file has no PEM certificates.
+var ErrNoCACertificates = errors.New("no certificates found in the CA file")
+
+// DefaultTransportConfig is used until SetTransport is called.
+var DefaultTransportConfig = TransportConfig{
+	ConnectTimeout: 10 * time.Second,
+	ReadTimeout:    30 * time.Second,
+	MaxRetries:     3,
+	RetryWait:      500 * time.Millisecond,
+}
+
+// TransportConfig configures the HTTP connections to the server.
+type TransportConfig struct {
+	// ConnectTimeout limits the time to connect, including the TLS
+	// handshake. Zero means no limit.
+	ConnectTimeout time.Duration
+	// ReadTimeout limits the time to wait for the response headers and for
+	// each read of the body. Zero means no limit.
+	ReadTimeout time.Duration
+	// MaxRetries is how many times an idempotent request is retried after
+	// a network error or a 5xx or 429 response.
+	MaxRetries int
+	// RetryWait is the wait before the first retry. It's doubled for each
+	// retry.
+	RetryWait time.Duration
+	// CAFile is a PEM file with root certificates trusted in addition to
+	// the system's.
+	CAFile string
+	// CertFile and KeyFile are the PEM files with the client certificate
+	// and its key.
+	CertFile string
+	KeyFile  string
+	// Proxy is the URL of a HTTP, HTTPS or SOCKS5 proxy, like
+	// socks5://localhost:1080. If empty, the proxy is taken from the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+}
+
+// transport is the HTTP client and retry settings shared by all clients.
+type transport struct {
+	client      *http.Client
+	readTimeout time.Duration
+	maxRetries  int
+	retryWait   time.Duration
+}
+
+var (
+	transportMu sync.RWMutex
+	// sharedTransport is used by all clients.
+	sharedTransport *transport
+)
+
+func init() {
+	// The default config has no files to load, so it can't fail.
+	sharedTransport, _ = newTransport(&DefaultTransportConfig)
+}
+
+// SetTransport configures the HTTP connections of all clients.
+func SetTransport(cfg *TransportConfig) error {
+	t, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+	transportMu.Lock()
+	sharedTransport = t
+	transportMu.Unlock()
+	return nil
+}
+
+func getTransport() *transport {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return sharedTransport
+}
+
+func newTransport(cfg *TransportConfig) (*transport, error) {
+	tlsConfig := &tls.Config{}
+	if cfg.CAFile != "" {
+		pem, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, ErrNoCACertificates
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxy = http.ProxyURL(u)
+	}
+	dialer := &net.Dialer{Timeout: cfg.ConnectTimeout, KeepAlive: 30 * time.Second}
+	return &transport{
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 proxy,
+				DialContext:           dialer.DialContext,
+				TLSClientConfig:       tlsConfig,
+				TLSHandshakeTimeout:   cfg.ConnectTimeout,
+				ResponseHeaderTimeout: cfg.ReadTimeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       90 * time.Second,
+			},
+		},
+		readTimeout: cfg.ReadTimeout,
+		maxRetries:  cfg.MaxRetries,
+		retryWait:   cfg.RetryWait,
+	}, nil
+}
+
+// idempotentMethods are retried even though they don't start with "get".
+var idempotentMethods = map[string]bool{
+	"ping":      true,
+	"stream":    true,
+	"download":  true,
+	"search3":   true,
+	"star":      true,
+	"unstar":    true,
+	"setRating": true,
+	// Saving the same queue again gives the same result.
+	"savePlayQueue": true,
+	// Creating a bookmark replaces the track's bookmark.
+	"createBookmark": true,
+	"updateShare":    true,
+}
+
+// methodName returns the API method of the request URL.
+func methodName(u string) string {
+	return strings.TrimSuffix(path.Base(strings.SplitN(u, "?", 2)[0]), ".view")
+}
+
+// isIdempotent returns true if the request can be sent again without
+// changing the result.
+func isIdempotent(method string) bool {
+	return strings.HasPrefix(method, "get") || idempotentMethods[method]
+}
+
+// do sends the request. Idempotent requests are retried with backoff after
+// network errors and 5xx and 429 responses. If the server has the formPost
+// extension, the query is sent as a POST form so the credentials are not in
+// the URL. The credentials are hidden in the returned error, since errors
+// are shown to the user and logged.
+func (c *Client) do(ctx context.Context, u string) (*http.Response, error) {
+	t := getTransport()
+	retries := 0
+	if isIdempotent(methodName(u)) {
+		retries = t.maxRetries
+	}
+	wait := t.retryWait
+	for attempt := 0; ; attempt++ {
+		res, err := c.send(ctx, t, u)
+		retry := err != nil || res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+		if !retry || attempt >= retries || ctx.Err() != nil {
+			return res, err
+		}
+		d := wait
+		if err == nil {
+			// Servers may say how long to wait.
+			if s, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil {
+				d = time.Duration(s) * time.Second
+			}
+			res.Body.Close()
+		}
+		if c.logger != nil {
+			c.logger.DebugLog("Retrying " + methodName(u) + " in " + d.String() + ".")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(d):
+		}
+		wait *= 2
+	}
+}
+
+// send sends the request once. The response body is closed and the request
+// is cancelled if a read of the body takes longer than the read timeout.
+func (c *Client) send(ctx context.Context, t *transport, u string) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var req *http.Request
+	var err error
+	if parts := strings.SplitN(u, "?", 2); len(parts) == 2 && c.hasCapability(jamsonic.FormPostCapability) {
+		req, err = http.NewRequest(http.MethodPost, parts[0], strings.NewReader(parts[1]))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequest(http.MethodGet, u, nil)
+	}
+	if err != nil {
+		cancel()
+		return nil, hideURLError(err)
+	}
+	res, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, hideURLError(err)
+	}
+	res.Body = newTimeoutBody(res.Body, t.readTimeout, cancel)
+	return res, nil
+}
+
+// hideURLError hides the credentials in the URL of the error.
+func hideURLError(err error) error {
+	if ue, ok := err.(*url.Error); ok {
+		ue.URL = hideCredentials(ue.URL)
+	}
+	return err
+}
+
+// timeoutBody cancels the request if a read takes longer than the timeout.
+type timeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+}
+
+func newTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) io.ReadCloser {
+	b := &timeoutBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	if timeout > 0 {
+		b.timer = time.AfterFunc(timeout, cancel)
+		b.timer.Stop()
+	}
+	return b
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if b.timer != nil {
+		b.timer.Reset(b.timeout)
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.timer != nil {
+		// The time between reads is up to the caller.
+		b.timer.Stop()
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package subsonic
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTransportConfig doesn't slow down the tests that hit failing servers.
+var testTransportConfig = TransportConfig{MaxRetries: 3, RetryWait: time.Millisecond}
+
+func init() {
+	SetTransport(&testTransportConfig)
+}
+
+func TestTransport(t *testing.T) {
+	assert := assert.New(t)
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch methodName(r.URL.Path) {
+		case "getPlaylist", "createPlaylist":
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "getPlaylists":
+			if n == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "getArtists":
+			<-r.Context().Done()
+			return
+		case "stream":
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		writeServerReply(w, &apiData{Response: apiResponse{Status: "ok"}})
+	}))
+	defer ts.Close()
+	c := &Client{Credentials: Credentials{Host: ts.URL}}
+
+	t.Run("retry_server_error", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := c.sendRequest(context.Background(), c.makeRequestURL("getPlaylist"))
+		assert.NoError(err)
+		assert.Equal(int32(3), atomic.LoadInt32(&calls))
+	})
+	t.Run("retry_too_many_requests", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := c.sendRequest(context.Background(), c.makeRequestURL("getPlaylists"))
+		assert.NoError(err)
+		assert.Equal(int32(2), atomic.LoadInt32(&calls))
+	})
+	t.Run("no_retry_for_changes", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		_, err := c.sendRequest(context.Background(), c.makeRequestURL("createPlaylist"))
+		assert.Error(err)
+		assert.Equal(int32(1), atomic.LoadInt32(&calls), "Should not send a change twice")
+	})
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.sendRequest(ctx, c.makeRequestURL("getArtists"))
+		assert.Error(err)
+		assert.Equal(context.DeadlineExceeded, ctx.Err())
+	})
+	t.Run("read_timeout", func(t *testing.T) {
+		cfg := testTransportConfig
+		cfg.ReadTimeout = 50 * time.Millisecond
+		cfg.MaxRetries = 0
+		require.NoError(t, SetTransport(&cfg))
+		defer SetTransport(&testTransportConfig)
+		res, err := c.do(context.Background(), c.makeRequestURL("stream"))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		start := time.Now()
+		_, err = ioutil.ReadAll(res.Body)
+		assert.Error(err, "Should stop a stalled read")
+		assert.True(time.Since(start) < 150*time.Millisecond)
+	})
+	t.Run("bad_ca_file", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "ca")
+		require.NoError(t, err)
+		f.Close()
+		defer os.Remove(f.Name())
+		assert.Equal(ErrNoCACertificates, SetTransport(&TransportConfig{CAFile: f.Name()}))
+	})
+}
+
+func TestIdempotent(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("getAlbum", methodName("http://host/rest/getAlbum.view?id=1"))
+	assert.Equal("stream", methodName("http://host/sub/rest/stream?id=1"))
+	assert.True(isIdempotent("getAlbum"))
+	assert.True(isIdempotent("ping"))
+	assert.False(isIdempotent("createPlaylist"))
+	assert.False(isIdempotent("scrobble"))
+}
@@ -18,9 +18,12 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/TcM1911/jamsonic"
@@ -42,9 +45,32 @@ type TUI struct {
 
 	// Provider of music
 	provider jamsonic.Provider
+	// ctx is passed to all provider calls. It's cancelled when the TUI
+	// stops so requests in flight don't outlive it.
+	ctx    context.Context
+	cancel context.CancelFunc
+	// syncCancel cancels the running library sync. It's nil when the
+	// library isn't being synced.
+	syncCancel context.CancelFunc
+	syncMu     sync.Mutex
+	// drawMu is held while the window is drawn, so other goroutines can
+	// change the primitives with queueUpdateDraw.
+	drawMu sync.Mutex
+	// tty is the terminal the escape sequences are written to.
+	tty *os.File
+	// clipboard is the text to copy to the terminal's clipboard after the
+	// next draw.
+	clipboard   string
+	clipboardMu sync.Mutex
 
 	// The music player controller.
 	player *jamsonic.Player
+	// localHandler plays the tracks on this computer. The player uses it
+	// unless the output is the server's jukebox.
+	localHandler jamsonic.StreamHandler
+	// radio keeps the play queue filled. It's nil if the provider can't
+	// suggest tracks.
+	radio *jamsonic.Radio
 	// Current duration of the track being played. This value is updated
 	// by the callback function for the player.
 	trackDuration time.Duration
@@ -58,6 +84,9 @@ type TUI struct {
 	// The bottom section of the TUI. Displays track duration and title.
 	// The content is updated by the callback function.
 	footer *tview.TextView
+	// coverArt shows the art of the current track next to the footer. It's
+	// nil if the provider doesn't have cover art.
+	coverArt *coverArt
 	// Middle section of the TUI.
 	pages *tview.Pages
 	// The Library page. This page is split up in two parts. The artistView and tracksView
@@ -80,10 +109,109 @@ type TUI struct {
 	// trackListed is used to map the track listing to the correct Track struct in the TUI track list.
 	trackListed map[string]*jamsonic.Track
 
+	// The Playlists page. Like the Library page, it's split in playlistView and
+	// playlistTracksView.
+	playlistsPage *tview.Flex
+	// Displays a selectable list of the cached playlists.
+	playlistView *tview.List
+	// Displays the tracks of the playlist selected in the playlistView.
+	playlistTracksView *tview.List
+	// playlists has the same order as the playlistView.
+	playlists []*jamsonic.Playlist
+
+	// searchQuery is the last search, shown again when the search is opened.
+	searchQuery string
+	// searchResults are the results of the last search.
+	searchResults []*searchItem
+	// searchIndex is the search result last jumped to in the library.
+	searchIndex int
+	// searchOffset is the offset of the last page of server results.
+	searchOffset int
+
+	// favoritesView lists the starred artists, albums and tracks.
+	favoritesView *tview.List
+	// favorites has the same order as the favoritesView.
+	favorites []*searchItem
+
+	// browsePage shows the album lists.
+	browsePage *tview.Flex
+	// browseAlbumsView displays the albums in the selected album list.
+	browseAlbumsView *tview.List
+	// browseQuery is the query for the last page in the browseAlbumsView.
+	browseQuery *jamsonic.AlbumListQuery
+	// browseAlbums has the same order as the browseAlbumsView.
+	browseAlbums []*jamsonic.Album
+
+	// podcastsPage shows the podcast channels and their episodes.
+	podcastsPage *tview.Flex
+	// podcastChannelsView lists the newest episodes option and the channels.
+	podcastChannelsView *tview.List
+	// podcastEpisodesView lists the episodes of the selected channel.
+	podcastEpisodesView *tview.List
+	// podcastChannels are the channels, with their episodes.
+	podcastChannels []*jamsonic.PodcastChannel
+	// newestEpisodes are the newest episodes of all channels.
+	newestEpisodes []*jamsonic.PodcastEpisode
+	// podcastEpisodes has the same order as the podcastEpisodesView.
+	podcastEpisodes []*jamsonic.PodcastEpisode
+	// podcastTracker saves the progress of the episodes. It's nil if the
+	// provider doesn't have podcasts.
+	podcastTracker *jamsonic.PodcastTracker
+	// queueSync saves the play queue on the server. It's nil if the
+	// provider can't save play queues.
+	queueSync *jamsonic.QueueSync
+
+	// bookmarksView lists the bookmarks saved on the server.
+	bookmarksView *tview.List
+	// bookmarks has the same order as the bookmarksView.
+	bookmarks []*jamsonic.Bookmark
+	// bookmarkTracker bookmarks the long tracks that are stopped midway.
+	// It's nil if the provider can't save bookmarks.
+	bookmarkTracker *jamsonic.BookmarkTracker
+
+	// nowPlayingView lists the tracks the other users are playing.
+	nowPlayingView *tview.List
+	// nowPlaying has the same order as the nowPlayingView.
+	nowPlaying []*jamsonic.NowPlaying
+
+	// sharesView lists the shared links.
+	sharesView *tview.List
+	// shares has the same order as the sharesView.
+	shares []*jamsonic.Share
+
+	// stationsView lists the internet radio stations.
+	stationsView *tview.List
+	// stations has the same order as the stationsView.
+	stations []*jamsonic.InternetRadioStation
+
+	// lyricsView shows the lyrics of the current track.
+	lyricsView *tview.TextView
+	// lyricsMu guards the lyrics fields. They are updated by the player's
+	// callback and when the lyrics are loaded.
+	lyricsMu sync.Mutex
+	// lyricsTrackID is the ID of the track the lyrics are shown for.
+	lyricsTrackID string
+	// lyrics are the lyrics shown, or nil if they are not loaded.
+	lyrics *jamsonic.Lyrics
+	// lyricsLine is the highlighted line for synced lyrics.
+	lyricsLine int
+
+	// closeDialog removes the open dialog. It's set when a dialog is shown.
+	closeDialog func()
+
 	// settingsList is the menu list with all settings categories.
 	settingsList *tview.List
+	// musicFolderDropDown chooses the music folder on the settings page.
+	musicFolderDropDown *tview.DropDown
+	// musicFolders are the server's music folders in the drop down, after
+	// the option for all folders.
+	musicFolders []*jamsonic.MusicFolder
 }
 
+// pageNames are the pages shown in the header. The page index is used as
+// the page name in the pages view.
+var pageNames = []string{"Library", "Playlists", "Favorites", "Browse", "Podcasts", "Bookmarks", "Now playing", "Shares", "Stations", "Lyrics", "Settings", "Log"}
+
 // New returns a TUI object. This should only be called once.
 func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger) *TUI {
 	tui := &TUI{
@@ -92,6 +220,12 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 		pages:  tview.NewPages(),
 		logger: logger,
 	}
+	tui.ctx, tui.cancel = context.WithCancel(context.Background())
+	tui.tty = os.Stdout
+	// Windows doesn't have /dev/tty.
+	if f, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		tui.tty = f
+	}
 
 	// Header
 	header := tview.NewTextView().SetRegions(true).SetWrap(false).SetDynamicColors(true)
@@ -101,8 +235,7 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 
 	tui.header = header
 
-	pageLists := []string{"Library", "Settings", "Log"}
-	for i, page := range pageLists {
+	for i, page := range pageNames {
 		fmt.Fprintf(header, `%d ["%d"][white]%s[white][""]  `, i+1, i, page)
 	}
 
@@ -111,17 +244,39 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 	tui.footer.SetBorder(true)
 	tui.drawFooter()
 
+	// The footer is made taller to fit the cover art.
+	var nowPlaying tview.Primitive = tui.footer
+	nowPlayingHeight := 3
+	tui.coverArt = tui.newCoverArt(client)
+	if tui.coverArt != nil {
+		nowPlaying = tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(tui.coverArt.view, artCols+2, 0, false).
+			AddItem(tui.footer, 0, 1, false)
+		nowPlayingHeight = artRows + 2
+	}
+	tui.app.SetAfterDrawFunc(tui.afterDraw)
+
 	// Layout
 	tui.window = tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(tui.header, 3, 1, false).
 		AddItem(tui.pages, 0, 1, true).
-		AddItem(tui.footer, 3, 1, false)
+		AddItem(nowPlaying, nowPlayingHeight, 1, false)
 
 	// Add pages
 	logPage := tui.createLogPage()
 	tui.pages.AddPage("0", tui.createLibraryPage(), true, true)
-	tui.pages.AddPage("1", tui.createSettingsPage(), true, false)
-	tui.pages.AddPage("2", logPage, true, false)
+	tui.pages.AddPage("1", tui.createPlaylistsPage(), true, false)
+	tui.pages.AddPage("2", tui.createFavoritesPage(), true, false)
+	tui.browsePage = tui.createBrowsePage()
+	tui.pages.AddPage("3", tui.browsePage, true, false)
+	tui.pages.AddPage("4", tui.createPodcastsPage(), true, false)
+	tui.pages.AddPage("5", tui.createBookmarksPage(), true, false)
+	tui.pages.AddPage("6", tui.createNowPlayingPage(), true, false)
+	tui.pages.AddPage("7", tui.createSharesPage(), true, false)
+	tui.pages.AddPage("8", tui.createStationsPage(), true, false)
+	tui.pages.AddPage("9", tui.createLyricsPage(), true, false)
+	tui.pages.AddPage("10", tui.createSettingsPage(), true, false)
+	tui.pages.AddPage("11", logPage, true, false)
 
 	// Set logger
 	logger.SetOutput(logPage)
@@ -134,10 +289,14 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 
 	/// To be moved
 	handlerLogger := logger.SubLogger("[Stream handler]")
-	streamHandler := native.New(handlerLogger)
+	tui.localHandler = native.New(handlerLogger)
+	output, err := db.Output()
+	if err != nil {
+		logger.ErrorLog("Failed to read the output: " + err.Error())
+	}
 	playerLogger := logger.SubLogger("[Player]")
 	logger.DebugLog("Starting the player.")
-	tui.player = jamsonic.NewPlayer(playerLogger, client, streamHandler, tui.playerCallback, 500)
+	tui.player = jamsonic.NewPlayer(playerLogger, client, tui.outputHandler(client, output), tui.playerCallback, 500)
 	go func() {
 		err := tui.player.Error
 		for {
@@ -148,6 +307,24 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 		}
 	}()
 	tui.provider = client
+	if rp, ok := client.(jamsonic.RadioProvider); ok {
+		tui.radio = jamsonic.NewRadio(tui.player, rp, logger.SubLogger("[Radio]"))
+	}
+	if _, ok := client.(jamsonic.PodcastProvider); ok {
+		tui.podcastTracker = jamsonic.NewPodcastTracker(tui.player, db, logger.SubLogger("[Podcasts]"))
+	}
+	if qp, ok := client.(jamsonic.PlayQueueProvider); ok {
+		tui.queueSync = jamsonic.NewQueueSync(tui.player, qp, logger.SubLogger("[Play queue]"))
+		nonUIBlockingCall(tui.announceSavedQueue)
+	}
+	if bp, ok := client.(jamsonic.BookmarkProvider); ok {
+		tui.bookmarkTracker = jamsonic.NewBookmarkTracker(tui.player, bp, logger.SubLogger("[Bookmarks]"))
+	}
+	// The pages are created before the provider is set.
+	nonUIBlockingCall(tui.populateStations)
+	nonUIBlockingCall(tui.populatePodcasts)
+	nonUIBlockingCall(tui.populateMusicFolders)
+	nonUIBlockingCall(tui.followNowPlaying)
 
 	// Hack to redraw the tracks list after the app has started.
 	// Otherwise the line is not generated with right width.
@@ -162,9 +339,70 @@ func New(db *storage.BoltDB, client jamsonic.Provider, logger *jamsonic.Logger)
 	return tui
 }
 
+// outputHandler returns the stream handler playing on the output. The local
+// handler is used if the provider doesn't have the output.
+func (tui *TUI) outputHandler(client jamsonic.Provider, output string) jamsonic.StreamHandler {
+	if jp, ok := client.(jamsonic.JukeboxProvider); ok && output == jamsonic.JukeboxOutput {
+		return jp.Jukebox(tui.logger.SubLogger("[Jukebox]"))
+	}
+	return tui.localHandler
+}
+
 // Run starts the TUI application.
 func (tui *TUI) Run() error {
-	return tui.app.SetRoot(tui.window, true).Run()
+	defer tui.cancel()
+	err := tui.app.SetRoot(&lockedPrimitive{Primitive: tui.window, mu: &tui.drawMu}, true).Run()
+	if tui.queueSync != nil {
+		tui.queueSync.Close()
+	}
+	if tui.bookmarkTracker != nil {
+		tui.bookmarkTracker.Close()
+	}
+	if tui.podcastTracker != nil {
+		tui.podcastTracker.Close()
+	}
+	if tui.tty != os.Stdout {
+		tui.tty.Close()
+	}
+	return err
+}
+
+// Player returns the music player controlled by the TUI.
+func (tui *TUI) Player() *jamsonic.Player {
+	return tui.player
+}
+
+// queueUpdateDraw changes the primitives from a goroutine other than the
+// event loop and redraws the screen. The vendored tview predates
+// Application.QueueUpdateDraw, so the update instead waits for the window
+// to not be drawn.
+func (tui *TUI) queueUpdateDraw(update func()) {
+	tui.drawMu.Lock()
+	update()
+	tui.drawMu.Unlock()
+	tui.app.Draw()
+}
+
+// lockedPrimitive holds the mutex while the primitive is drawn.
+type lockedPrimitive struct {
+	tview.Primitive
+	mu *sync.Mutex
+}
+
+func (p *lockedPrimitive) Draw(screen tcell.Screen) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Primitive.Draw(screen)
+}
+
+// afterDraw writes the escape sequences that have to follow the screen
+// update: the cover art graphics and the clipboard. They're written to the
+// terminal, like the screen, so they don't end up in a redirected stdout.
+func (tui *TUI) afterDraw(screen tcell.Screen) {
+	if tui.coverArt != nil && (CoverArtProtocol == Sixel || CoverArtProtocol == Kitty) {
+		tui.coverArt.drawGraphics(screen, tui.tty)
+	}
+	tui.writeClipboard(screen, tui.tty)
 }
 
 // drawFooter updates the footer with the latest information.
@@ -172,18 +410,35 @@ func (tui *TUI) Run() error {
 func (tui *TUI) drawFooter() {
 	min := int(tui.trackDuration.Minutes())
 	secs := int(tui.trackDuration.Seconds()) % 60
-	var title string
+	var title, details string
 	if tui.currentTrack != nil {
 		title = tui.currentTrack.Title
+		details = tui.currentTrack.Artist
+		if tui.currentTrack.Album != "" {
+			details += " - " + tui.currentTrack.Album
+		}
+		// Live streams announce what's playing.
+		if tui.currentTrack.Live {
+			if streamTitle := tui.player.StreamTitle(); streamTitle != "" {
+				title, details = streamTitle, tui.currentTrack.Title
+			}
+		}
 	}
 	tui.footer.Clear()
 	fmt.Fprintf(tui.footer, "%02d:%02d / %s", min, secs, title)
+	if tui.radio != nil && tui.radio.On() {
+		fmt.Fprint(tui.footer, tview.Escape("  [radio]"))
+	}
+	// Only visible when the footer is tall enough.
+	fmt.Fprint(tui.footer, "\n"+tview.Escape(details))
 	tui.app.Draw()
 }
 
 func (tui *TUI) playerCallback(data *jamsonic.CallbackData) {
 	tui.trackDuration = data.Duration
 	tui.currentTrack = data.CurrentTrack
+	tui.showCoverArt(data.CurrentTrack)
+	tui.updateLyrics(data.CurrentTrack, data.Duration)
 	tui.drawFooter()
 }
 
@@ -191,7 +446,31 @@ func switchPage(tui *TUI, page int) {
 	index := strconv.Itoa(page)
 	tui.pages.SwitchToPage(index)
 	tui.header.Highlight(index)
-	if page == 0 {
+	switch page {
+	case 0:
 		tui.app.SetFocus(tui.libraryView)
+	case 1:
+		tui.app.SetFocus(tui.playlistsPage)
+	case 2:
+		tui.app.SetFocus(tui.favoritesView)
+	case 3:
+		tui.app.SetFocus(tui.browsePage)
+	case 4:
+		// Update the progress markers.
+		tui.populateEpisodes(tui.podcastChannelsView.GetCurrentItem())
+		tui.app.SetFocus(tui.podcastsPage)
+	case 5:
+		// Bookmarks are also created when tracks are stopped.
+		nonUIBlockingCall(tui.populateBookmarks)
+		tui.app.SetFocus(tui.bookmarksView)
+	case 6:
+		tui.app.SetFocus(tui.nowPlayingView)
+	case 7:
+		nonUIBlockingCall(tui.populateShares)
+		tui.app.SetFocus(tui.sharesView)
+	case 8:
+		tui.app.SetFocus(tui.stationsView)
+	case 9:
+		tui.app.SetFocus(tui.lyricsView)
 	}
 }
@@ -0,0 +1,119 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+func (tui *TUI) createBookmarksPage() *tview.List {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Bookmarks")
+	tui.bookmarksView = list
+
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.bookmarks) && tui.bookmarkTracker != nil {
+			b := tui.bookmarks[index]
+			nonUIBlockingCall(func() {
+				tui.bookmarkTracker.Resume(b)
+			})
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'N':
+			tui.bookmarkCurrentTrack()
+			return nil
+		case 'D':
+			index := list.GetCurrentItem()
+			if index < len(tui.bookmarks) {
+				b := tui.bookmarks[index]
+				tui.showConfirm("Delete the bookmark of "+b.Track.Title+"?", func() {
+					tui.editBookmarks(func(t *jamsonic.BookmarkTracker) error {
+						return t.Delete(tui.ctx, b.Track.ID)
+					})
+				})
+			}
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	return list
+}
+
+// populateBookmarks gets the bookmarks from the provider and lists them.
+func (tui *TUI) populateBookmarks() {
+	provider, ok := tui.provider.(jamsonic.BookmarkProvider)
+	if !ok {
+		return
+	}
+	bookmarks, err := provider.Bookmarks(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the bookmarks: " + err.Error())
+		return
+	}
+	tui.bookmarks = bookmarks
+	current := tui.bookmarksView.GetCurrentItem()
+	tui.bookmarksView.Clear()
+	for _, b := range bookmarks {
+		line := b.Track.Title
+		if b.Track.Artist != "" {
+			line += " - " + b.Track.Artist
+		}
+		line += " (at " + durationString(b.Position) + ")"
+		tui.bookmarksView.AddItem(line, b.Comment, 0, nil)
+	}
+	if current < len(bookmarks) {
+		tui.bookmarksView.SetCurrentItem(current)
+	}
+	tui.app.Draw()
+}
+
+// bookmarkCurrentTrack asks for a comment and bookmarks the current track at
+// the position it's played.
+func (tui *TUI) bookmarkCurrentTrack() {
+	track := tui.player.CurrentTrack()
+	if track == nil || track.Live {
+		tui.logger.InfoLog("No track to bookmark is playing.")
+		return
+	}
+	position := tui.player.Position()
+	tui.showInput("Bookmark comment", "", func(comment string) {
+		tui.editBookmarks(func(t *jamsonic.BookmarkTracker) error {
+			return t.Create(tui.ctx, track, position, comment)
+		})
+	})
+}
+
+// editBookmarks runs the change in the background and fetches the bookmarks
+// again.
+func (tui *TUI) editBookmarks(edit func(t *jamsonic.BookmarkTracker) error) {
+	if tui.bookmarkTracker == nil {
+		tui.logger.ErrorLog("The provider doesn't support bookmarks.")
+		return
+	}
+	nonUIBlockingCall(func() {
+		if err := edit(tui.bookmarkTracker); err != nil {
+			tui.logger.ErrorLog("Failed to update the bookmarks: " + err.Error())
+			return
+		}
+		tui.populateBookmarks()
+	})
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const (
+	// albumPageSize is the number of albums fetched per page.
+	albumPageSize = 50
+	// moreAlbumsOption is the last entry in the albums if the server may
+	// have more albums.
+	moreAlbumsOption = "<More albums>"
+)
+
+// albumLists are the lists shown on the Browse page.
+var albumLists = []struct {
+	name     string
+	listType jamsonic.AlbumListType
+}{
+	{"Recently played", jamsonic.RecentAlbums},
+	{"Most played", jamsonic.FrequentAlbums},
+	{"Recently added", jamsonic.NewestAlbums},
+	{"Random", jamsonic.RandomAlbums},
+	{"Highest rated", jamsonic.HighestRatedAlbums},
+	{"Starred", jamsonic.StarredAlbums},
+	{"By name", jamsonic.AlbumsByName},
+	{"By artist", jamsonic.AlbumsByArtist},
+	{"By year...", jamsonic.AlbumsByYear},
+	{"By genre...", jamsonic.AlbumsByGenre},
+}
+
+func (tui *TUI) createBrowsePage() *tview.Flex {
+	lists := tview.NewList().ShowSecondaryText(false)
+	lists.SetBorder(true).SetTitle("Lists")
+	for _, l := range albumLists {
+		lists.AddItem(l.name, "", 0, nil)
+	}
+	albums := tview.NewList().ShowSecondaryText(false)
+	albums.SetBorder(true).SetTitle("Albums")
+	tui.browseAlbumsView = albums
+
+	lists.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		query := &jamsonic.AlbumListQuery{Type: albumLists[index].listType, Count: albumPageSize}
+		switch query.Type {
+		case jamsonic.AlbumsByYear:
+			tui.showInput("Year or years (1990-1999)", "", func(text string) {
+				from, to, err := parseYears(text)
+				if err != nil {
+					tui.logger.ErrorLog("Invalid year: " + text)
+					return
+				}
+				query.FromYear, query.ToYear = from, to
+				tui.browse(query)
+			})
+		case jamsonic.AlbumsByGenre:
+			tui.showInput("Genre", "", func(text string) {
+				query.Genre = text
+				tui.browse(query)
+			})
+		default:
+			tui.browse(query)
+		}
+	})
+	lists.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			tui.app.SetFocus(albums)
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	albums.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.browseAlbums) {
+			tui.playTracksNow(tui.searchResultTracks(&searchItem{album: tui.browseAlbums[index]}))
+			return
+		}
+		// Fetch the next page.
+		next := *tui.browseQuery
+		next.Offset += albumPageSize
+		tui.browse(&next)
+	})
+	albums.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			tui.app.SetFocus(lists)
+			return nil
+		}
+		index := albums.GetCurrentItem()
+		if index >= len(tui.browseAlbums) {
+			return tui.vimBindings(tui.musicControl(event))
+		}
+		item := &searchItem{album: tui.browseAlbums[index]}
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(tui.searchResultTracks(item)...)
+			return nil
+		case 'l':
+			tui.jumpToResult(item)
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(tui.shareControl(tui.favoriteControl(event, item), item)))
+	})
+
+	return tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(lists, 0, 1, true).
+		AddItem(albums, 0, 2, false)
+}
+
+// browse fetches the album list in the background. If the query has an
+// offset, the albums are added to the current list.
+func (tui *TUI) browse(query *jamsonic.AlbumListQuery) {
+	lister, ok := tui.provider.(jamsonic.AlbumLister)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support album lists.")
+		return
+	}
+	nonUIBlockingCall(func() {
+		albums, err := lister.AlbumList(tui.ctx, query)
+		if err != nil {
+			tui.logger.ErrorLog("Failed to get the albums: " + err.Error())
+			return
+		}
+		if query.Offset > 0 {
+			albums = append(tui.browseAlbums, albums...)
+		}
+		tui.browseQuery = query
+		tui.browseAlbums = albums
+		view := tui.browseAlbumsView
+		view.Clear()
+		for _, a := range albums {
+			line := fmt.Sprintf("%s - %s", a.Name, a.Artist)
+			if a.Year != 0 {
+				line += " {" + strconv.Itoa(int(a.Year)) + "}"
+			}
+			view.AddItem(line+starMark(a.Starred)+ratingMark(a.Rating), "", 0, nil)
+		}
+		if len(albums) == query.Offset+albumPageSize {
+			view.AddItem(moreAlbumsOption, "", 0, nil)
+		}
+		view.SetCurrentItem(query.Offset)
+		tui.app.SetFocus(view)
+		tui.app.Draw()
+	})
+}
+
+// parseYears parses a year or a range of years like "1990-1999".
+func parseYears(s string) (int, int, error) {
+	parts := strings.SplitN(s, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 1 {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	return from, to, err
+}
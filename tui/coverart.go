@@ -0,0 +1,370 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+
+	// Decoders for the cover art formats.
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/TcM1911/jamsonic/storage"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// Protocols used to draw the cover art.
+const (
+	// HalfBlocks draws the art with colored half-block characters. It works
+	// in all terminals with true color support.
+	HalfBlocks = "halfblock"
+	// Sixel draws the art with sixel graphics.
+	Sixel = "sixel"
+	// Kitty draws the art with the kitty graphics protocol.
+	Kitty = "kitty"
+	// NoCoverArt turns off the cover art.
+	NoCoverArt = "off"
+)
+
+// CoverArtProtocol is the protocol used to draw the cover art.
+var CoverArtProtocol = HalfBlocks
+
+const (
+	// artCols and artRows are the size of the cover art in cells.
+	artCols = 12
+	artRows = 6
+	// cellWidth and cellHeight are the assumed size of a cell in pixels.
+	// They are used to size sixel images.
+	cellWidth  = 8
+	cellHeight = 16
+	// coverArtSize is the image size requested from the provider.
+	coverArtSize = 300
+)
+
+// coverArt holds the state of the cover art in the now playing area.
+type coverArt struct {
+	sync.Mutex
+	// provider serves the images, usually through the disk cache.
+	provider jamsonic.CoverArtProvider
+	// view is the box the art is drawn in.
+	view *tview.TextView
+	// id is the ID of the art shown or being loaded.
+	id string
+	// graphics is the escape sequence drawing the art with sixel or kitty.
+	graphics string
+	// changed is set when the graphics need to be written again.
+	changed bool
+	// stale is set if an old sixel image is left on the screen.
+	stale bool
+	// drawnAt is where the graphics were last written.
+	drawnAt [4]int
+}
+
+// newCoverArt returns the cover art state if the provider supports cover
+// art and it's not turned off.
+func (tui *TUI) newCoverArt(provider jamsonic.Provider) *coverArt {
+	p, ok := provider.(jamsonic.CoverArtProvider)
+	if !ok || CoverArtProtocol == NoCoverArt {
+		return nil
+	}
+	cache, err := storage.NewCoverArtCache(storage.CoverArtDir(), storage.CoverArtCacheSize, p)
+	if err != nil {
+		tui.logger.ErrorLog("Can't create the cover art cache: " + err.Error())
+	} else {
+		p = cache
+	}
+	view := tview.NewTextView().SetWrap(false).SetDynamicColors(true)
+	view.SetBorder(true)
+	return &coverArt{provider: p, view: view}
+}
+
+// showCoverArt loads the art for the track in the background, if it's not
+// already shown.
+func (tui *TUI) showCoverArt(track *jamsonic.Track) {
+	art := tui.coverArt
+	if art == nil {
+		return
+	}
+	var id string
+	if track != nil {
+		id = track.CoverArt
+	}
+	art.Lock()
+	defer art.Unlock()
+	if id == art.id {
+		return
+	}
+	art.id = id
+	go tui.loadCoverArt(id)
+}
+
+// loadCoverArt fetches, scales and encodes the art. Only the result is
+// handed to the draw loop.
+func (tui *TUI) loadCoverArt(id string) {
+	art := tui.coverArt
+	var img image.Image
+	if id != "" {
+		r, err := art.provider.CoverArt(tui.ctx, id, coverArtSize)
+		if err == nil {
+			img, _, err = image.Decode(r)
+			r.Close()
+		}
+		if err != nil {
+			tui.logger.DebugLog("Failed to get the cover art: " + err.Error())
+			img = nil
+		}
+	}
+	var text, graphics string
+	if img != nil {
+		switch CoverArtProtocol {
+		case Sixel:
+			graphics = sixel(fit(img, artCols*cellWidth, artRows*cellHeight))
+		case Kitty:
+			graphics = kitty(fit(img, artCols*cellWidth, artRows*cellHeight), artCols, artRows)
+		default:
+			text = halfBlocks(fit(img, artCols, artRows*2))
+		}
+	}
+
+	art.Lock()
+	if id != art.id {
+		// The track changed while loading.
+		art.Unlock()
+		return
+	}
+	art.stale = art.stale || art.graphics != ""
+	art.graphics = graphics
+	art.changed = true
+	art.view.Clear()
+	fmt.Fprint(art.view, text)
+	art.Unlock()
+	tui.app.Draw()
+}
+
+// drawGraphics writes the sixel or kitty graphics into the art box after the
+// screen is drawn. The graphics are only written when they have changed or
+// the box has moved.
+func (art *coverArt) drawGraphics(screen tcell.Screen, tty io.Writer) {
+	art.Lock()
+	defer art.Unlock()
+	x, y, w, h := art.view.GetInnerRect()
+	rect := [4]int{x, y, w, h}
+	if !art.changed && rect == art.drawnAt {
+		return
+	}
+	art.changed = false
+	art.drawnAt = rect
+	// The graphics have to be written after the cells under them.
+	screen.Show()
+	if CoverArtProtocol == Kitty {
+		fmt.Fprint(tty, "\x1b_Ga=d\x1b\\")
+	} else if art.stale {
+		// Let tcell repaint the cells covered by the old image.
+		screen.Sync()
+		art.stale = false
+	}
+	if art.graphics == "" || w < artCols || h < artRows {
+		return
+	}
+	fmt.Fprintf(tty, "\x1b7\x1b[%d;%dH%s\x1b8", y+1, x+1, art.graphics)
+}
+
+// fit scales the image to fit in a width x height image, keeping the
+// aspect ratio. Each pixel is the average of the pixels it covers. The
+// image is centered and the rest is transparent.
+func fit(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	w, h := width, b.Dy()*width/b.Dx()
+	if h > height {
+		w, h = b.Dx()*height/b.Dy(), height
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	offX, offY := (width-w)/2, (height-h)/2
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := b.Min.Y + (y+1)*b.Dy()/h
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := b.Min.X + (x+1)*b.Dx()/w
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := img.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr, g+cg, bl+cb, n+1
+				}
+			}
+			dst.SetRGBA(offX+x, offY+y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(bl / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+	return dst
+}
+
+// halfBlocks draws the image with upper half blocks. The foreground color
+// is the upper pixel and the background color the lower pixel, so each cell
+// shows two pixels. A lower half block is used if only the upper pixel is
+// transparent.
+func halfBlocks(img *image.RGBA) string {
+	var b strings.Builder
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		if y > bounds.Min.Y {
+			b.WriteString("\n")
+		}
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top, bottom := img.RGBAAt(x, y), img.RGBAAt(x, y+1)
+			switch {
+			case top.A == 0 && bottom.A == 0:
+				b.WriteString("[-:-] ")
+			case top.A == 0:
+				fmt.Fprintf(&b, "[%s:-]▄", tagColor(bottom))
+			default:
+				fmt.Fprintf(&b, "[%s:%s]▀", tagColor(top), tagColor(bottom))
+			}
+		}
+		b.WriteString("[-:-]")
+	}
+	return b.String()
+}
+
+// tagColor returns the color for a tview color tag. Transparent pixels use
+// the default color.
+func tagColor(c color.RGBA) string {
+	if c.A == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// sixel encodes the image as sixel graphics with a 6x6x6 color cube.
+// Transparent pixels are not drawn.
+func sixel(img *image.RGBA) string {
+	var b strings.Builder
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	index := func(x, y int) int {
+		c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+		if c.A == 0 {
+			return -1
+		}
+		q := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+		return q(c.R)*36 + q(c.G)*6 + q(c.B)
+	}
+	// P2=1 leaves the pixels without a color unchanged.
+	fmt.Fprintf(&b, "\x1bP0;1q\"1;1;%d;%d", w, h)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+	for y := 0; y < h; y += 6 {
+		var used [216]bool
+		for x := 0; x < w; x++ {
+			for k := 0; k < 6 && y+k < h; k++ {
+				if c := index(x, y+k); c >= 0 {
+					used[c] = true
+				}
+			}
+		}
+		for c := range used {
+			if !used[c] {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", c)
+			var last byte
+			run := 0
+			flush := func() {
+				if run > 3 {
+					fmt.Fprintf(&b, "!%d%c", run, last)
+				} else {
+					b.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for k := 0; k < 6 && y+k < h; k++ {
+					if index(x, y+k) == c {
+						bits |= 1 << uint(k)
+					}
+				}
+				ch := 63 + bits
+				if ch != last && run > 0 {
+					flush()
+					run = 0
+				}
+				last = ch
+				run++
+			}
+			flush()
+			// Back to the start of the band for the next color.
+			b.WriteString("$")
+		}
+		b.WriteString("-")
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// kitty encodes the image for the kitty graphics protocol. The image is sent
+// as PNG in chunks and scaled by the terminal to cols x rows cells.
+func kitty(img *image.RGBA, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+	const chunkSize = 4096
+	var b strings.Builder
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
+		more := 1
+		if end >= len(data) {
+			end, more = len(data), 0
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, data[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+	}
+	return b.String()
+}
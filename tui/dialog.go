@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// dialogPage is the name of the page used for dialogs. Only one dialog is
+// shown at the time.
+const dialogPage = "dialog"
+
+// dialogOpen returns true if a dialog is shown. Global key bindings are
+// passed on to the dialog while it's open.
+func (tui *TUI) dialogOpen() bool {
+	return tui.pages.HasPage(dialogPage)
+}
+
+// openDialog adds the dialog page on top of the current page and focuses p.
+// When the dialog is closed, the focus is given back.
+func (tui *TUI) openDialog(page, p tview.Primitive) {
+	previous := tui.app.GetFocus()
+	tui.closeDialog = func() {
+		tui.pages.RemovePage(dialogPage)
+		tui.app.SetFocus(previous)
+	}
+	tui.pages.AddPage(dialogPage, page, true, true)
+	tui.app.SetFocus(p)
+}
+
+// showDialog displays the primitive centered on top of the current page.
+func (tui *TUI) showDialog(p tview.Primitive, width, height int) {
+	tui.openDialog(centered(p, width, height), p)
+}
+
+// centered returns a layout with the primitive centered in the given size.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// showInput asks for a text. The done function is only called if the text
+// is entered and not empty.
+func (tui *TUI) showInput(title, value string, done func(text string)) {
+	input := tview.NewInputField().SetText(value)
+	input.SetBorder(true).SetTitle(title)
+	input.SetDoneFunc(func(key tcell.Key) {
+		tui.closeDialog()
+		if key == tcell.KeyEnter && input.GetText() != "" {
+			done(input.GetText())
+		}
+	})
+	tui.showDialog(input, 50, 3)
+}
+
+// showConfirm asks the user to confirm the action.
+func (tui *TUI) showConfirm(text string, done func()) {
+	modal := tview.NewModal().SetText(text).AddButtons([]string{"Yes", "No"})
+	modal.SetDoneFunc(func(index int, _ string) {
+		tui.closeDialog()
+		if index == 0 {
+			done()
+		}
+	})
+	tui.openDialog(modal, modal)
+}
+
+// showChoice lets the user select one of the options.
+func (tui *TUI) showChoice(title string, options []string, done func(index int)) {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(title)
+	for _, o := range options {
+		list.AddItem(o, "", 0, nil)
+	}
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		tui.closeDialog()
+		done(index)
+	})
+	list.SetDoneFunc(func() {
+		tui.closeDialog()
+	})
+	list.SetInputCapture(tui.vimBindings)
+	height := len(options) + 2
+	if height > 20 {
+		height = 20
+	}
+	tui.showDialog(list, 50, height)
+}
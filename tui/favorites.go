@@ -0,0 +1,210 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+func (tui *TUI) createFavoritesPage() *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle("Favorites")
+	tui.favoritesView = list
+
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.favorites) {
+			tui.playTracksNow(tui.searchResultTracks(tui.favorites[index]))
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := list.GetCurrentItem()
+		if index >= len(tui.favorites) {
+			return tui.vimBindings(tui.musicControl(event))
+		}
+		item := tui.favorites[index]
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(tui.searchResultTracks(item)...)
+			return nil
+		case 'l':
+			tui.jumpToResult(item)
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(tui.shareControl(tui.favoriteControl(event, item), item)))
+	})
+
+	tui.populateFavorites()
+	return list
+}
+
+// populateFavorites lists the cached starred items.
+func (tui *TUI) populateFavorites() {
+	favorites, err := tui.db.Favorites()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the favorites: " + err.Error())
+		return
+	}
+	tui.favorites = searchItems(&jamsonic.SearchResult{
+		Artists: favorites.Artists,
+		Albums:  favorites.Albums,
+		Tracks:  favorites.Tracks,
+	})
+	current := tui.favoritesView.GetCurrentItem()
+	tui.favoritesView.Clear()
+	for _, item := range tui.favorites {
+		_, rating := item.favorite()
+		tui.favoritesView.AddItem(item.String()+ratingMark(rating), "", 0, nil)
+	}
+	if current < len(tui.favorites) {
+		tui.favoritesView.SetCurrentItem(current)
+	}
+}
+
+// favoriteControl handles the keys to star and rate the item. 's' toggles
+// the star and '0' to '5' sets the rating.
+func (tui *TUI) favoriteControl(event *tcell.EventKey, item *searchItem) *tcell.EventKey {
+	if event == nil {
+		return nil
+	}
+	if item == nil {
+		return event
+	}
+	r := event.Rune()
+	switch {
+	case r == 's':
+		starred, _ := item.favorite()
+		tui.updateFavorite(item, func(m jamsonic.FavoritesManager, kind jamsonic.ItemKind, id string) error {
+			if starred {
+				return m.Unstar(tui.ctx, kind, id)
+			}
+			return m.Star(tui.ctx, kind, id)
+		}, func(starred *bool, _ *int) {
+			*starred = !*starred
+		})
+		return nil
+	case r >= '0' && r <= '5':
+		rating := int(r - '0')
+		tui.updateFavorite(item, func(m jamsonic.FavoritesManager, _ jamsonic.ItemKind, id string) error {
+			return m.SetRating(tui.ctx, id, rating)
+		}, func(_ *bool, r *int) {
+			*r = rating
+		})
+		return nil
+	}
+	return event
+}
+
+// updateFavorite sends the change to the server in the background. The
+// library cache is updated with the change and the favorites are fetched
+// again.
+func (tui *TUI) updateFavorite(item *searchItem, send func(m jamsonic.FavoritesManager, kind jamsonic.ItemKind, id string) error, apply func(starred *bool, rating *int)) {
+	m, ok := tui.provider.(jamsonic.FavoritesManager)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support starring and rating.")
+		return
+	}
+	kind, id := item.kind()
+	nonUIBlockingCall(func() {
+		if err := send(m, kind, id); err != nil {
+			tui.logger.ErrorLog("Failed to update the favorites: " + err.Error())
+			return
+		}
+		// Update the item and the matching item in the library.
+		item.apply(apply)
+		starred, rating := item.favorite()
+		artist, album, track := tui.locate(item)
+		lib := &searchItem{artist: artist}
+		if track != nil {
+			lib = &searchItem{track: track}
+		} else if album != nil {
+			lib = &searchItem{album: album}
+		}
+		if artist != nil {
+			lib.apply(func(s *bool, r *int) {
+				*s, *r = starred, rating
+			})
+		}
+		if err := tui.db.SaveArtists(tui.libraryArtists()); err != nil {
+			tui.logger.ErrorLog("Failed to save the library: " + err.Error())
+		}
+		if err := jamsonic.RefreshFavorites(tui.ctx, tui.db, m); err != nil {
+			tui.logger.ErrorLog("Failed to get the favorites: " + err.Error())
+		}
+		tui.populateFavorites()
+		if current := tui.artistView.GetCurrentItem(); current < len(tui.artists) {
+			tui.populateTracks(tui.artists[current])
+		}
+		tui.app.Draw()
+	})
+}
+
+// kind returns the kind and ID of the item.
+func (i *searchItem) kind() (jamsonic.ItemKind, string) {
+	switch {
+	case i.artist != nil:
+		return jamsonic.ArtistItem, i.artist.ID
+	case i.album != nil:
+		return jamsonic.AlbumItem, i.album.ID
+	default:
+		return jamsonic.TrackItem, i.track.ID
+	}
+}
+
+// favorite returns if the item is starred and its rating.
+func (i *searchItem) favorite() (bool, int) {
+	switch {
+	case i.artist != nil:
+		return i.artist.Starred, i.artist.Rating
+	case i.album != nil:
+		return i.album.Starred, i.album.Rating
+	default:
+		return i.track.Starred, i.track.Rating
+	}
+}
+
+// apply calls the function with the item's starred and rating fields.
+func (i *searchItem) apply(f func(starred *bool, rating *int)) {
+	switch {
+	case i.artist != nil:
+		f(&i.artist.Starred, &i.artist.Rating)
+	case i.album != nil:
+		f(&i.album.Starred, &i.album.Rating)
+	default:
+		f(&i.track.Starred, &i.track.Rating)
+	}
+}
+
+// starMark is added to starred items in the library.
+func starMark(starred bool) string {
+	if starred {
+		return " *"
+	}
+	return ""
+}
+
+// ratingMark shows the rating, if any.
+func ratingMark(rating int) string {
+	if rating == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d/5)", rating)
+}
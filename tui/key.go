@@ -58,11 +58,48 @@ func (tui *TUI) globalControl(event *tcell.EventKey) *tcell.EventKey {
 	if event == nil {
 		return nil
 	}
+	// Let the dialog handle all keys, including Escape to close it.
+	if tui.dialogOpen() {
+		return event
+	}
+	// Alt+1 to Alt+9 and Alt+0 go directly to the first ten pages.
+	if event.Key() == tcell.KeyRune && event.Modifiers()&tcell.ModAlt != 0 {
+		r := event.Rune()
+		if r < '0' || r > '9' {
+			return event
+		}
+		page := int(r - '1')
+		if r == '0' {
+			page = 9
+		}
+		if page >= len(pageNames) {
+			return nil
+		}
+		tui.goToPage(page)
+		tui.app.Draw()
+		return nil
+	}
 	switch event.Key() {
+	// Pick the page to show from a list of all pages.
+	case tcell.KeyCtrlG:
+		tui.showChoice("Go to page", pageNames, tui.goToPage)
+		tui.app.Draw()
+		return nil
 	// Switch to next page.
 	case tcell.KeyCtrlN:
-		tui.currentPage = (tui.currentPage + 1) % 3
+		tui.currentPage = (tui.currentPage + 1) % len(pageNames)
 		switchPage(tui, tui.currentPage)
+	// Toggle between the library and the playlists.
+	case tcell.KeyCtrlSpace:
+		if tui.currentPage == 0 {
+			tui.currentPage = 1
+		} else {
+			tui.currentPage = 0
+		}
+		switchPage(tui, tui.currentPage)
+		// The event is not passed on, so the app has to be redrawn.
+		tui.app.Draw()
+		return nil
 	case tcell.KeyEsc:
 		// If shift Escape, it's a force quit so just exit
 		// don't try to clean up.
@@ -77,15 +114,31 @@ func (tui *TUI) globalControl(event *tcell.EventKey) *tcell.EventKey {
 			tui.app.Stop()
 		})
 		return nil
+	case tcell.KeyCtrlR:
+		tui.toggleRadio()
+		tui.app.Draw()
+		return nil
 	case tcell.KeyCtrlU:
 		nonUIBlockingCall(func() {
 			updateLibrary(tui)
 		})
 		return nil
+	case tcell.KeyCtrlX:
+		tui.cancelSync()
+		return nil
+	case tcell.KeyCtrlL:
+		nonUIBlockingCall(tui.resumeSavedQueue)
+		return nil
 	}
 	return event
 }
 
+// goToPage switches to the page with the given index in pageNames.
+func (tui *TUI) goToPage(page int) {
+	tui.currentPage = page
+	switchPage(tui, page)
+}
+
 // vimBindings provides similar navigations to Vim.
 func (tui *TUI) vimBindings(event *tcell.EventKey) *tcell.EventKey {
 	if event == nil {
@@ -83,7 +83,8 @@ func createArtistList(t *TUI) *tview.List {
 			return nil
 		}
 		// Also handle music control and VIM bindings.
-		return t.vimBindings(t.musicControl(event))
+		item := t.selectedArtistItem()
+		return t.vimBindings(t.musicControl(t.searchControl(t.shareControl(t.favoriteControl(event, item), item))))
 	})
 
 	return artistList
@@ -108,6 +109,7 @@ func (t *TUI) populateTracks(artistStr string) {
 		if album.Year != uint32(0) {
 			albumLine += "{" + strconv.Itoa(int(album.Year)) + "}"
 		}
+		albumLine += starMark(album.Starred) + ratingMark(album.Rating)
 		// Fill the rest of the line with "~"
 		width := runewidth.StringWidth(albumLine)
 		if (lineWidth - width) > 0 {
@@ -120,15 +122,23 @@ func (t *TUI) populateTracks(artistStr string) {
 
 		// Add entries for all the tracks in the album too.
 		for i, tr := range album.Tracks {
-			// Ensure the track has a matching artist name.
-			tr.Artist = artist.Name
-			// Ensure the track has a matching album name.
-			tr.Album = album.Name
+			// Fall back to the browsed artist and album when the server
+			// didn't tell us. Compilations keep their per-track artist.
+			if tr.Artist == "" {
+				tr.Artist = artist.Name
+			}
+			if tr.Album == "" {
+				tr.Album = album.Name
+			}
 			// Ensure the track has a track number.
 			if tr.TrackNumber == uint32(0) {
 				tr.TrackNumber = uint32(i + 1)
 			}
 			entry := fmt.Sprintf("%d. %s", tr.TrackNumber, tr.Title)
+			if tr.Artist != artist.Name {
+				entry += " - " + tr.Artist
+			}
+			entry += starMark(tr.Starred) + ratingMark(tr.Rating)
 
 			// Add the track duration to the end of the line if we have it.
 			d, err := strconv.Atoi(tr.DurationMillis)
@@ -136,6 +146,10 @@ func (t *TUI) populateTracks(artistStr string) {
 			if err == nil {
 				// Generate a duration string "mm:ss".
 				durration := durationString(time.Millisecond * time.Duration(d))
+				// Prefix the duration with the format, e.g. "flac 1000k".
+				if format := trackFormat(tr); format != "" {
+					durration = format + " " + durration
+				}
 				// Calculate how much padding is needed between the track name
 				// and the track durration.
 				width := runewidth.StringWidth(entry + durration)
@@ -151,6 +165,19 @@ func (t *TUI) populateTracks(artistStr string) {
 	}
 }
 
+// trackFormat returns the file format and bit rate of the track, or
+// an empty string if the server didn't report them.
+func trackFormat(tr *jamsonic.Track) string {
+	format := tr.Suffix
+	if tr.BitRate > 0 {
+		if format != "" {
+			format += " "
+		}
+		format += strconv.Itoa(tr.BitRate) + "k"
+	}
+	return format
+}
+
 func durationString(d time.Duration) string {
 	min := int(d.Minutes())
 	secs := int(d.Seconds()) % 60
@@ -187,8 +214,16 @@ func createTrackList(tui *TUI) *tview.List {
 			tui.app.SetFocus(tui.artistView)
 			return nil
 		}
+		// Add the selected track or album to a playlist.
+		if event.Rune() == 'A' {
+			if item := tui.selectedTrackItem(); item != nil {
+				tui.addToPlaylist(tui.searchResultTracks(item))
+			}
+			return nil
+		}
 		// Handle music control input and VIM bindings.
-		return tui.vimBindings(tui.musicControl(event))
+		item := tui.selectedTrackItem()
+		return tui.vimBindings(tui.musicControl(tui.searchControl(tui.shareControl(tui.favoriteControl(event, item), item))))
 	})
 	return tracks
 }
@@ -236,6 +271,31 @@ func (tui *TUI) playTracks(index int, entry string) {
 	nonUIBlockingCall(tui.player.Play)
 }
 
+// selectedArtistItem returns the artist selected in the artistView.
+func (tui *TUI) selectedArtistItem() *searchItem {
+	current := tui.artistView.GetCurrentItem()
+	if current >= len(tui.artists) {
+		return nil
+	}
+	return &searchItem{artist: tui.artistMap[tui.artists[current]]}
+}
+
+// selectedTrackItem returns the album or the track selected in the
+// tracksView.
+func (tui *TUI) selectedTrackItem() *searchItem {
+	if tui.tracksView.GetItemCount() == 0 {
+		return nil
+	}
+	entry, _ := tui.tracksView.GetItemText(tui.tracksView.GetCurrentItem())
+	if tr, ok := tui.trackListed[entry]; ok {
+		return &searchItem{track: tr}
+	}
+	if album, ok := tui.albumListed[entry]; ok {
+		return &searchItem{album: album}
+	}
+	return nil
+}
+
 // playArtist plays all songs for an artist.
 func (tui *TUI) playArtist(entry string) {
 	var tracks []*jamsonic.Track
@@ -245,13 +305,3 @@ func (tui *TUI) playArtist(entry string) {
 	tui.player.CreatePlayQueue(tracks)
 	nonUIBlockingCall(tui.player.Play)
 }
-
-// Updates the library and refreshes the UI.
-func updateLibrary(tui *TUI) {
-	err := jamsonic.RefreshLibrary(tui.db, tui.provider)
-	if err != nil {
-		tui.player.Error <- err
-		return
-	}
-	tui.populateArtists()
-}
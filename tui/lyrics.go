@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+func (tui *TUI) createLyricsPage() *tview.TextView {
+	view := tview.NewTextView().SetRegions(true).SetDynamicColors(true).
+		SetWrap(true).SetTextAlign(tview.AlignCenter)
+	view.SetBorder(true).SetTitle("Lyrics")
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 'j', tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 'k', tcell.ModNone)
+		}
+		return tui.musicControl(event)
+	})
+	tui.lyricsView = view
+	tui.lyricsLine = -1
+	return view
+}
+
+// updateLyrics is called by the player's callback. When the track changes,
+// the lyrics are loaded in the background. For synced lyrics, the line at
+// the position is highlighted.
+func (tui *TUI) updateLyrics(track *jamsonic.Track, position time.Duration) {
+	provider, ok := tui.provider.(jamsonic.LyricsProvider)
+	if !ok {
+		return
+	}
+	var id string
+	if track != nil {
+		id = track.ID
+	}
+	tui.lyricsMu.Lock()
+	defer tui.lyricsMu.Unlock()
+	if id != tui.lyricsTrackID {
+		tui.lyricsTrackID = id
+		tui.lyrics = nil
+		tui.lyricsLine = -1
+		tui.lyricsView.Clear()
+		if track != nil && !track.Live {
+			fmt.Fprint(tui.lyricsView, "Loading lyrics...")
+			go tui.loadLyrics(provider, track)
+		}
+		return
+	}
+	if tui.lyrics == nil {
+		return
+	}
+	line := tui.lyrics.LineAt(position)
+	if line == tui.lyricsLine {
+		return
+	}
+	tui.lyricsLine = line
+	if line < 0 {
+		tui.lyricsView.Highlight().ScrollToBeginning()
+		return
+	}
+	tui.lyricsView.Highlight(strconv.Itoa(line)).ScrollToHighlight()
+}
+
+// loadLyrics gets the lyrics from the cache or the provider and shows them.
+func (tui *TUI) loadLyrics(provider jamsonic.LyricsProvider, track *jamsonic.Track) {
+	lyrics, err := jamsonic.GetLyrics(tui.ctx, tui.db, provider, track)
+	if err != nil && err != jamsonic.ErrNoLyrics {
+		tui.logger.ErrorLog("Failed to get the lyrics: " + err.Error())
+	}
+	tui.lyricsMu.Lock()
+	defer tui.app.Draw()
+	defer tui.lyricsMu.Unlock()
+	if track.ID != tui.lyricsTrackID {
+		// The track changed while loading.
+		return
+	}
+	tui.lyricsView.Clear()
+	title := "Lyrics"
+	if !jamsonic.HasCapability(provider, jamsonic.SongLyricsCapability) {
+		title += " (the server has no synced lyrics)"
+	}
+	tui.lyricsView.SetTitle(title)
+	if err != nil {
+		fmt.Fprint(tui.lyricsView, "No lyrics found.")
+		return
+	}
+	tui.lyrics = lyrics
+	for i, line := range lyrics.Lines {
+		text := tview.Escape(line.Text)
+		if lyrics.Synced {
+			// Each line is a region so it can be highlighted.
+			text = fmt.Sprintf(`["%d"]%s[""]`, i, text)
+		}
+		fmt.Fprintln(tui.lyricsView, text)
+	}
+	tui.lyricsView.ScrollToBeginning()
+}
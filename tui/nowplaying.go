@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// nowPlayingInterval is how often the tracks played by the other users are
+// fetched.
+const nowPlayingInterval = 30 * time.Second
+
+func (tui *TUI) createNowPlayingPage() *tview.List {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Now playing")
+	tui.nowPlayingView = list
+
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.nowPlaying) {
+			tui.playTracksNow([]*jamsonic.Track{tui.nowPlaying[index].Track})
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := list.GetCurrentItem()
+		if index < len(tui.nowPlaying) && event.Rune() == 'a' {
+			tui.player.Enqueue(tui.nowPlaying[index].Track)
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	return list
+}
+
+// followNowPlaying lists what the other users are playing every
+// nowPlayingInterval until the TUI stops.
+func (tui *TUI) followNowPlaying() {
+	if _, ok := tui.provider.(jamsonic.NowPlayingProvider); !ok {
+		return
+	}
+	ticker := time.NewTicker(nowPlayingInterval)
+	defer ticker.Stop()
+	for {
+		tui.populateNowPlaying()
+		select {
+		case <-ticker.C:
+		case <-tui.ctx.Done():
+			return
+		}
+	}
+}
+
+// populateNowPlaying gets the tracks the other users are playing from the
+// provider and lists them.
+func (tui *TUI) populateNowPlaying() {
+	provider, ok := tui.provider.(jamsonic.NowPlayingProvider)
+	if !ok {
+		return
+	}
+	entries, err := provider.NowPlaying(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get what the other users are playing: " + err.Error())
+		return
+	}
+	tui.nowPlaying = entries
+	current := tui.nowPlayingView.GetCurrentItem()
+	tui.nowPlayingView.Clear()
+	for _, e := range entries {
+		line := e.Username + ": " + e.Track.Title
+		if e.Track.Artist != "" {
+			line += " - " + e.Track.Artist
+		}
+		tui.nowPlayingView.AddItem(line, nowPlayingDetails(e), 0, nil)
+	}
+	if current < len(entries) {
+		tui.nowPlayingView.SetCurrentItem(current)
+	}
+	tui.app.Draw()
+}
+
+// nowPlayingDetails describes the player and when the track was started.
+func nowPlayingDetails(e *jamsonic.NowPlaying) string {
+	started := "just started"
+	if min := int(e.Started.Minutes()); min > 0 {
+		started = strconv.Itoa(min) + " min ago"
+	}
+	if e.PlayerName == "" {
+		return started
+	}
+	return e.PlayerName + ", " + started
+}
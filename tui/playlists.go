@@ -0,0 +1,334 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/tview"
+)
+
+// newPlaylistOption is the last option when choosing a playlist to add
+// tracks to.
+const newPlaylistOption = "<New playlist>"
+
+func (tui *TUI) createPlaylistsPage() *tview.Flex {
+	tui.playlistView = createPlaylistList(tui)
+	tui.playlistTracksView = createPlaylistTrackList(tui)
+
+	// Same layout as the library page.
+	tui.playlistsPage = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(tui.playlistView, 0, 1, true).
+		AddItem(tui.playlistTracksView, 0, 2, false)
+
+	tui.populatePlaylists()
+	return tui.playlistsPage
+}
+
+// Creates a TUI list with all the playlists.
+func createPlaylistList(tui *TUI) *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle("Playlists")
+
+	list.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		tui.populatePlaylistTracks(index)
+	})
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		p := tui.selectedPlaylist()
+		switch event.Key() {
+		case tcell.KeyTab:
+			tui.app.SetFocus(tui.playlistTracksView)
+			return nil
+		case tcell.KeyEnter:
+			if p != nil {
+				tui.player.CreatePlayQueue(p.Tracks)
+				nonUIBlockingCall(tui.player.Play)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'a':
+			if p != nil {
+				tui.player.Enqueue(p.Tracks...)
+			}
+			return nil
+		case 'N':
+			tui.showInput("New playlist", "", func(name string) {
+				tui.createPlaylist(name, nil)
+			})
+			return nil
+		case 'e':
+			if p != nil {
+				tui.showInput("Rename playlist", p.Name, func(name string) {
+					tui.editPlaylist(p.ID, func(m jamsonic.PlaylistManager) error {
+						return m.RenamePlaylist(tui.ctx, p.ID, name)
+					})
+				})
+			}
+			return nil
+		case 'S':
+			if p != nil {
+				tui.shareTracks(p.Name, p.Tracks)
+			}
+			return nil
+		case 'D':
+			if p != nil {
+				tui.showConfirm("Delete the playlist "+p.Name+"?", func() {
+					tui.deletePlaylist(p.ID)
+				})
+			}
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+	return list
+}
+
+// Creates a TUI list with the tracks in the selected playlist.
+func createPlaylistTrackList(tui *TUI) *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle("Tracks")
+
+	// Play the playlist from the selected track.
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		p := tui.selectedPlaylist()
+		if p == nil || index >= len(p.Tracks) {
+			return
+		}
+		tui.player.CreatePlayQueue(p.Tracks[index:])
+		nonUIBlockingCall(tui.player.Play)
+	})
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			tui.app.SetFocus(tui.playlistView)
+			return nil
+		}
+		p := tui.selectedPlaylist()
+		index := list.GetCurrentItem()
+		if p == nil || index >= len(p.Tracks) {
+			return tui.vimBindings(tui.musicControl(event))
+		}
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(p.Tracks[index])
+			return nil
+		case 'd':
+			tui.editPlaylist(p.ID, func(m jamsonic.PlaylistManager) error {
+				return m.RemoveFromPlaylist(tui.ctx, p.ID, []int{index})
+			})
+			return nil
+		case 'S':
+			tr := p.Tracks[index]
+			tui.shareTracks(itemName(&searchItem{track: tr}), []*jamsonic.Track{tr})
+			return nil
+		case 'J':
+			if index+1 < len(p.Tracks) {
+				tui.moveTrack(p, index, index+1)
+			}
+			return nil
+		case 'K':
+			if index > 0 {
+				tui.moveTrack(p, index, index-1)
+			}
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+	return list
+}
+
+// populatePlaylists reads the cached playlists from the database and
+// updates the lists. The current selections are kept if possible.
+func (tui *TUI) populatePlaylists() {
+	playlists, err := tui.db.Playlists()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the playlists: " + err.Error())
+		return
+	}
+	tui.playlists = playlists
+	current := tui.playlistView.GetCurrentItem()
+	tui.playlistView.Clear()
+	for _, p := range playlists {
+		tui.playlistView.AddItem(p.Name, "", 0, nil)
+	}
+	if current >= len(playlists) {
+		current = len(playlists) - 1
+	}
+	if current < 0 {
+		current = 0
+	}
+	tui.playlistView.SetCurrentItem(current)
+	tui.populatePlaylistTracks(current)
+}
+
+// populatePlaylistTracks lists the tracks of the playlist at the index.
+func (tui *TUI) populatePlaylistTracks(index int) {
+	current := tui.playlistTracksView.GetCurrentItem()
+	tui.playlistTracksView.Clear()
+	if index < 0 || index >= len(tui.playlists) {
+		return
+	}
+	_, _, lineWidth, _ := tui.playlistTracksView.GetInnerRect()
+	for i, tr := range tui.playlists[index].Tracks {
+		entry := fmt.Sprintf("%d. %s", i+1, tr.Title)
+		if tr.Artist != "" {
+			entry += " - " + tr.Artist
+		}
+		if d, err := strconv.Atoi(tr.DurationMillis); err == nil {
+			durration := durationString(time.Millisecond * time.Duration(d))
+			width := runewidth.StringWidth(entry + durration)
+			entry += getFillString(lineWidth, width, " ") + durration
+		}
+		tui.playlistTracksView.AddItem(entry, "", 0, nil)
+	}
+	if current < tui.playlistTracksView.GetItemCount() {
+		tui.playlistTracksView.SetCurrentItem(current)
+	}
+}
+
+// selectedPlaylist returns the highlighted playlist or nil if there are
+// no playlists.
+func (tui *TUI) selectedPlaylist() *jamsonic.Playlist {
+	index := tui.playlistView.GetCurrentItem()
+	if index >= len(tui.playlists) {
+		return nil
+	}
+	return tui.playlists[index]
+}
+
+// playlistManager returns the provider as a PlaylistManager. If the provider
+// can't edit playlists, an error is logged.
+func (tui *TUI) playlistManager() (jamsonic.PlaylistManager, bool) {
+	m, ok := tui.provider.(jamsonic.PlaylistManager)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support editing playlists.")
+	}
+	return m, ok
+}
+
+// editPlaylist runs the edit in the background. Afterwards, the playlist is
+// fetched from the server to update the cache and the UI.
+func (tui *TUI) editPlaylist(id string, edit func(m jamsonic.PlaylistManager) error) {
+	m, ok := tui.playlistManager()
+	if !ok {
+		return
+	}
+	nonUIBlockingCall(func() {
+		if err := edit(m); err != nil {
+			tui.logger.ErrorLog("Failed to edit the playlist: " + err.Error())
+		}
+		p, err := m.Playlist(tui.ctx, id)
+		if err != nil {
+			tui.logger.ErrorLog("Failed to get the playlist: " + err.Error())
+			return
+		}
+		tui.savePlaylist(p)
+	})
+}
+
+// createPlaylist creates a new playlist with the tracks in the background.
+func (tui *TUI) createPlaylist(name string, tracks []*jamsonic.Track) {
+	m, ok := tui.playlistManager()
+	if !ok {
+		return
+	}
+	nonUIBlockingCall(func() {
+		p, err := m.CreatePlaylist(tui.ctx, name, trackIDs(tracks))
+		if err != nil {
+			tui.logger.ErrorLog("Failed to create the playlist: " + err.Error())
+			return
+		}
+		tui.savePlaylist(p)
+	})
+}
+
+// deletePlaylist deletes the playlist in the background.
+func (tui *TUI) deletePlaylist(id string) {
+	m, ok := tui.playlistManager()
+	if !ok {
+		return
+	}
+	nonUIBlockingCall(func() {
+		if err := m.DeletePlaylist(tui.ctx, id); err != nil {
+			tui.logger.ErrorLog("Failed to delete the playlist: " + err.Error())
+			return
+		}
+		if err := tui.db.DeletePlaylist(id); err != nil {
+			tui.logger.ErrorLog("Failed to delete the cached playlist: " + err.Error())
+		}
+		tui.populatePlaylists()
+		tui.app.Draw()
+	})
+}
+
+// moveTrack moves the track in the playlist and follows it with the cursor.
+func (tui *TUI) moveTrack(p *jamsonic.Playlist, from, to int) {
+	tracks := make([]*jamsonic.Track, len(p.Tracks))
+	copy(tracks, p.Tracks)
+	tracks[from], tracks[to] = tracks[to], tracks[from]
+	tui.playlistTracksView.SetCurrentItem(to)
+	tui.editPlaylist(p.ID, func(m jamsonic.PlaylistManager) error {
+		return m.ReorderPlaylist(tui.ctx, p.ID, trackIDs(tracks))
+	})
+}
+
+// savePlaylist caches the playlist and redraws the playlists page.
+func (tui *TUI) savePlaylist(p *jamsonic.Playlist) {
+	if err := tui.db.SavePlaylist(p); err != nil {
+		tui.logger.ErrorLog("Failed to save the playlist: " + err.Error())
+	}
+	tui.populatePlaylists()
+	tui.app.Draw()
+}
+
+// addToPlaylist lets the user choose a playlist, or create a new one, for
+// the tracks.
+func (tui *TUI) addToPlaylist(tracks []*jamsonic.Track) {
+	options := make([]string, 0, len(tui.playlists)+1)
+	for _, p := range tui.playlists {
+		options = append(options, p.Name)
+	}
+	options = append(options, newPlaylistOption)
+	tui.showChoice("Add to playlist", options, func(index int) {
+		if index == len(tui.playlists) {
+			tui.showInput("New playlist", "", func(name string) {
+				tui.createPlaylist(name, tracks)
+			})
+			return
+		}
+		id := tui.playlists[index].ID
+		tui.editPlaylist(id, func(m jamsonic.PlaylistManager) error {
+			return m.AppendToPlaylist(tui.ctx, id, trackIDs(tracks))
+		})
+	})
+}
+
+func trackIDs(tracks []*jamsonic.Track) []string {
+	ids := make([]string, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ID
+	}
+	return ids
+}
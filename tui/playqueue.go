@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"github.com/TcM1911/jamsonic"
+)
+
+// announceSavedQueue tells the user if a play queue saved on the server, for
+// example by a phone, can be resumed.
+func (tui *TUI) announceSavedQueue() {
+	provider, ok := tui.provider.(jamsonic.PlayQueueProvider)
+	if !ok {
+		return
+	}
+	saved, err := provider.PlayQueue(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the saved play queue: " + err.Error())
+		return
+	}
+	if saved != nil {
+		tui.logger.InfoLog("The play queue saved by " + queueSaver(saved) + " at " +
+			saved.Changed.Local().Format("2006-01-02 15:04") + " can be resumed with Ctrl+L.")
+	}
+}
+
+// resumeSavedQueue replaces the play queue with the queue saved on the
+// server and plays it from where it was left.
+func (tui *TUI) resumeSavedQueue() {
+	if tui.queueSync == nil {
+		return
+	}
+	saved, err := tui.queueSync.Resume(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to resume the play queue: " + err.Error())
+		return
+	}
+	if saved == nil {
+		tui.logger.InfoLog("No play queue is saved on the server.")
+		return
+	}
+	tui.logger.InfoLog("Resumed the play queue saved by " + queueSaver(saved) + ".")
+}
+
+// queueSaver returns the name of the client that saved the queue.
+func queueSaver(q *jamsonic.SavedPlayQueue) string {
+	if q.ChangedBy == "" {
+		return "another client"
+	}
+	return q.ChangedBy
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const (
+	// newestEpisodesOption is the first entry in the channel list. It lists
+	// the newest episodes of all channels.
+	newestEpisodesOption = "<Newest episodes>"
+	// newestEpisodesCount is the number of newest episodes fetched.
+	newestEpisodesCount = 20
+)
+
+func (tui *TUI) createPodcastsPage() *tview.Flex {
+	channels := tview.NewList().ShowSecondaryText(false)
+	channels.SetBorder(true).SetTitle("Podcasts")
+	episodes := tview.NewList().ShowSecondaryText(false)
+	episodes.SetBorder(true).SetTitle("Episodes")
+	tui.podcastChannelsView = channels
+	tui.podcastEpisodesView = episodes
+
+	channels.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		tui.populateEpisodes(index)
+	})
+	channels.SetSelectedFunc(func(int, string, string, rune) {
+		tui.app.SetFocus(episodes)
+	})
+	channels.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			tui.app.SetFocus(episodes)
+			return nil
+		}
+		switch event.Rune() {
+		case 'N':
+			tui.showInput("Podcast feed URL", "", func(url string) {
+				tui.editPodcasts(func(p jamsonic.PodcastProvider) error {
+					return p.CreatePodcastChannel(tui.ctx, url)
+				})
+			})
+			return nil
+		case 'D':
+			if ch := tui.selectedChannel(); ch != nil {
+				tui.showConfirm("Unsubscribe from "+ch.Title+"?", func() {
+					tui.editPodcasts(func(p jamsonic.PodcastProvider) error {
+						return p.DeletePodcastChannel(tui.ctx, ch.ID)
+					})
+				})
+			}
+			return nil
+		case 'R':
+			tui.logger.InfoLog("Checking the podcasts for new episodes.")
+			tui.editPodcasts(func(p jamsonic.PodcastProvider) error {
+				return p.RefreshPodcasts(tui.ctx)
+			})
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	episodes.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if e := tui.selectedEpisode(); e != nil {
+			tui.playTracksNow([]*jamsonic.Track{e.Track()})
+		}
+	})
+	episodes.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			tui.app.SetFocus(channels)
+			return nil
+		}
+		e := tui.selectedEpisode()
+		if e == nil {
+			return tui.vimBindings(tui.musicControl(event))
+		}
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(e.Track())
+			return nil
+		case 'd':
+			tui.editPodcasts(func(p jamsonic.PodcastProvider) error {
+				return p.DownloadPodcastEpisode(tui.ctx, e.ID)
+			})
+			return nil
+		case 'm':
+			tui.togglePlayed(e)
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	tui.podcastsPage = tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(channels, 0, 1, true).
+		AddItem(episodes, 0, 2, false)
+	return tui.podcastsPage
+}
+
+// populatePodcasts gets the channels and the newest episodes from the
+// provider and lists them.
+func (tui *TUI) populatePodcasts() {
+	provider, ok := tui.provider.(jamsonic.PodcastProvider)
+	if !ok {
+		return
+	}
+	channels, err := provider.Podcasts(tui.ctx, true)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the podcasts: " + err.Error())
+		return
+	}
+	newest, err := provider.NewestPodcasts(tui.ctx, newestEpisodesCount)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the newest episodes: " + err.Error())
+	}
+	tui.podcastChannels = channels
+	tui.newestEpisodes = newest
+	current := tui.podcastChannelsView.GetCurrentItem()
+	tui.podcastChannelsView.Clear()
+	tui.podcastChannelsView.AddItem(newestEpisodesOption, "", 0, nil)
+	for _, ch := range channels {
+		tui.podcastChannelsView.AddItem(ch.Title, "", 0, nil)
+	}
+	if current > len(channels) {
+		current = len(channels)
+	}
+	tui.podcastChannelsView.SetCurrentItem(current)
+	tui.populateEpisodes(current)
+	tui.app.Draw()
+}
+
+// populateEpisodes lists the episodes of the channel at the index in the
+// channel list, with markers for the played and partly played episodes.
+func (tui *TUI) populateEpisodes(index int) {
+	current := tui.podcastEpisodesView.GetCurrentItem()
+	tui.podcastEpisodesView.Clear()
+	tui.podcastEpisodes = nil
+	if index == 0 {
+		tui.podcastEpisodes = tui.newestEpisodes
+	} else if index <= len(tui.podcastChannels) {
+		tui.podcastEpisodes = tui.podcastChannels[index-1].Episodes
+	}
+	progress, err := tui.db.PodcastProgress()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the podcast progress: " + err.Error())
+	}
+	for _, e := range tui.podcastEpisodes {
+		line := e.Title
+		if !e.Published.IsZero() {
+			line += " {" + e.Published.Format("2006-01-02") + "}"
+		}
+		p := progress[e.ID]
+		switch {
+		case !e.Downloaded():
+			line += " [" + e.Status + "[]"
+		case p != nil && p.Played:
+			line += " (played)"
+		case p != nil && p.Position > 0:
+			line += " (at " + durationString(p.Position) + ")"
+		}
+		tui.podcastEpisodesView.AddItem(line, "", 0, nil)
+	}
+	if current < len(tui.podcastEpisodes) {
+		tui.podcastEpisodesView.SetCurrentItem(current)
+	}
+}
+
+// selectedChannel returns the highlighted channel or nil if the newest
+// episodes are highlighted.
+func (tui *TUI) selectedChannel() *jamsonic.PodcastChannel {
+	index := tui.podcastChannelsView.GetCurrentItem()
+	if index == 0 || index > len(tui.podcastChannels) {
+		return nil
+	}
+	return tui.podcastChannels[index-1]
+}
+
+// selectedEpisode returns the highlighted episode or nil if there are no
+// episodes.
+func (tui *TUI) selectedEpisode() *jamsonic.PodcastEpisode {
+	index := tui.podcastEpisodesView.GetCurrentItem()
+	if index >= len(tui.podcastEpisodes) {
+		return nil
+	}
+	return tui.podcastEpisodes[index]
+}
+
+// editPodcasts runs the change in the background and fetches the podcasts
+// again.
+func (tui *TUI) editPodcasts(edit func(p jamsonic.PodcastProvider) error) {
+	provider, ok := tui.provider.(jamsonic.PodcastProvider)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support podcasts.")
+		return
+	}
+	nonUIBlockingCall(func() {
+		if err := edit(provider); err != nil {
+			tui.logger.ErrorLog("Failed to update the podcasts: " + err.Error())
+			return
+		}
+		tui.populatePodcasts()
+	})
+}
+
+// togglePlayed marks the episode as played, or as not played if it has
+// been played.
+func (tui *TUI) togglePlayed(e *jamsonic.PodcastEpisode) {
+	progress, err := tui.db.PodcastProgress()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the podcast progress: " + err.Error())
+		return
+	}
+	played := progress[e.ID] != nil && progress[e.ID].Played
+	if err := tui.db.SavePodcastProgress(e.ID, &jamsonic.EpisodeProgress{Played: !played}); err != nil {
+		tui.logger.ErrorLog("Failed to save the podcast progress: " + err.Error())
+		return
+	}
+	tui.populateEpisodes(tui.podcastChannelsView.GetCurrentItem())
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+// toggleRadio stops the radio if it's on. Otherwise the user is asked for
+// a filter and the radio is started.
+func (tui *TUI) toggleRadio() {
+	if tui.radio == nil {
+		tui.logger.ErrorLog("The provider doesn't support the radio.")
+		return
+	}
+	if tui.radio.On() {
+		tui.radio.Stop()
+		tui.logger.InfoLog("Radio stopped.")
+		tui.drawFooter()
+		return
+	}
+	tui.showInput("Radio filter: genre, years (Rock, 1990-1999) or all", "all", func(text string) {
+		filter, err := parseRadioFilter(text)
+		if err != nil {
+			tui.logger.ErrorLog("Invalid radio filter: " + text)
+			return
+		}
+		tui.radio.Start(filter)
+		tui.logger.InfoLog("Radio started.")
+		tui.drawFooter()
+	})
+}
+
+// parseRadioFilter parses a comma separated genre and year range. Both are
+// optional and "all" means no filter.
+func parseRadioFilter(s string) (jamsonic.RadioFilter, error) {
+	var filter jamsonic.RadioFilter
+	if strings.EqualFold(strings.TrimSpace(s), "all") {
+		return filter, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(part[:1]); err != nil {
+			filter.Genre = part
+			continue
+		}
+		from, to, err := parseYears(part)
+		if err != nil {
+			return filter, err
+		}
+		filter.FromYear, filter.ToYear = from, to
+	}
+	return filter, nil
+}
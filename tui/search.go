@@ -0,0 +1,306 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/TcM1911/jamsonic/storage"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const (
+	// searchPageSize is the number of results of each kind requested from
+	// the server per page.
+	searchPageSize = 20
+	// moreResultsOption is the last entry in the results if the server may
+	// have more results.
+	moreResultsOption = "<More results>"
+)
+
+// searchItem is one entry in the search results. Only one of the fields
+// is set.
+type searchItem struct {
+	artist *jamsonic.Artist
+	album  *jamsonic.Album
+	track  *jamsonic.Track
+}
+
+func (i *searchItem) String() string {
+	switch {
+	case i.artist != nil:
+		return "[Artist] " + i.artist.Name
+	case i.album != nil:
+		return fmt.Sprintf("[Album]  %s - %s", i.album.Name, i.album.Artist)
+	default:
+		return fmt.Sprintf("[Track]  %s - %s", i.track.Title, i.track.Artist)
+	}
+}
+
+func searchItems(res *jamsonic.SearchResult) []*searchItem {
+	items := make([]*searchItem, 0, res.Len())
+	for _, a := range res.Artists {
+		items = append(items, &searchItem{artist: a})
+	}
+	for _, a := range res.Albums {
+		items = append(items, &searchItem{album: a})
+	}
+	for _, t := range res.Tracks {
+		items = append(items, &searchItem{track: t})
+	}
+	return items
+}
+
+// searchControl handles the search keys for the library page.
+func (tui *TUI) searchControl(event *tcell.EventKey) *tcell.EventKey {
+	if event == nil {
+		return nil
+	}
+	switch event.Rune() {
+	case '/':
+		tui.showSearch()
+		return nil
+	case 'n':
+		tui.nextSearchResult()
+		return nil
+	}
+	return event
+}
+
+// showSearch opens the search overlay. The cached library is searched while
+// typing. When Enter is pressed, the server is searched if the provider
+// supports it.
+func (tui *TUI) showSearch() {
+	input := tview.NewInputField().SetLabel("Search: ").SetText(tui.searchQuery)
+	results := tview.NewList().ShowSecondaryText(false)
+	results.SetBorder(true).SetTitle("Results")
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(input, 1, 1, true).
+		AddItem(results, 0, 1, false)
+	layout.SetBorder(true).SetTitle("Search")
+
+	var more bool
+	show := func(items []*searchItem, hasMore bool) {
+		tui.searchResults = items
+		tui.searchIndex = -1
+		more = hasMore
+		results.Clear()
+		for _, item := range items {
+			results.AddItem(item.String(), "", 0, nil)
+		}
+		if more {
+			results.AddItem(moreResultsOption, "", 0, nil)
+		}
+	}
+	show(tui.searchResults, false)
+
+	input.SetChangedFunc(func(text string) {
+		tui.searchQuery = text
+		show(searchItems(storage.Search(tui.libraryArtists(), text)), false)
+	})
+	input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			tui.closeDialog()
+		case tcell.KeyEnter:
+			searcher, ok := tui.provider.(jamsonic.Searcher)
+			if !ok || tui.searchQuery == "" {
+				tui.app.SetFocus(results)
+				return
+			}
+			query := tui.searchQuery
+			nonUIBlockingCall(func() {
+				res, err := searcher.Search(tui.ctx, query, searchPageSize, 0)
+				if err != nil {
+					tui.logger.ErrorLog("Search failed: " + err.Error())
+					return
+				}
+				show(searchItems(res), isFullPage(res))
+				tui.app.SetFocus(results)
+				tui.app.Draw()
+			})
+		case tcell.KeyTab:
+			tui.app.SetFocus(results)
+		}
+	})
+
+	results.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.searchResults) {
+			tui.closeDialog()
+			tui.playTracksNow(tui.searchResultTracks(tui.searchResults[index]))
+			return
+		}
+		// Fetch the next page from the server.
+		searcher, ok := tui.provider.(jamsonic.Searcher)
+		if !ok || !more {
+			return
+		}
+		query, offset, current := tui.searchQuery, tui.searchOffset+searchPageSize, tui.searchResults
+		nonUIBlockingCall(func() {
+			res, err := searcher.Search(tui.ctx, query, searchPageSize, offset)
+			if err != nil {
+				tui.logger.ErrorLog("Search failed: " + err.Error())
+				return
+			}
+			tui.searchOffset = offset
+			show(append(current, searchItems(res)...), isFullPage(res))
+			results.SetCurrentItem(len(current))
+			tui.app.Draw()
+		})
+	})
+	results.SetDoneFunc(func() {
+		tui.closeDialog()
+	})
+	results.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := results.GetCurrentItem()
+		if event.Key() == tcell.KeyTab || event.Rune() == '/' {
+			tui.app.SetFocus(input)
+			return nil
+		}
+		if index >= len(tui.searchResults) {
+			return tui.vimBindings(event)
+		}
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(tui.searchResultTracks(tui.searchResults[index])...)
+			return nil
+		case 'l':
+			tui.closeDialog()
+			tui.searchIndex = index
+			tui.jumpToResult(tui.searchResults[index])
+			return nil
+		}
+		return tui.vimBindings(event)
+	})
+
+	tui.searchOffset = 0
+	tui.openDialog(centered(layout, 80, 22), input)
+}
+
+// nextSearchResult shows the next search result in the library.
+func (tui *TUI) nextSearchResult() {
+	if len(tui.searchResults) == 0 {
+		return
+	}
+	tui.searchIndex = (tui.searchIndex + 1) % len(tui.searchResults)
+	tui.jumpToResult(tui.searchResults[tui.searchIndex])
+}
+
+// isFullPage returns true if the server may have more results.
+func isFullPage(res *jamsonic.SearchResult) bool {
+	return len(res.Artists) == searchPageSize ||
+		len(res.Albums) == searchPageSize ||
+		len(res.Tracks) == searchPageSize
+}
+
+// libraryArtists returns the artists in the library view.
+func (tui *TUI) libraryArtists() []*jamsonic.Artist {
+	artists := make([]*jamsonic.Artist, 0, len(tui.artists))
+	for _, name := range tui.artists {
+		artists = append(artists, tui.artistMap[name])
+	}
+	return artists
+}
+
+// locate finds the result in the library. Results from the server are
+// matched by ID, or by name for artists. Nil is returned for the parts
+// not found.
+func (tui *TUI) locate(item *searchItem) (*jamsonic.Artist, *jamsonic.Album, *jamsonic.Track) {
+	for _, name := range tui.artists {
+		artist := tui.artistMap[name]
+		if item.artist != nil && (artist == item.artist || artist.ID == item.artist.ID || artist.Name == item.artist.Name) {
+			return artist, nil, nil
+		}
+		for _, album := range artist.Albums {
+			if item.album != nil && (album == item.album || album.ID == item.album.ID) {
+				return artist, album, nil
+			}
+			if item.track == nil {
+				continue
+			}
+			for _, tr := range album.Tracks {
+				if tr == item.track || tr.ID == item.track.ID {
+					return artist, album, tr
+				}
+			}
+		}
+	}
+	return nil, nil, nil
+}
+
+// searchResultTracks returns the tracks to play for the search result.
+func (tui *TUI) searchResultTracks(item *searchItem) []*jamsonic.Track {
+	if item.track != nil {
+		return []*jamsonic.Track{item.track}
+	}
+	artist, album, _ := tui.locate(item)
+	if album != nil {
+		return album.Tracks
+	}
+	if artist != nil {
+		var tracks []*jamsonic.Track
+		for _, album := range artist.Albums {
+			tracks = append(tracks, album.Tracks...)
+		}
+		return tracks
+	}
+	tui.logger.InfoLog("The search result is not in the cached library. Press Ctrl+U to update the library.")
+	return nil
+}
+
+// playTracksNow replaces the play queue with the tracks and starts playing.
+func (tui *TUI) playTracksNow(tracks []*jamsonic.Track) {
+	if len(tracks) == 0 {
+		return
+	}
+	tui.player.CreatePlayQueue(tracks)
+	nonUIBlockingCall(tui.player.Play)
+}
+
+// jumpToResult switches to the library and selects the search result.
+func (tui *TUI) jumpToResult(item *searchItem) {
+	artist, album, track := tui.locate(item)
+	if artist == nil {
+		tui.logger.InfoLog("The search result is not in the cached library. Press Ctrl+U to update the library.")
+		return
+	}
+	tui.currentPage = 0
+	switchPage(tui, 0)
+	for i, name := range tui.artists {
+		if name == artist.Name {
+			// Triggers populateTracks.
+			tui.artistView.SetCurrentItem(i)
+			break
+		}
+	}
+	if album == nil {
+		tui.app.SetFocus(tui.artistView)
+		return
+	}
+	for i := 0; i < tui.tracksView.GetItemCount(); i++ {
+		line, _ := tui.tracksView.GetItemText(i)
+		if (track != nil && tui.trackListed[line] == track) ||
+			(track == nil && line[0] == '~' && tui.albumListed[line] == album) {
+			tui.tracksView.SetCurrentItem(i)
+			break
+		}
+	}
+	tui.app.SetFocus(tui.tracksView)
+}
@@ -19,7 +19,9 @@ package tui
 
 import (
 	"encoding/json"
+	"strconv"
 
+	"github.com/TcM1911/jamsonic"
 	"github.com/TcM1911/jamsonic/subsonic"
 	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
@@ -36,6 +38,17 @@ const (
 	strHost           = "Host"
 	strUsername       = "Username"
 	strPassword       = "Password"
+	strAuthMethod     = "Authentication"
+	strAPIKey         = "API key"
+	strDetect         = "Detect"
+	strDelete         = "Delete"
+	strProfile        = "Profile"
+	strName           = "Name"
+	strFormat         = "Format"
+	strMaxBitRate     = "Max bit rate (kbps)"
+	strMusicFolder    = "Music folder"
+	strAllFolders     = "All folders"
+	strOutput         = "Output"
 	strBlank          = ""
 	passwordMask      = '*'
 	strDefaultHostStr = "https://"
@@ -47,6 +60,9 @@ var settingsPages *tview.Pages
 func (tui *TUI) createSettingsPage() *tview.Flex {
 	configPages := []*configPage{
 		&configPage{name: "*sonic", panel: sonicForm(tui)},
+		&configPage{name: "Streaming", panel: streamForm(tui)},
+		&configPage{name: "Music folder", panel: musicFolderForm(tui)},
+		&configPage{name: "Output", panel: outputForm(tui)},
 	}
 	settingsPages = tview.NewPages()
 	configList := createConfigList(configPages)
@@ -97,30 +113,107 @@ func newSettingsForm() *tview.Form {
 	return form
 }
 
-// sonicForm is the form for changing *sonic credentials.
+// outputs are the options for the output in outputForm.
+var outputs = []struct {
+	name   string
+	output string
+}{
+	{"Local", jamsonic.LocalOutput},
+	{"Server jukebox", jamsonic.JukeboxOutput},
+}
+
+// authMethods are the options for the authentication method in sonicForm.
+var authMethods = []struct {
+	name   string
+	method subsonic.AuthMethod
+}{
+	{"Token", subsonic.TokenAuth},
+	{"Password (LDAP)", subsonic.PasswordAuth},
+	{"API key", subsonic.APIKeyAuth},
+}
+
+// sonicForm is the form for changing *sonic credentials. The inputs depend
+// on the authentication method, which can be detected from the server.
 func sonicForm(tui *TUI) *tview.Form {
-	host, username, password := strDefaultHostStr, strBlank, strBlank
+	host, username, method := strDefaultHostStr, strBlank, subsonic.TokenAuth
 	credBuf, err := tui.db.GetCredentials(subsonic.CredentialKey)
 	if err == nil {
 		var creds subsonic.Credentials
 		err := json.Unmarshal(credBuf, &creds)
 		if err == nil {
 			host, username = creds.Host, creds.Username
+			if creds.AuthMethod != "" {
+				method = creds.AuthMethod
+			}
 		}
 	}
 	form := newSettingsForm()
-	form.AddInputField(strHost, host, fieldWidth, nil, nil).
-		AddInputField(strUsername, username, fieldWidth, nil, nil).
-		AddPasswordField(strPassword, password, fieldWidth, passwordMask, nil).
+	hostField := tview.NewInputField().SetLabel(strHost).SetText(host)
+	methodField := tview.NewDropDown().SetLabel(strAuthMethod)
+	usernameField := tview.NewInputField().SetLabel(strUsername).SetText(username)
+	passwordField := tview.NewInputField().SetLabel(strPassword).SetMaskCharacter(passwordMask)
+	apiKeyField := tview.NewInputField().SetLabel(strAPIKey).SetMaskCharacter(passwordMask)
+	// layout shows the inputs used by the method.
+	layout := func(index int) {
+		method = authMethods[index].method
+		methodField.SetCurrentOption(index)
+		form.Clear(false).
+			AddFormItem(hostField).
+			AddFormItem(methodField)
+		if method == subsonic.APIKeyAuth {
+			form.AddFormItem(apiKeyField)
+		} else {
+			form.AddFormItem(usernameField).
+				AddFormItem(passwordField)
+		}
+	}
+	names := make([]string, len(authMethods))
+	current := 0
+	for i, m := range authMethods {
+		names[i] = m.name
+		if m.method == method {
+			current = i
+		}
+	}
+	methodField.SetOptions(names, func(_ string, index int) {
+		layout(index)
+	})
+	layout(current)
+
+	form.AddButton(strDetect, func() {
+		h := hostField.GetText()
+		nonUIBlockingCall(func() {
+			detected, err := subsonic.DetectAuthMethod(h)
+			if err != nil {
+				tui.logger.ErrorLog("Failed to detect the authentication method: " + err.Error())
+				return
+			}
+			for i, m := range authMethods {
+				if m.method == detected {
+					tui.queueUpdateDraw(func() { layout(i) })
+				}
+			}
+		})
+	}).
 		AddButton(strSave, func() {
-			h := form.GetFormItemByLabel(strHost).(*tview.InputField).GetText()
-			u := form.GetFormItemByLabel(strUsername).(*tview.InputField).GetText()
-			p := form.GetFormItemByLabel(strPassword).(*tview.InputField).GetText()
-			c, err := subsonic.Login(u, p, h)
+			h := hostField.GetText()
+			var c *subsonic.Client
+			var err error
+			switch method {
+			case subsonic.APIKeyAuth:
+				c, err = subsonic.LoginWithAPIKey(apiKeyField.GetText(), h)
+			case subsonic.PasswordAuth:
+				c, err = subsonic.LoginWithPassword(usernameField.GetText(), passwordField.GetText(), h)
+			default:
+				c, err = subsonic.Login(usernameField.GetText(), passwordField.GetText(), h)
+			}
 			if err != nil {
 				tui.player.Error <- err
 				return
 			}
+			if _, profile, err := jamsonic.GetStreamProfiles(tui.db); err == nil {
+				c.SetStreamSettings(profile.StreamSettings)
+			}
 			buf, err := json.Marshal(&c.Credentials)
 			if err != nil {
 				tui.player.Error <- err
@@ -139,3 +232,210 @@ func sonicForm(tui *TUI) *tview.Form {
 		})
 	return form
 }
+
+// streamForm is the form for choosing and editing the stream profiles.
+// Saving a profile makes it the active profile.
+func streamForm(tui *TUI) *tview.Form {
+	form := newSettingsForm()
+	profiles, active, err := jamsonic.GetStreamProfiles(tui.db)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the stream profiles: " + err.Error())
+		profiles, active = jamsonic.DefaultStreamProfiles, jamsonic.DefaultStreamProfiles[0]
+	}
+	dropDown := tview.NewDropDown().SetLabel(strProfile)
+	form.AddFormItem(dropDown).
+		AddInputField(strName, "", fieldWidth, nil, nil).
+		AddInputField(strFormat, "", fieldWidth, nil, nil).
+		AddInputField(strMaxBitRate, "", fieldWidth, tview.InputFieldInteger, nil)
+	name := form.GetFormItemByLabel(strName).(*tview.InputField)
+	format := form.GetFormItemByLabel(strFormat).(*tview.InputField)
+	bitRate := form.GetFormItemByLabel(strMaxBitRate).(*tview.InputField)
+	show := func(p *jamsonic.StreamProfile) {
+		name.SetText(p.Name)
+		format.SetText(p.Format)
+		bitRate.SetText("")
+		if p.MaxBitRate > 0 {
+			bitRate.SetText(strconv.Itoa(p.MaxBitRate))
+		}
+	}
+	profileNames := func() []string {
+		names := make([]string, len(profiles))
+		for i, p := range profiles {
+			names[i] = p.Name
+		}
+		return names
+	}
+	selected := func(_ string, index int) {
+		show(profiles[index])
+	}
+	setOptions := func(current *jamsonic.StreamProfile) {
+		dropDown.SetOptions(profileNames(), selected)
+		for i, p := range profiles {
+			if p == current {
+				dropDown.SetCurrentOption(i)
+			}
+		}
+		show(current)
+	}
+	setOptions(active)
+
+	save := func(updated []*jamsonic.StreamProfile, current *jamsonic.StreamProfile) {
+		configurer, ok := tui.provider.(jamsonic.StreamConfigurer)
+		if !ok {
+			tui.logger.ErrorLog("The provider doesn't support stream settings.")
+			return
+		}
+		if err := tui.db.SaveStreamProfiles(updated); err != nil {
+			tui.logger.ErrorLog("Failed to save the stream profiles: " + err.Error())
+			return
+		}
+		profiles = updated
+		if err := jamsonic.ActivateStreamProfile(tui.db, configurer, current.Name); err != nil {
+			tui.logger.ErrorLog("Failed to change the stream profile: " + err.Error())
+			return
+		}
+		tui.logger.InfoLog("Streaming with the profile " + current.Name + ".")
+		setOptions(current)
+	}
+	form.AddButton(strSave, func() {
+		if name.GetText() == "" {
+			return
+		}
+		rate, _ := strconv.Atoi(bitRate.GetText())
+		p := &jamsonic.StreamProfile{
+			Name:           name.GetText(),
+			StreamSettings: jamsonic.StreamSettings{Format: format.GetText(), MaxBitRate: rate},
+		}
+		updated := make([]*jamsonic.StreamProfile, 0, len(profiles)+1)
+		for _, old := range profiles {
+			if old.Name != p.Name {
+				updated = append(updated, old)
+			}
+		}
+		save(append(updated, p), p)
+	}).
+		AddButton(strDelete, func() {
+			index, _ := dropDown.GetCurrentOption()
+			if len(profiles) < 2 || index < 0 {
+				return
+			}
+			updated := append(append([]*jamsonic.StreamProfile{}, profiles[:index]...), profiles[index+1:]...)
+			save(updated, updated[0])
+		}).
+		AddButton(strCancel, func() {
+			tui.app.SetFocus(tui.settingsList)
+		})
+	return form
+}
+
+// musicFolderForm is the form for choosing the music folder. The library is
+// synced again after a new folder is saved. The folders are added by
+// populateMusicFolders.
+func musicFolderForm(tui *TUI) *tview.Form {
+	form := newSettingsForm()
+	tui.musicFolderDropDown = tview.NewDropDown().SetLabel(strMusicFolder).
+		SetOptions([]string{strAllFolders}, nil).SetCurrentOption(0)
+	form.AddFormItem(tui.musicFolderDropDown).
+		AddButton(strSave, func() {
+			provider, ok := tui.provider.(jamsonic.MusicFolderProvider)
+			if !ok {
+				tui.logger.ErrorLog("The provider doesn't have music folders.")
+				return
+			}
+			index, name := tui.musicFolderDropDown.GetCurrentOption()
+			var id string
+			if index > 0 {
+				id = tui.musicFolders[index-1].ID
+			}
+			if err := jamsonic.SelectMusicFolder(tui.db, provider, id); err != nil {
+				tui.logger.ErrorLog("Failed to save the music folder: " + err.Error())
+				return
+			}
+			tui.logger.InfoLog("Using the music folder " + name + ".")
+			nonUIBlockingCall(func() {
+				updateLibrary(tui)
+			})
+		}).
+		AddButton(strCancel, func() {
+			tui.app.SetFocus(tui.settingsList)
+		})
+	return form
+}
+
+// populateMusicFolders gets the server's music folders and selects the saved
+// folder.
+func (tui *TUI) populateMusicFolders() {
+	provider, ok := tui.provider.(jamsonic.MusicFolderProvider)
+	if !ok {
+		return
+	}
+	folders, err := provider.MusicFolders(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the music folders: " + err.Error())
+		return
+	}
+	selected, err := tui.db.MusicFolder()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the music folder: " + err.Error())
+	}
+	options := []string{strAllFolders}
+	current := 0
+	for i, f := range folders {
+		options = append(options, f.Name)
+		if f.ID == selected {
+			current = i + 1
+		}
+	}
+	tui.musicFolders = folders
+	tui.musicFolderDropDown.SetOptions(options, nil).SetCurrentOption(current)
+	tui.app.Draw()
+}
+
+// outputForm is the form for choosing where the tracks are played. The
+// player switches to the output when it's saved.
+func outputForm(tui *TUI) *tview.Form {
+	form := newSettingsForm()
+	names := make([]string, len(outputs))
+	current := 0
+	saved, err := tui.db.Output()
+	if err != nil {
+		tui.logger.ErrorLog("Failed to read the output: " + err.Error())
+	}
+	for i, o := range outputs {
+		names[i] = o.name
+		if o.output == saved {
+			current = i
+		}
+	}
+	dropDown := tview.NewDropDown().SetLabel(strOutput).SetOptions(names, nil).SetCurrentOption(current)
+	form.AddFormItem(dropDown).
+		AddButton(strSave, func() {
+			index, _ := dropDown.GetCurrentOption()
+			tui.setOutput(outputs[index].output)
+		}).
+		AddButton(strCancel, func() {
+			tui.app.SetFocus(tui.settingsList)
+		})
+	return form
+}
+
+// setOutput saves the output and lets the player continue on it.
+func (tui *TUI) setOutput(output string) {
+	if _, ok := tui.provider.(jamsonic.JukeboxProvider); !ok && output == jamsonic.JukeboxOutput {
+		tui.logger.ErrorLog("The provider doesn't have a jukebox.")
+		return
+	}
+	if err := tui.db.SaveOutput(output); err != nil {
+		tui.logger.ErrorLog("Failed to save the output: " + err.Error())
+		return
+	}
+	handler := tui.outputHandler(tui.provider, output)
+	nonUIBlockingCall(func() {
+		tui.player.UpdateHandler(handler)
+	})
+	if output == jamsonic.JukeboxOutput {
+		tui.logger.InfoLog("Playing on the server's jukebox.")
+	} else {
+		tui.logger.InfoLog("Playing on this computer.")
+	}
+}
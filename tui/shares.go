@@ -0,0 +1,253 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const (
+	// shareDescriptionLabel and shareExpiryLabel are the fields of the
+	// share form.
+	shareDescriptionLabel = "Description"
+	shareExpiryLabel      = "Expires in days"
+)
+
+func (tui *TUI) createSharesPage() *tview.List {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Shares")
+	tui.sharesView = list
+
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.shares) {
+			tui.showShareLink(tui.shares[index])
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := list.GetCurrentItem()
+		if index >= len(tui.shares) {
+			return tui.vimBindings(tui.musicControl(event))
+		}
+		s := tui.shares[index]
+		switch event.Rune() {
+		case 'a':
+			tui.player.Enqueue(s.Tracks...)
+			return nil
+		case 'e':
+			tui.showShareForm("Edit share", s.Description, s.Expires, func(description string, expires time.Time) {
+				tui.editShares(func(p jamsonic.ShareProvider) error {
+					return p.UpdateShare(tui.ctx, s.ID, description, expires)
+				})
+			})
+			return nil
+		case 'D':
+			tui.showConfirm("Revoke the share "+shareName(s)+"?", func() {
+				tui.editShares(func(p jamsonic.ShareProvider) error {
+					return p.DeleteShare(tui.ctx, s.ID)
+				})
+			})
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	return list
+}
+
+// populateShares gets the shares from the provider and lists them.
+func (tui *TUI) populateShares() {
+	provider, ok := tui.provider.(jamsonic.ShareProvider)
+	if !ok {
+		return
+	}
+	shares, err := provider.Shares(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the shares: " + err.Error())
+		return
+	}
+	tui.shares = shares
+	current := tui.sharesView.GetCurrentItem()
+	tui.sharesView.Clear()
+	for _, s := range shares {
+		line := fmt.Sprintf("%s (%d tracks)", shareName(s), len(s.Tracks))
+		details := s.URL + ", visited " + strconv.Itoa(s.VisitCount) + " times"
+		if !s.Expires.IsZero() {
+			details += ", expires " + s.Expires.Local().Format("2006-01-02")
+		}
+		tui.sharesView.AddItem(tview.Escape(line), tview.Escape(details), 0, nil)
+	}
+	if current < len(shares) {
+		tui.sharesView.SetCurrentItem(current)
+	}
+	tui.app.Draw()
+}
+
+// shareControl handles the key to share the item. 'S' asks for a
+// description and an expiry and creates a link to the item's tracks.
+func (tui *TUI) shareControl(event *tcell.EventKey, item *searchItem) *tcell.EventKey {
+	if event == nil {
+		return nil
+	}
+	if item == nil || event.Rune() != 'S' {
+		return event
+	}
+	tui.shareTracks(itemName(item), tui.searchResultTracks(item))
+	return nil
+}
+
+// shareTracks asks for a description and an expiry and creates a link to
+// the tracks. The link is shown and copied to the clipboard.
+func (tui *TUI) shareTracks(name string, tracks []*jamsonic.Track) {
+	provider, ok := tui.provider.(jamsonic.ShareProvider)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support sharing.")
+		return
+	}
+	if len(tracks) == 0 {
+		return
+	}
+	ids := make([]string, len(tracks))
+	for i, t := range tracks {
+		ids[i] = t.ID
+	}
+	tui.showShareForm("Share "+name, name, time.Time{}, func(description string, expires time.Time) {
+		nonUIBlockingCall(func() {
+			s, err := provider.CreateShare(tui.ctx, ids, description, expires)
+			if err != nil {
+				tui.logger.ErrorLog("Failed to share " + name + ": " + err.Error())
+				return
+			}
+			tui.logger.InfoLog("Shared " + name + ": " + s.URL)
+			tui.showShareLink(s)
+			tui.populateShares()
+		})
+	})
+}
+
+// showShareForm asks for the description and the expiry in days. An empty
+// expiry means the link never expires.
+func (tui *TUI) showShareForm(title, description string, expires time.Time, done func(description string, expires time.Time)) {
+	var days string
+	if !expires.IsZero() {
+		if d := int(time.Until(expires).Hours()+23) / 24; d > 0 {
+			days = strconv.Itoa(d)
+		}
+	}
+	form := tview.NewForm().
+		AddInputField(shareDescriptionLabel, description, 30, nil, nil).
+		AddInputField(shareExpiryLabel, days, 5, tview.InputFieldInteger, nil)
+	form.AddButton("Save", func() {
+		tui.closeDialog()
+		text := form.GetFormItemByLabel(shareDescriptionLabel).(*tview.InputField).GetText()
+		var expires time.Time
+		days, _ := strconv.Atoi(form.GetFormItemByLabel(shareExpiryLabel).(*tview.InputField).GetText())
+		if days > 0 {
+			expires = time.Now().AddDate(0, 0, days)
+		}
+		done(text, expires)
+	}).AddButton("Cancel", func() {
+		tui.closeDialog()
+	})
+	form.SetCancelFunc(func() {
+		tui.closeDialog()
+	})
+	form.SetBorder(true).SetTitle(title)
+	tui.showDialog(form, 50, 9)
+}
+
+// showShareLink shows the share's link and copies it to the clipboard.
+func (tui *TUI) showShareLink(s *jamsonic.Share) {
+	tui.copyToClipboard(s.URL)
+	modal := tview.NewModal().
+		SetText(s.URL + "\n\nThe link is copied to the clipboard.").
+		AddButtons([]string{"OK"})
+	modal.SetDoneFunc(func(int, string) {
+		tui.closeDialog()
+	})
+	tui.openDialog(modal, modal)
+}
+
+// editShares runs the change in the background and fetches the shares
+// again.
+func (tui *TUI) editShares(edit func(p jamsonic.ShareProvider) error) {
+	provider, ok := tui.provider.(jamsonic.ShareProvider)
+	if !ok {
+		tui.logger.ErrorLog("The provider doesn't support sharing.")
+		return
+	}
+	nonUIBlockingCall(func() {
+		if err := edit(provider); err != nil {
+			tui.logger.ErrorLog("Failed to update the shares: " + err.Error())
+			return
+		}
+		tui.populateShares()
+	})
+}
+
+// copyToClipboard queues the text for the terminal's clipboard. It's
+// written by writeClipboard after the next draw so the escape sequence
+// doesn't end up in the middle of a screen update.
+func (tui *TUI) copyToClipboard(text string) {
+	tui.clipboardMu.Lock()
+	tui.clipboard = text
+	tui.clipboardMu.Unlock()
+}
+
+// writeClipboard sets the terminal's clipboard to the queued text with the
+// OSC 52 escape sequence. Terminals that don't support it ignore the
+// sequence.
+func (tui *TUI) writeClipboard(screen tcell.Screen, tty io.Writer) {
+	tui.clipboardMu.Lock()
+	text := tui.clipboard
+	tui.clipboard = ""
+	tui.clipboardMu.Unlock()
+	if text == "" {
+		return
+	}
+	// The sequence has to be written after the screen update.
+	screen.Show()
+	fmt.Fprintf(tty, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+}
+
+// shareName returns the description of the share, or the title of its
+// first track if it has no description.
+func shareName(s *jamsonic.Share) string {
+	if s.Description != "" || len(s.Tracks) == 0 {
+		return s.Description
+	}
+	return s.Tracks[0].Title
+}
+
+// itemName returns the name of the artist, album or track.
+func itemName(item *searchItem) string {
+	switch {
+	case item.artist != nil:
+		return item.artist.Name
+	case item.album != nil:
+		return item.album.Name + " - " + item.album.Artist
+	default:
+		return item.track.Title + " - " + item.track.Artist
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"github.com/TcM1911/jamsonic"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+func (tui *TUI) createStationsPage() *tview.List {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Internet radio")
+	tui.stationsView = list
+
+	list.SetSelectedFunc(func(index int, _ string, _ string, _ rune) {
+		if index < len(tui.stations) {
+			tui.playTracksNow([]*jamsonic.Track{tui.stations[index].Track()})
+		}
+	})
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		index := list.GetCurrentItem()
+		if index < len(tui.stations) && event.Rune() == 'a' {
+			tui.player.Enqueue(tui.stations[index].Track())
+			return nil
+		}
+		return tui.vimBindings(tui.musicControl(event))
+	})
+
+	return list
+}
+
+// populateStations gets the stations from the provider and lists them.
+func (tui *TUI) populateStations() {
+	provider, ok := tui.provider.(jamsonic.InternetRadioProvider)
+	if !ok {
+		return
+	}
+	stations, err := provider.InternetRadioStations(tui.ctx)
+	if err != nil {
+		tui.logger.ErrorLog("Failed to get the internet radio stations: " + err.Error())
+		return
+	}
+	tui.stations = stations
+	current := tui.stationsView.GetCurrentItem()
+	tui.stationsView.Clear()
+	for _, s := range stations {
+		tui.stationsView.AddItem(s.Name, s.HomePageURL, 0, nil)
+	}
+	if current < len(stations) {
+		tui.stationsView.SetCurrentItem(current)
+	}
+	tui.app.Draw()
+}
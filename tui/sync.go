@@ -0,0 +1,142 @@
+// Copyright (c) 2018 Joakim Kennedy
+//
+// This file is part of Jamsonic.
+//
+// Jamsonic is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// Jamsonic is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with Jamsonic.  If not, see <http://www.gnu.org/licenses/>.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TcM1911/jamsonic"
+)
+
+const (
+	// syncBarWidth is the width of the sync progress bar.
+	syncBarWidth = 20
+	// syncSummaryTime is how long the summary is shown after a sync.
+	syncSummaryTime = 10 * time.Second
+)
+
+// Updates the library and refreshes the UI. The progress is shown in the
+// footer's title and the sync can be cancelled with cancelSync.
+func updateLibrary(tui *TUI) {
+	tui.syncMu.Lock()
+	if tui.syncCancel != nil {
+		tui.syncMu.Unlock()
+		tui.logger.InfoLog("The library is already being synced.")
+		return
+	}
+	ctx, cancel := context.WithCancel(tui.ctx)
+	tui.syncCancel = cancel
+	tui.syncMu.Unlock()
+	defer func() {
+		tui.syncMu.Lock()
+		tui.syncCancel = nil
+		tui.syncMu.Unlock()
+		cancel()
+	}()
+
+	var last jamsonic.SyncProgress
+	if r, ok := tui.provider.(jamsonic.SyncProgressReporter); ok {
+		r.SetSyncProgress(func(p jamsonic.SyncProgress) {
+			last = p
+			tui.setSyncTitle(syncProgressLine(&p))
+		})
+		defer r.SetSyncProgress(nil)
+	}
+	tui.setSyncTitle("Syncing the library (Ctrl+X cancels)")
+	changes, err := jamsonic.SyncLibrary(ctx, tui.db, tui.provider)
+	if ctx.Err() != nil {
+		tui.logger.InfoLog("The library sync was cancelled.")
+		tui.showSyncSummary("Sync cancelled")
+		return
+	}
+	if err != nil {
+		tui.showSyncSummary("Sync failed")
+		tui.player.Error <- err
+		return
+	}
+	summary := "Library synced: " + changes.String()
+	tui.logger.InfoLog(summary)
+	if n := len(last.Failures); n > 0 {
+		failed := make([]string, n)
+		for i, f := range last.Failures {
+			failed[i] = f.String()
+		}
+		tui.logger.ErrorLog(fmt.Sprintf("%d artists or albums failed to download:\n%s", n, strings.Join(failed, "\n")))
+		summary += fmt.Sprintf(", %d failed (see the log)", n)
+	}
+	tui.showSyncSummary(summary)
+	tui.populateArtists()
+	tui.populatePlaylists()
+	tui.populateStations()
+	tui.app.Draw()
+}
+
+// cancelSync cancels the running library sync, if any.
+func (tui *TUI) cancelSync() {
+	tui.syncMu.Lock()
+	defer tui.syncMu.Unlock()
+	if tui.syncCancel != nil {
+		tui.syncCancel()
+	}
+}
+
+// setSyncTitle shows the sync status in the footer's title.
+func (tui *TUI) setSyncTitle(title string) {
+	tui.footer.SetTitle(title)
+	tui.app.Draw()
+}
+
+// showSyncSummary shows the result of the sync for a while.
+func (tui *TUI) showSyncSummary(summary string) {
+	tui.setSyncTitle(summary)
+	time.AfterFunc(syncSummaryTime, func() {
+		tui.syncMu.Lock()
+		defer tui.syncMu.Unlock()
+		// Don't hide the progress of a new sync.
+		if tui.syncCancel == nil {
+			tui.setSyncTitle("")
+		}
+	})
+}
+
+// syncProgressLine returns a progress bar with the albums, or the artists if
+// the albums are unknown, done out of the total.
+func syncProgressLine(p *jamsonic.SyncProgress) string {
+	done, total, unit := p.AlbumsDone, p.AlbumsTotal, "albums"
+	if total == 0 {
+		done, total, unit = p.ArtistsDone, p.ArtistsTotal, "artists"
+	}
+	filled := 0
+	if total > 0 {
+		filled = syncBarWidth * done / total
+		if filled > syncBarWidth {
+			filled = syncBarWidth
+		}
+	}
+	line := fmt.Sprintf("Syncing %s%s %d/%d %s", strings.Repeat("█", filled),
+		strings.Repeat("░", syncBarWidth-filled), done, total, unit)
+	if len(p.Failures) > 0 {
+		line += fmt.Sprintf(", %d failed", len(p.Failures))
+	}
+	if p.Current != "" {
+		line += ": " + p.Current
+	}
+	return line + " (Ctrl+X cancels)"
+}